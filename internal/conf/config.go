@@ -26,10 +26,11 @@ type Server struct {
 }
 
 type ServerHTTP struct {
-	Port      int         `comment:"http 端口"`                // 服务器端口号
-	Timeout   Duration    `comment:"请求超时时间"`                 // 请求超时时间
-	JwtSecret string      `comment:"jwt 秘钥，空串时，每次启动程序将随机赋值"` // JWT密钥
-	PProf     ServerPPROF // Pprof配置
+	Port        int         `comment:"http 端口"`                                                                            // 服务器端口号
+	Timeout     Duration    `comment:"请求超时时间"`                                                                             // 请求超时时间
+	JwtSecret   string      `comment:"jwt 秘钥，空串时，每次启动程序将随机赋值"`                                                             // JWT密钥
+	ExternalURL string      `comment:"设备可访问的本服务地址，用于设备上传抓拍图像，如 http://192.168.1.10:15123，为空时使用 media.webhookip 与 http 端口"` // 对外访问地址
+	PProf       ServerPPROF // Pprof配置
 }
 
 // ServerPPROF 结构体，包含 Enabled 和 AccessIps 两个字段
@@ -69,6 +70,11 @@ type SIP struct {
 	ID       string `comment:"gb/t28181 20 位国标 ID" json:"id"`
 	Domain   string `comment:"域" json:"domain"`
 	Password string `comment:"注册密码" json:"password"`
+
+	CatalogSubscribeExpires        int `comment:"目录订阅有效期(秒)，设备注册后自动订阅，0 表示不订阅" json:"catalog_subscribe_expires"`
+	MobilePositionSubscribeExpires int `comment:"移动位置订阅有效期(秒)，设备注册后自动订阅，0 表示不订阅" json:"mobile_position_subscribe_expires"`
+	MobilePositionInterval         int `comment:"移动位置上报间隔(秒)" json:"mobile_position_interval"`
+	DeviceStatusInterval           int `comment:"设备状态查询间隔(秒)，0 表示不查询" json:"device_status_interval"`
 }
 
 type Media struct {
@@ -36,6 +36,10 @@ func DefaultConfig() Bootstrap {
 			ID:       "34010000002000000001",
 			Domain:   "3401000000",
 			Password: "",
+
+			CatalogSubscribeExpires: 3600,
+			MobilePositionInterval:  5,
+			DeviceStatusInterval:    300,
 		},
 		Media: Media{
 			IP:           "127.0.0.1",
@@ -5,4 +5,10 @@ const (
 	IDPrefixGBChannel = "ch" // 国标通道 id 前缀
 	IDPrefixRTMP      = "mp" // rtmp ID 前缀，取 rtmp 后缀的 mp，不好记但是清晰
 	IDPrefixRTSP      = "sp" // rtsp ID 前缀，取 rtsp 后缀的 sp，不好记但是清晰
+	IDPrefixDownload  = "dl" // 国标录像下载任务 id 前缀，同时作为下载流 ID
+	IDPrefixFirmware  = "fw" // 设备固件 id 前缀
+	IDPrefixUpgrade   = "up" // 设备升级任务 id 前缀，同时作为升级会话 ID
+	IDPrefixCatalog   = "cg" // 国标目录分组 id 前缀
+	IDPrefixPlatform  = "pf" // 上级平台 id 前缀
+	IDPrefixShare     = "ps" // 级联共享通道 id 前缀
 )
@@ -0,0 +1,44 @@
+package gb28181
+
+import (
+	"context"
+	"time"
+
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// AlarmStorer Instantiation interface
+type AlarmStorer interface {
+	Find(context.Context, *[]*Alarm, orm.Pager, ...orm.QueryOption) (int64, error)
+	Add(context.Context, *Alarm) error
+}
+
+// FindAlarm Paginated search
+func (c Core) FindAlarm(ctx context.Context, in *FindAlarmInput) ([]*Alarm, int64, error) {
+	items := make([]*Alarm, 0)
+	query := orm.NewQuery(6).OrderBy("alarm_at DESC")
+	if in.DeviceID != "" {
+		query.Where("device_id=?", in.DeviceID)
+	}
+	if in.ChannelID != "" {
+		query.Where("channel_id=?", in.ChannelID)
+	}
+	if in.Method > 0 {
+		query.Where("method=?", in.Method)
+	}
+	if in.Type > 0 {
+		query.Where("type=?", in.Type)
+	}
+	if in.StartAt > 0 {
+		query.Where("alarm_at>=?", time.Unix(in.StartAt, 0))
+	}
+	if in.EndAt > 0 {
+		query.Where("alarm_at<=?", time.Unix(in.EndAt, 0))
+	}
+	total, err := c.store.Alarm().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, total, nil
+}
@@ -0,0 +1,24 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/orm"
+
+// Alarm 设备报警记录
+type Alarm struct {
+	ID          int      `gorm:"primaryKey" json:"id"`
+	DeviceID    string   `gorm:"column:device_id;index;notNull;default:'';comment:设备国标编码" json:"device_id"`            // 上报设备国标编码
+	ChannelID   string   `gorm:"column:channel_id;index;notNull;default:'';comment:报警源国标编码" json:"channel_id"`         // 报警源国标编码，设备或通道
+	Priority    int      `gorm:"column:priority;notNull;default:0;comment:报警级别" json:"priority"`                       // 报警级别 1:一级警情 2:二级警情 3:三级警情 4:四级警情
+	Method      int      `gorm:"column:method;notNull;default:0;comment:报警方式" json:"method"`                           // 报警方式 1:电话 2:设备 3:短信 4:GPS 5:视频 6:设备故障 7:其他
+	Type        int      `gorm:"column:type;index;notNull;default:0;comment:报警类型" json:"type"`                         // 报警类型，取值与报警方式相关
+	EventType   int      `gorm:"column:event_type;notNull;default:0;comment:报警类型扩展参数" json:"event_type"`               // 入侵检测报警的事件类型 1:进入区域 2:离开区域
+	Description string   `gorm:"column:description;notNull;default:'';comment:报警描述" json:"description"`                // 报警描述
+	Longitude   float64  `gorm:"column:longitude;notNull;default:0;comment:经度" json:"longitude"`                       // 经度
+	Latitude    float64  `gorm:"column:latitude;notNull;default:0;comment:纬度" json:"latitude"`                         // 纬度
+	AlarmAt     orm.Time `gorm:"column:alarm_at;index;notNull;default:CURRENT_TIMESTAMP;comment:报警时间" json:"alarm_at"` // 报警时间
+	CreatedAt   orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`   // 创建时间
+}
+
+// TableName database table name
+func (*Alarm) TableName() string {
+	return "alarms"
+}
@@ -0,0 +1,13 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/web"
+
+type FindAlarmInput struct {
+	web.PagerFilter
+	DeviceID  string `form:"device_id"`  // 设备国标编码
+	ChannelID string `form:"channel_id"` // 报警源国标编码
+	Method    int    `form:"method"`     // 报警方式
+	Type      int    `form:"type"`       // 报警类型
+	StartAt   int64  `form:"start_at"`   // 报警时间起，unix 秒
+	EndAt     int64  `form:"end_at"`     // 报警时间止，unix 秒
+}
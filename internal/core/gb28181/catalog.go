@@ -0,0 +1,126 @@
+package gb28181
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// CatalogGroupStorer Instantiation interface
+type CatalogGroupStorer interface {
+	Find(context.Context, *[]*CatalogGroup, orm.Pager, ...orm.QueryOption) (int64, error)
+	Add(context.Context, *CatalogGroup) error
+	Edit(context.Context, *CatalogGroup, func(*CatalogGroup), ...orm.QueryOption) error
+	Del(context.Context, *CatalogGroup, ...orm.QueryOption) error
+}
+
+// 分组节点的排序，行政区划在前，通道最后
+var catalogTypeOrder = map[string]int{
+	CatalogTypeCivilCode:     1,
+	CatalogTypePlatform:      2,
+	CatalogTypeBusinessGroup: 3,
+	CatalogTypeVirtualOrg:    4,
+	CatalogTypeRecorder:      5,
+	CatalogTypeChannel:       6,
+}
+
+// FindTree 全局目录树，未指定设备时以设备作为根节点
+func (c Core) FindTree(ctx context.Context, in *FindTreeInput) ([]*TreeNode, error) {
+	if in.DID != "" {
+		return c.FindDeviceTree(ctx, in.DID, in.ParentID)
+	}
+	devices := make([]*Device, 0)
+	if _, err := c.store.Device().Find(ctx, &devices, web.NewPagerFilterMaxSize(), orm.OrderBy("device_id ASC")); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	out := make([]*TreeNode, 0, len(devices))
+	for _, d := range devices {
+		name := d.Name
+		if name == "" {
+			name = d.Ext.Name
+		}
+		out = append(out, &TreeNode{
+			ID:       d.ID,
+			GBID:     d.DeviceID,
+			DID:      d.ID,
+			Name:     name,
+			Type:     CatalogTypeDevice,
+			IsOnline: d.IsOnline,
+			Children: d.Channels,
+		})
+	}
+	return out, nil
+}
+
+// FindDeviceTree 设备目录树的一层，parentID 为空时返回根节点
+// 父节点不在该设备目录中的条目视为根节点，兼容只上报通道的设备
+func (c Core) FindDeviceTree(ctx context.Context, did, parentID string) ([]*TreeNode, error) {
+	groups := make([]*CatalogGroup, 0)
+	if _, err := c.store.CatalogGroup().Find(ctx, &groups, web.NewPagerFilterMaxSize(), orm.Where("did=?", did)); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	channels := make([]*Channel, 0)
+	if _, err := c.store.Channel().Find(ctx, &channels, web.NewPagerFilterMaxSize(), orm.Where("did=?", did)); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+
+	known := make(map[string]struct{}, len(groups))
+	for _, g := range groups {
+		known[g.GroupID] = struct{}{}
+	}
+
+	nodes := make([]*TreeNode, 0, len(groups)+len(channels))
+	for _, g := range groups {
+		nodes = append(nodes, &TreeNode{
+			ID:       g.ID,
+			GBID:     g.GroupID,
+			DID:      g.DID,
+			Name:     g.Name,
+			Type:     g.Type,
+			ParentID: resolveParent(g.GroupID, known, g.parentCandidates()),
+			IsOnline: true,
+		})
+	}
+	for _, ch := range channels {
+		nodes = append(nodes, &TreeNode{
+			ID:       ch.ID,
+			GBID:     ch.ChannelID,
+			DID:      ch.DID,
+			Name:     ch.Name,
+			Type:     CatalogTypeChannel,
+			ParentID: resolveParent(ch.ChannelID, known, ch.parentCandidates()),
+			IsOnline: ch.IsOnline,
+		})
+	}
+
+	children := make(map[string]int, len(groups))
+	for _, n := range nodes {
+		if n.ParentID != "" && n.ParentID != n.GBID {
+			children[n.ParentID]++
+		}
+	}
+	out := make([]*TreeNode, 0, 8)
+	for _, n := range nodes {
+		// 父节点为自身的异常数据作为根节点
+		if n.ParentID == n.GBID {
+			n.ParentID = ""
+		}
+		if n.ParentID != parentID {
+			continue
+		}
+		if n.Type != CatalogTypeChannel {
+			n.Children = children[n.GBID]
+		}
+		out = append(out, n)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if a, b := catalogTypeOrder[out[i].Type], catalogTypeOrder[out[j].Type]; a != b {
+			return a < b
+		}
+		return out[i].GBID < out[j].GBID
+	})
+	return out, nil
+}
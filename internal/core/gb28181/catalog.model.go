@@ -0,0 +1,106 @@
+package gb28181
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// 目录节点类型，按国标编码的类型码划分 GB/T28181 附录 D
+const (
+	CatalogTypeDevice        = "device"         // 设备，仅全局目录树使用
+	CatalogTypeCivilCode     = "civil_code"     // 行政区划，2/4/6/8 位编码
+	CatalogTypePlatform      = "platform"       // 中心信令控制服务器 200
+	CatalogTypeRecorder      = "recorder"       // 下级录像机 DVR 111、NVR 118，通道挂载其下
+	CatalogTypeBusinessGroup = "business_group" // 业务分组 215
+	CatalogTypeVirtualOrg    = "virtual_org"    // 虚拟组织 216
+	CatalogTypeChannel       = "channel"        // 视频通道
+)
+
+// CatalogType 按国标编码判断目录项类型，无法识别的编码视为通道
+func CatalogType(id string) string {
+	switch len(id) {
+	case 2, 4, 6, 8:
+		return CatalogTypeCivilCode
+	case 20:
+		switch id[10:13] {
+		case "200":
+			return CatalogTypePlatform
+		case "111", "118":
+			return CatalogTypeRecorder
+		case "215":
+			return CatalogTypeBusinessGroup
+		case "216":
+			return CatalogTypeVirtualOrg
+		}
+	}
+	return CatalogTypeChannel
+}
+
+// IsCatalogGroup 是否为分组类目录项，分组不可播放，不计入通道
+func IsCatalogGroup(id string) bool {
+	return CatalogType(id) != CatalogTypeChannel
+}
+
+// CatalogGroup 下级目录中的分组节点，包含行政区划、业务分组、虚拟组织等
+type CatalogGroup struct {
+	ID              string   `gorm:"primaryKey" json:"id"`
+	DID             string   `gorm:"column:did;index;notNull;default:'';comment:设备 ID" json:"did"`                        // 设备 ID
+	DeviceID        string   `gorm:"column:device_id;index;notNull;default:'';comment:国标编码" json:"device_id"`             // 设备国标编码
+	GroupID         string   `gorm:"column:group_id;notNull;default:'';comment:国标编码" json:"group_id"`                     // 分组国标编码
+	Name            string   `gorm:"column:name;notNull;default:'';comment:名称" json:"name"`                               // 名称
+	Type            string   `gorm:"column:type;notNull;default:'';comment:分组类型" json:"type"`                             // 分组类型
+	ParentID        string   `gorm:"column:parent_id;notNull;default:'';comment:父节点编码" json:"parent_id"`                  // 上报的父节点编码
+	CivilCode       string   `gorm:"column:civil_code;notNull;default:'';comment:行政区划" json:"civil_code"`                 // 行政区划
+	BusinessGroupID string   `gorm:"column:business_group_id;notNull;default:'';comment:业务分组编码" json:"business_group_id"` // 虚拟组织所属业务分组
+	CreatedAt       orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`  // 创建时间
+	UpdatedAt       orm.Time `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"`  // 更新时间
+}
+
+// TableName database table name
+func (*CatalogGroup) TableName() string {
+	return "catalog_groups"
+}
+
+// parentCandidates 目录树中可能的父节点编码，按优先级排列
+func (g *CatalogGroup) parentCandidates() []string {
+	if g.Type == CatalogTypeCivilCode {
+		// 行政区划逐级截断，如 340200 的上级为 3402，中间级缺失时继续向上查找
+		out := make([]string, 0, 3)
+		for i := len(g.GroupID) - 2; i >= 2; i -= 2 {
+			out = append(out, g.GroupID[:i])
+		}
+		return out
+	}
+	out := parentIDs(g.ParentID)
+	if g.Type == CatalogTypeVirtualOrg {
+		out = append(out, g.BusinessGroupID)
+	}
+	return append(out, g.CivilCode)
+}
+
+// parentCandidates 通道在目录树中可能的父节点编码，按优先级排列
+func (c *Channel) parentCandidates() []string {
+	return append(parentIDs(c.ParentID), c.BusinessGroupID, c.CivilCode)
+}
+
+// resolveParent 取第一个已知分组作为父节点，均不在目录中时作为根节点
+func resolveParent(self string, known map[string]struct{}, candidates []string) string {
+	for _, v := range candidates {
+		if v == "" || v == self {
+			continue
+		}
+		if _, ok := known[v]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// parentIDs 部分平台以 "/" 分隔多级父节点，由近及远返回
+func parentIDs(s string) []string {
+	parts := strings.Split(s, "/")
+	slices.Reverse(parts)
+	return parts
+}
@@ -0,0 +1,18 @@
+package gb28181
+
+type FindTreeInput struct {
+	DID      string `form:"did"`       // 设备 ID，全局目录树中展开设备时使用
+	ParentID string `form:"parent_id"` // 父节点国标编码，为空时返回根节点
+}
+
+// TreeNode 目录树节点
+type TreeNode struct {
+	ID       string `json:"id"`        // 设备/分组/通道 ID
+	GBID     string `json:"gb_id"`     // 国标编码
+	DID      string `json:"did"`       // 所属设备 ID
+	Name     string `json:"name"`      // 名称
+	Type     string `json:"type"`      // 节点类型
+	ParentID string `json:"parent_id"` // 父节点国标编码，根节点为空
+	IsOnline bool   `json:"is_online"` // 设备/通道是否在线，分组恒为 true
+	Children int    `json:"children"`  // 直接子节点数
+}
@@ -0,0 +1,112 @@
+package gb28181
+
+import "testing"
+
+func TestCatalogType(t *testing.T) {
+	cases := []struct {
+		id     string
+		expect string
+	}{
+		{"34", CatalogTypeCivilCode},
+		{"3402", CatalogTypeCivilCode},
+		{"340200", CatalogTypeCivilCode},
+		{"34020000", CatalogTypeCivilCode},
+		{"34020000002000000001", CatalogTypePlatform},
+		{"34020000001110000001", CatalogTypeRecorder},
+		{"34020000001180000001", CatalogTypeRecorder},
+		{"34020000002150000001", CatalogTypeBusinessGroup},
+		{"34020000002160000001", CatalogTypeVirtualOrg},
+		{"34020000001320000001", CatalogTypeChannel},
+		{"34020000001310000001", CatalogTypeChannel},
+		{"340200000013", CatalogTypeChannel},
+	}
+	for _, tc := range cases {
+		if got := CatalogType(tc.id); got != tc.expect {
+			t.Fatalf("CatalogType(%s) expect %s, got %s", tc.id, tc.expect, got)
+		}
+	}
+}
+
+func TestResolveParent(t *testing.T) {
+	known := map[string]struct{}{
+		"34":                   {},
+		"340200":               {},
+		"34020000001180000001": {},
+		"34020000002150000001": {},
+		"34020000002160000001": {},
+	}
+	cases := []struct {
+		name   string
+		self   string
+		cands  []string
+		expect string
+	}{
+		{
+			name:   "channel under nvr",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000001180000001", CivilCode: "340200"}).parentCandidates(),
+			expect: "34020000001180000001",
+		},
+		{
+			name:   "unknown parent falls back to business group",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000001320000009", BusinessGroupID: "34020000002150000001", CivilCode: "340200"}).parentCandidates(),
+			expect: "34020000002150000001",
+		},
+		{
+			name:   "unknown parent falls back to civil code",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000001320000009", CivilCode: "340200"}).parentCandidates(),
+			expect: "340200",
+		},
+		{
+			name:   "multi level parent takes nearest known",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000002150000001/34020000002160000001", CivilCode: "340200"}).parentCandidates(),
+			expect: "34020000002160000001",
+		},
+		{
+			name:   "multi level parent skips unknown",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000002150000001/34020000002160000009"}).parentCandidates(),
+			expect: "34020000002150000001",
+		},
+		{
+			name:   "nothing known is root",
+			self:   "34020000001320000001",
+			cands:  (&Channel{ParentID: "34020000001320000009", CivilCode: "110000"}).parentCandidates(),
+			expect: "",
+		},
+		{
+			name:   "civil code skips missing level",
+			self:   "34020001",
+			cands:  (&CatalogGroup{GroupID: "34020001", Type: CatalogTypeCivilCode}).parentCandidates(),
+			expect: "340200",
+		},
+		{
+			name:   "civil code walks up to province",
+			self:   "3401",
+			cands:  (&CatalogGroup{GroupID: "3401", Type: CatalogTypeCivilCode}).parentCandidates(),
+			expect: "34",
+		},
+		{
+			name:   "virtual org falls back to business group",
+			self:   "34020000002160000002",
+			cands:  (&CatalogGroup{GroupID: "34020000002160000002", Type: CatalogTypeVirtualOrg, ParentID: "34020000002000000001", BusinessGroupID: "34020000002150000001"}).parentCandidates(),
+			expect: "34020000002150000001",
+		},
+		{
+			name:   "self parent is root",
+			self:   "34020000002150000001",
+			cands:  (&CatalogGroup{GroupID: "34020000002150000001", Type: CatalogTypeBusinessGroup, ParentID: "34020000002150000001"}).parentCandidates(),
+			expect: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveParent(tc.self, known, tc.cands); got != tc.expect {
+				t.Fatalf("expect %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
@@ -48,6 +48,33 @@ func (c *Core) FindChannel(ctx context.Context, in *FindChannelInput) ([]*Channe
 		isOnline, _ := strconv.ParseBool(in.IsOnline)
 		query.Where("is_online = ?", isOnline)
 	}
+	if in.CivilCode != "" {
+		query.Where("civil_code LIKE ?", in.CivilCode+"%")
+	}
+	if in.ParentID != "" {
+		query.Where("parent_id = ?", in.ParentID)
+	}
+	if in.BusinessGroupID != "" {
+		query.Where("business_group_id = ?", in.BusinessGroupID)
+	}
+	if in.Owner != "" {
+		query.Where("owner = ?", in.Owner)
+	}
+	if in.Address != "" {
+		query.Where("address LIKE ?", "%"+in.Address+"%")
+	}
+	if in.PTZType > 0 {
+		query.Where("ptztype = ?", in.PTZType)
+	}
+	if in.PositionType > 0 {
+		query.Where("position_type = ?", in.PositionType)
+	}
+	switch in.HasPosition {
+	case "true":
+		query.Where("(longitude <> 0 OR latitude <> 0)")
+	case "false":
+		query.Where("longitude = 0 AND latitude = 0")
+	}
 
 	total, err := c.store.Channel().Find(ctx, &items, in, query.Encode()...)
 	if err != nil {
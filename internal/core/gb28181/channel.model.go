@@ -1,24 +1,86 @@
 // Code generated by godddx, DO AVOID EDIT.
 package gb28181
 
-import "github.com/ixugo/goddd/pkg/orm"
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/ixugo/goddd/pkg/orm"
+)
 
 // Channel domain model
 type Channel struct {
-	ID        string    `gorm:"primaryKey" json:"id"`
-	DID       string    `gorm:"column:did;index;notNull;default:'';comment:父级 ID" json:"did"`
-	DeviceID  string    `gorm:"column:device_id;index;notNull;default:'';comment:国标编码" json:"device_id"`   // 国标编码
-	ChannelID string    `gorm:"column:channel_id;index;notNull;default:'';comment:国标编码" json:"channel_id"` // 国标编码
-	Name      string    `gorm:"column:name;notNull;default:'';comment:通道名称" json:"name"`                   // 通道名称
-	PTZType   int       `gorm:"column:ptztype;notNull;default:0;comment:云台类型" json:"ptztype"`              // 云台类型
-	IsOnline  bool      `gorm:"column:is_online;notNull;default:FALSE;comment:是否在线" json:"is_online"`      // 是否在线
-	IsPlaying bool      `gorm:"column:is_playing;notNull;default:FALSE;comment:是否播放中" json:"is_playing"`   // 是否播放中
-	Ext       DeviceExt `gorm:"column:ext;notNull;default:'{}';type:jsonb" json:"ext"`
-	CreatedAt orm.Time  `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"` // 创建时间
-	UpdatedAt orm.Time  `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"` // 更新时间
+	ID              string       `gorm:"primaryKey" json:"id"`
+	DID             string       `gorm:"column:did;index;notNull;default:'';comment:父级 ID" json:"did"`
+	DeviceID        string       `gorm:"column:device_id;index;notNull;default:'';comment:国标编码" json:"device_id"`             // 国标编码
+	ChannelID       string       `gorm:"column:channel_id;index;notNull;default:'';comment:国标编码" json:"channel_id"`           // 国标编码
+	Name            string       `gorm:"column:name;notNull;default:'';comment:通道名称" json:"name"`                             // 通道名称
+	PTZType         int          `gorm:"column:ptztype;notNull;default:0;comment:云台类型" json:"ptztype"`                        // 云台类型
+	IsOnline        bool         `gorm:"column:is_online;notNull;default:FALSE;comment:是否在线" json:"is_online"`                // 是否在线
+	IsPlaying       bool         `gorm:"column:is_playing;notNull;default:FALSE;comment:是否播放中" json:"is_playing"`             // 是否播放中
+	ParentID        string       `gorm:"column:parent_id;notNull;default:'';comment:父节点编码" json:"parent_id"`                  // 上报的父节点编码
+	CivilCode       string       `gorm:"column:civil_code;notNull;default:'';comment:行政区划" json:"civil_code"`                 // 行政区划
+	BusinessGroupID string       `gorm:"column:business_group_id;notNull;default:'';comment:业务分组编码" json:"business_group_id"` // 所属业务分组
+	Owner           string       `gorm:"column:owner;notNull;default:'';comment:设备归属" json:"owner"`                           // 设备归属
+	Address         string       `gorm:"column:address;notNull;default:'';comment:安装地址" json:"address"`                       // 安装地址
+	Longitude       float64      `gorm:"column:longitude;notNull;default:0;comment:经度" json:"longitude"`                      // 经度
+	Latitude        float64      `gorm:"column:latitude;notNull;default:0;comment:纬度" json:"latitude"`                        // 纬度
+	PositionType    int          `gorm:"column:position_type;notNull;default:0;comment:位置类型" json:"position_type"`            // 摄像机位置类型 1 省际检查站 2 党政机关 3 车站码头 4 中心广场 5 体育场馆 6 商业中心 7 宗教场所 8 校园周边 9 治安复杂区域 10 交通干线
+	Ext             DeviceExt    `gorm:"column:ext;notNull;default:'{}';type:jsonb" json:"ext"`
+	EnabledAudio    bool         `gorm:"column:enabled_audio;notNull;default:FALSE;comment:是否开启音频" json:"enabled_audio"`     // 点播时是否请求音频
+	Media           ChannelMedia `gorm:"column:media;notNull;default:'{}';type:jsonb;comment:协商的媒体格式" json:"media"`          // 最近一次点播协商的编码
+	CreatedAt       orm.Time     `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"` // 创建时间
+	UpdatedAt       orm.Time     `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"` // 更新时间
 }
 
 // TableName database table name
 func (*Channel) TableName() string {
 	return "channels"
 }
+
+// applyCatalog 更新目录上报的通道属性，名称与云台类型可由用户修改，设备未上报时保留
+func (c *Channel) applyCatalog(src *Channel) {
+	c.ParentID = src.ParentID
+	c.CivilCode = src.CivilCode
+	c.BusinessGroupID = src.BusinessGroupID
+	c.Owner = src.Owner
+	c.Address = src.Address
+	c.Longitude = src.Longitude
+	c.Latitude = src.Latitude
+	c.PositionType = src.PositionType
+	if src.PTZType > 0 {
+		c.PTZType = src.PTZType
+	}
+
+	ext := src.Ext
+	c.Ext.Manufacturer = ext.Manufacturer
+	c.Ext.Model = ext.Model
+	c.Ext.Block = ext.Block
+	c.Ext.IPAddress = ext.IPAddress
+	c.Ext.Port = ext.Port
+	c.Ext.Parental = ext.Parental
+	c.Ext.SafetyWay = ext.SafetyWay
+	c.Ext.RegisterWay = ext.RegisterWay
+	c.Ext.CertNum = ext.CertNum
+	c.Ext.Certifiable = ext.Certifiable
+	c.Ext.ErrCode = ext.ErrCode
+	c.Ext.EndTime = ext.EndTime
+	c.Ext.Secrecy = ext.Secrecy
+	c.Ext.Info = ext.Info
+}
+
+// ChannelMedia 点播时设备应答 SDP 中的编码
+type ChannelMedia struct {
+	Video     string   `json:"video"`      // 视频编码，如 PS/H264/H265
+	Audio     string   `json:"audio"`      // 音频编码，未开启音频或设备不支持时为空
+	UpdatedAt orm.Time `json:"updated_at"` // 协商时间
+}
+
+// Scan implements orm.Scaner.
+func (i *ChannelMedia) Scan(input interface{}) error {
+	return orm.JsonUnmarshal(input, i)
+}
+
+func (i ChannelMedia) Value() (driver.Value, error) {
+	return json.Marshal(i)
+}
@@ -11,14 +11,24 @@ type FindChannelInput struct {
 	// Name     string    `form:"name"`      // 通道名称
 	// PTZType  int       `form:"ptztype"`   // 云台类型
 	IsOnline string `form:"is_online"` // 是否在线
+
+	CivilCode       string `form:"civil_code"`        // 行政区划，前缀匹配，可查询下级区划的通道
+	ParentID        string `form:"parent_id"`         // 父节点编码
+	BusinessGroupID string `form:"business_group_id"` // 业务分组编码
+	Owner           string `form:"owner"`             // 设备归属
+	Address         string `form:"address"`           // 安装地址，模糊搜索
+	PTZType         int    `form:"ptztype"`           // 云台类型，0 表示不过滤
+	PositionType    int    `form:"position_type"`     // 位置类型，0 表示不过滤
+	HasPosition     string `form:"has_position"`      // 是否有经纬度 true/false
 }
 
 type EditChannelInput struct {
-	DeviceID string    `json:"device_id"` // 国标编码
-	Name     string    `json:"name"`      // 通道名称
-	PTZType  int       `json:"ptztype"`   // 云台类型
-	IsOnline bool      `json:"is_online"` // 是否在线
-	Ext      DeviceExt `json:"ext"`
+	DeviceID     string    `json:"device_id"` // 国标编码
+	Name         string    `json:"name"`      // 通道名称
+	PTZType      int       `json:"ptztype"`   // 云台类型
+	IsOnline     bool      `json:"is_online"` // 是否在线
+	Ext          DeviceExt `json:"ext"`
+	EnabledAudio bool      `json:"enabled_audio"` // 点播时是否请求音频
 }
 
 type AddChannelInput struct {
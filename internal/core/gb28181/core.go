@@ -7,6 +7,16 @@ import "github.com/ixugo/goddd/domain/uniqueid"
 type Storer interface {
 	Device() DeviceStorer
 	Channel() ChannelStorer
+	Preset() PresetStorer
+	Cruise() CruiseStorer
+	Download() DownloadStorer
+	Alarm() AlarmStorer
+	Position() PositionStorer
+	Firmware() FirmwareStorer
+	Upgrade() UpgradeStorer
+	CatalogGroup() CatalogGroupStorer
+	Platform() PlatformStorer
+	PlatformChannel() PlatformChannelStorer
 }
 
 // Core business domain
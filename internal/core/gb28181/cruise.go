@@ -0,0 +1,92 @@
+package gb28181
+
+import (
+	"context"
+
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// CruiseStorer Instantiation interface
+type CruiseStorer interface {
+	Find(context.Context, *[]*Cruise, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Cruise, ...orm.QueryOption) error
+	Add(context.Context, *Cruise) error
+	Edit(context.Context, *Cruise, func(*Cruise), ...orm.QueryOption) error
+	Del(context.Context, *Cruise, ...orm.QueryOption) error
+}
+
+// FindCruise 查询通道的全部巡航轨迹
+func (c Core) FindCruise(ctx context.Context, cid string) ([]*Cruise, error) {
+	items := make([]*Cruise, 0, 4)
+	query := orm.NewQuery(1).OrderBy("cruise_id ASC").Where("cid=?", cid)
+	if _, err := c.store.Cruise().Find(ctx, &items, web.NewPagerFilterMaxSize(), query.Encode()...); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, nil
+}
+
+// GetCruise Query a single object
+func (c Core) GetCruise(ctx context.Context, cid string, cruiseID int) (*Cruise, error) {
+	var out Cruise
+	if err := c.store.Cruise().Get(ctx, &out, orm.Where("cid=? AND cruise_id=?", cid, cruiseID)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// AddCruise 新增巡航轨迹，组号已存在时覆盖
+func (c Core) AddCruise(ctx context.Context, cid string, in *AddCruiseInput) (*Cruise, error) {
+	var out Cruise
+	if err := c.store.Cruise().Edit(ctx, &out, func(b *Cruise) {
+		b.Name = in.Name
+		b.Points = in.Points
+		b.Speed = in.Speed
+		b.DwellTime = in.DwellTime
+	}, orm.Where("cid=? AND cruise_id=?", cid, in.CruiseID)); err == nil {
+		return &out, nil
+	}
+
+	out = Cruise{
+		CID:       cid,
+		CruiseID:  in.CruiseID,
+		Name:      in.Name,
+		Points:    in.Points,
+		Speed:     in.Speed,
+		DwellTime: in.DwellTime,
+	}
+	if err := c.store.Cruise().Add(ctx, &out); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// EditCruise Update object information
+func (c Core) EditCruise(ctx context.Context, in *EditCruiseInput, cid string, cruiseID int) (*Cruise, error) {
+	var out Cruise
+	if err := c.store.Cruise().Edit(ctx, &out, func(b *Cruise) {
+		b.Name = in.Name
+		b.Points = in.Points
+		b.Speed = in.Speed
+		b.DwellTime = in.DwellTime
+	}, orm.Where("cid=? AND cruise_id=?", cid, cruiseID)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Edit err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Edit err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// DelCruise Delete object
+func (c Core) DelCruise(ctx context.Context, cid string, cruiseID int) (*Cruise, error) {
+	var out Cruise
+	if err := c.store.Cruise().Del(ctx, &out, orm.Where("cid=? AND cruise_id=?", cid, cruiseID)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
@@ -0,0 +1,41 @@
+package gb28181
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// Cruise 通道巡航轨迹
+type Cruise struct {
+	ID        int          `gorm:"primaryKey" json:"id"`
+	CID       string       `gorm:"column:cid;notNull;default:'';uniqueIndex:idx_cruises_cid_cruise_id;comment:通道 ID" json:"cid"`           // 通道 ID
+	CruiseID  int          `gorm:"column:cruise_id;notNull;default:0;uniqueIndex:idx_cruises_cid_cruise_id;comment:巡航组号" json:"cruise_id"` // 巡航组号 0~255
+	Name      string       `gorm:"column:name;notNull;default:'';comment:巡航名称" json:"name"`                                                // 巡航名称
+	Points    CruisePoints `gorm:"column:points;notNull;default:'[]';type:jsonb;comment:巡航点" json:"points"`                                // 巡航点，按顺序排列的预置位编号
+	Speed     int          `gorm:"column:speed;notNull;default:0;comment:巡航速度" json:"speed"`                                               // 巡航速度 0~4095
+	DwellTime int          `gorm:"column:dwell_time;notNull;default:0;comment:停留时间(秒)" json:"dwell_time"`                                  // 每个巡航点的停留时间，单位秒 0~4095
+	CreatedAt orm.Time     `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`                     // 创建时间
+	UpdatedAt orm.Time     `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"`                     // 更新时间
+}
+
+// TableName database table name
+func (*Cruise) TableName() string {
+	return "cruises"
+}
+
+// CruisePoints 巡航点
+type CruisePoints []int
+
+// Scan implements orm.Scaner.
+func (i *CruisePoints) Scan(input interface{}) error {
+	return orm.JsonUnmarshal(input, i)
+}
+
+func (i CruisePoints) Value() (driver.Value, error) {
+	if i == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(i)
+}
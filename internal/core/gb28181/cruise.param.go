@@ -0,0 +1,16 @@
+package gb28181
+
+type EditCruiseInput struct {
+	Name      string       `json:"name"`                                   // 巡航名称
+	Points    CruisePoints `json:"points" binding:"required,min=1,max=32"` // 巡航点
+	Speed     int          `json:"speed" binding:"min=0,max=4095"`         // 巡航速度
+	DwellTime int          `json:"dwell_time" binding:"min=0,max=4095"`    // 停留时间(秒)
+}
+
+type AddCruiseInput struct {
+	CruiseID  int          `json:"cruise_id" binding:"min=0,max=255"`      // 巡航组号
+	Name      string       `json:"name"`                                   // 巡航名称
+	Points    CruisePoints `json:"points" binding:"required,min=1,max=32"` // 巡航点
+	Speed     int          `json:"speed" binding:"min=0,max=4095"`         // 巡航速度
+	DwellTime int          `json:"dwell_time" binding:"min=0,max=4095"`    // 停留时间(秒)
+}
@@ -29,6 +29,7 @@ type EditDeviceInput struct {
 	Name       string `json:"name"`        // 设备名称
 	Password   string `json:"password"`    // 注册密码
 	StreamMode int    `json:"stream_mode"` // 数据传输模式
+	Profile    string `json:"profile"`     // 厂商适配，为空时自动识别
 
 	// IP           string    `json:"ip"`
 	// Port         int       `json:"port"`
@@ -0,0 +1,119 @@
+package gb28181
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// DownloadStorer Instantiation interface
+type DownloadStorer interface {
+	Find(context.Context, *[]*Download, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Download, ...orm.QueryOption) error
+	Add(context.Context, *Download) error
+	Edit(context.Context, *Download, func(*Download), ...orm.QueryOption) error
+	Del(context.Context, *Download, ...orm.QueryOption) error
+}
+
+// defaultDownloadSpeed 未指定倍速时的默认下载倍速
+const defaultDownloadSpeed = 4
+
+// FindDownload Paginated search
+func (c Core) FindDownload(ctx context.Context, in *FindDownloadInput) ([]*Download, int64, error) {
+	items := make([]*Download, 0)
+	query := orm.NewQuery(2).OrderBy("created_at DESC")
+	if in.CID != "" {
+		query.Where("cid=?", in.CID)
+	}
+	if in.Status != "" {
+		query.Where("status=?", in.Status)
+	}
+	total, err := c.store.Download().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	now := time.Now()
+	for _, item := range items {
+		item.estimateProgress(now)
+	}
+	return items, total, nil
+}
+
+// GetDownload Query a single object
+func (c Core) GetDownload(ctx context.Context, id string) (*Download, error) {
+	var out Download
+	if err := c.store.Download().Get(ctx, &out, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	out.estimateProgress(time.Now())
+	return &out, nil
+}
+
+// AddDownload 创建下载任务，任务 ID 即收流的 stream
+func (c Core) AddDownload(ctx context.Context, ch *Channel, in *AddDownloadInput) (*Download, error) {
+	if in.EndTime <= in.StartTime {
+		return nil, reason.ErrBadRequest.SetMsg("结束时间必须大于开始时间")
+	}
+	speed := in.Speed
+	if speed <= 0 {
+		speed = defaultDownloadSpeed
+	}
+	out := Download{
+		ID:        c.uniqueID.UniqueID(bz.IDPrefixDownload),
+		CID:       ch.ID,
+		DeviceID:  ch.DeviceID,
+		ChannelID: ch.ChannelID,
+		StartTime: in.StartTime,
+		EndTime:   in.EndTime,
+		Speed:     speed,
+		Status:    DownloadStatusPending,
+	}
+	if err := c.store.Download().Add(ctx, &out); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// EditDownload 修改下载任务
+func (c Core) EditDownload(ctx context.Context, id string, changeFn func(*Download)) (*Download, error) {
+	var out Download
+	if err := c.store.Download().Edit(ctx, &out, changeFn, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Edit err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Edit err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// DelDownload Delete object
+func (c Core) DelDownload(ctx context.Context, id string) (*Download, error) {
+	var out Download
+	if err := c.store.Download().Del(ctx, &out, orm.Where("id=?", id)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// estimateProgress 下载中的任务按已用时长与倍速估算进度，设备通知完成(MediaStatus 121)前最多 99
+func (d *Download) estimateProgress(now time.Time) {
+	if d.Status != DownloadStatusDownloading || d.StartedAt <= 0 || d.Progress >= 100 {
+		return
+	}
+	speed := d.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	total := float64(d.EndTime-d.StartTime) / float64(speed)
+	if total <= 0 {
+		return
+	}
+	p := int(float64(now.Unix()-d.StartedAt) * 100 / total)
+	d.Progress = min(max(p, d.Progress), 99)
+}
@@ -0,0 +1,46 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/orm"
+
+// 下载任务状态
+const (
+	DownloadStatusPending     = "pending"     // 等待设备推流
+	DownloadStatusDownloading = "downloading" // 下载中
+	DownloadStatusCompleted   = "completed"   // 已完成，文件可下载
+	DownloadStatusFailed      = "failed"      // 失败
+	DownloadStatusCanceled    = "canceled"    // 已取消
+)
+
+// Download 录像下载任务，ID 同时作为 ZLM 收流的 stream
+type Download struct {
+	ID        string   `gorm:"primaryKey" json:"id"`
+	CID       string   `gorm:"column:cid;index;notNull;default:'';comment:通道 ID" json:"cid"`                       // 通道 ID
+	DeviceID  string   `gorm:"column:device_id;notNull;default:'';comment:国标编码" json:"device_id"`                  // 设备国标编码
+	ChannelID string   `gorm:"column:channel_id;notNull;default:'';comment:国标编码" json:"channel_id"`                // 通道国标编码
+	StartTime int64    `gorm:"column:start_time;notNull;default:0;comment:录像开始时间" json:"start_time"`               // 录像开始时间，unix 秒
+	EndTime   int64    `gorm:"column:end_time;notNull;default:0;comment:录像结束时间" json:"end_time"`                   // 录像结束时间，unix 秒
+	Speed     int      `gorm:"column:speed;notNull;default:1;comment:下载倍速" json:"speed"`                           // 设备应答的下载倍速
+	Status    string   `gorm:"column:status;index;notNull;default:'';comment:任务状态" json:"status"`                  // 任务状态
+	Progress  int      `gorm:"column:progress;notNull;default:0;comment:下载进度" json:"progress"`                     // 下载进度 0~100
+	FilePath  string   `gorm:"column:file_path;notNull;default:'';comment:文件路径" json:"-"`                          // ZLM 本地文件路径
+	FileURL   string   `gorm:"column:file_url;notNull;default:'';comment:文件地址" json:"file_url"`                    // ZLM http 相对地址
+	FileSize  int64    `gorm:"column:file_size;notNull;default:0;comment:文件大小" json:"file_size"`                   // 文件大小，字节
+	StartedAt int64    `gorm:"column:started_at;notNull;default:0;comment:开始下载时间" json:"started_at"`               // 设备开始推流时间，unix 秒
+	Error     string   `gorm:"column:error;notNull;default:'';comment:失败原因" json:"error"`                          // 失败原因
+	CreatedAt orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"` // 创建时间
+	UpdatedAt orm.Time `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"` // 更新时间
+}
+
+// TableName database table name
+func (*Download) TableName() string {
+	return "downloads"
+}
+
+// IsDone 任务是否已结束
+func (d *Download) IsDone() bool {
+	switch d.Status {
+	case DownloadStatusCompleted, DownloadStatusFailed, DownloadStatusCanceled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/web"
+
+type FindDownloadInput struct {
+	web.PagerFilter
+	CID    string `form:"cid"`    // 通道 ID
+	Status string `form:"status"` // 任务状态
+}
+
+type AddDownloadInput struct {
+	CID       string `json:"cid" binding:"required"`        // 通道 ID
+	StartTime int64  `json:"start_time" binding:"required"` // 录像开始时间，unix 秒
+	EndTime   int64  `json:"end_time" binding:"required"`   // 录像结束时间，unix 秒
+	Speed     int    `json:"speed" binding:"min=0,max=8"`   // 下载倍速，默认 4
+}
@@ -0,0 +1,75 @@
+package gb28181
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// FirmwareStorer Instantiation interface
+type FirmwareStorer interface {
+	Find(context.Context, *[]*Firmware, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Firmware, ...orm.QueryOption) error
+	Add(context.Context, *Firmware) error
+	Del(context.Context, *Firmware, ...orm.QueryOption) error
+}
+
+// FindFirmware Paginated search
+func (c Core) FindFirmware(ctx context.Context, in *FindFirmwareInput) ([]*Firmware, int64, error) {
+	items := make([]*Firmware, 0)
+	query := orm.NewQuery(1).OrderBy("created_at DESC")
+	if in.Manufacturer != "" {
+		query.Where("manufacturer=?", in.Manufacturer)
+	}
+	total, err := c.store.Firmware().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, total, nil
+}
+
+// GetFirmware Query a single object
+func (c Core) GetFirmware(ctx context.Context, id string) (*Firmware, error) {
+	var out Firmware
+	if err := c.store.Firmware().Get(ctx, &out, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// NewFirmwareID 固件 ID，先于入库生成，用作文件存储名
+func (c Core) NewFirmwareID() string {
+	return c.uniqueID.UniqueID(bz.IDPrefixFirmware)
+}
+
+// AddFirmware Insert into database，文件由调用方写入
+func (c Core) AddFirmware(ctx context.Context, in *Firmware) (*Firmware, error) {
+	if in.ID == "" {
+		in.ID = c.NewFirmwareID()
+	}
+	if err := c.store.Firmware().Add(ctx, in); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return in, nil
+}
+
+// DelFirmware Delete object，被未结束的升级任务引用时拒绝删除
+func (c Core) DelFirmware(ctx context.Context, id string) (*Firmware, error) {
+	used, err := c.firmwareInUse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, reason.ErrUsedLogic.SetMsg("固件正在被升级任务使用")
+	}
+	var out Firmware
+	if err := c.store.Firmware().Del(ctx, &out, orm.Where("id=?", id)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
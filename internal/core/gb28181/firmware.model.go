@@ -0,0 +1,20 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/orm"
+
+// Firmware 设备固件，文件存储在数据目录，由本服务提供下载
+type Firmware struct {
+	ID           string   `gorm:"primaryKey" json:"id"`
+	Name         string   `gorm:"column:name;notNull;default:'';comment:名称" json:"name"`                              // 名称
+	Version      string   `gorm:"column:version;notNull;default:'';comment:固件版本" json:"version"`                      // 固件版本，即升级命令中的 Firmware
+	Manufacturer string   `gorm:"column:manufacturer;notNull;default:'';comment:厂商" json:"manufacturer"`              // 适用厂商
+	FileName     string   `gorm:"column:file_name;notNull;default:'';comment:文件名" json:"file_name"`                   // 上传时的文件名
+	FileSize     int64    `gorm:"column:file_size;notNull;default:0;comment:文件大小" json:"file_size"`                   // 文件大小，字节
+	Checksum     string   `gorm:"column:checksum;notNull;default:'';comment:sha256" json:"checksum"`                  // 文件 sha256
+	CreatedAt    orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"` // 创建时间
+}
+
+// TableName database table name
+func (*Firmware) TableName() string {
+	return "firmwares"
+}
@@ -0,0 +1,14 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/web"
+
+type FindFirmwareInput struct {
+	web.PagerFilter
+	Manufacturer string `form:"manufacturer"` // 厂商
+}
+
+type AddFirmwareInput struct {
+	Name         string `form:"name"`                       // 名称
+	Version      string `form:"version" binding:"required"` // 固件版本
+	Manufacturer string `form:"manufacturer"`               // 适用厂商
+}
@@ -74,6 +74,15 @@ func (g GB28181) EditPlaying(deviceID, channelID string, playing bool) error {
 	return nil
 }
 
+// EditChannelMedia 记录点播协商的编码
+func (g GB28181) EditChannelMedia(deviceID, channelID string, media ChannelMedia) error {
+	var ch Channel
+	return g.store.Channel().Edit(context.TODO(), &ch, func(c *Channel) {
+		media.UpdatedAt = orm.Now()
+		c.Media = media
+	}, orm.Where("device_id = ? AND channel_id = ?", deviceID, channelID))
+}
+
 func (g GB28181) SaveChannels(channels []*Channel) error {
 	if len(channels) <= 0 {
 		return nil
@@ -89,6 +98,7 @@ func (g GB28181) SaveChannels(channels []*Channel) error {
 		var ch Channel
 		if err := g.store.Channel().Edit(context.TODO(), &ch, func(c *Channel) {
 			c.IsOnline = channel.IsOnline
+			c.applyCatalog(channel)
 			ch.DID = dev.ID
 		}, orm.Where("device_id = ? AND channel_id = ?", channel.DeviceID, channel.ChannelID)); err != nil {
 			channel.ID = g.uni.UniqueID(bz.IDPrefixGBChannel)
@@ -113,3 +123,223 @@ func (g GB28181) FindDevices(ctx context.Context) ([]*Device, error) {
 	}
 	return devices, nil
 }
+
+// SavePresets 保存设备上报的预置位，平台已命名的预置位保留原名称
+// prune 为 true 表示预置位已收齐，删除设备已不存在的预置位
+func (g GB28181) SavePresets(deviceID, channelID string, presets []*Preset, prune bool) error {
+	ctx := context.TODO()
+	var ch Channel
+	if err := g.store.Channel().Get(ctx, &ch, orm.Where("device_id = ? AND channel_id = ?", deviceID, channelID)); err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(presets))
+	for _, preset := range presets {
+		ids = append(ids, preset.PresetID)
+		var p Preset
+		err := g.store.Preset().Edit(ctx, &p, func(p *Preset) {
+			if p.Name == "" {
+				p.Name = preset.Name
+			}
+		}, orm.Where("cid=? AND preset_id=?", ch.ID, preset.PresetID))
+		if err == nil {
+			continue
+		}
+		if !orm.IsErrRecordNotFound(err) {
+			return err
+		}
+		preset.CID = ch.ID
+		if err := g.store.Preset().Add(ctx, preset); err != nil {
+			return err
+		}
+	}
+	if !prune {
+		return nil
+	}
+	var out Preset
+	if len(ids) == 0 {
+		return g.store.Preset().Del(ctx, &out, orm.Where("cid=?", ch.ID))
+	}
+	return g.store.Preset().Del(ctx, &out, orm.Where("cid=? AND preset_id NOT IN ?", ch.ID, ids))
+}
+
+// EditDownload 修改下载任务，供信令与媒体回调更新任务状态
+func (g GB28181) EditDownload(id string, changeFn func(*Download)) error {
+	var d Download
+	return g.store.Download().Edit(context.TODO(), &d, changeFn, orm.Where("id=?", id))
+}
+
+// EditUpgrade 修改设备的升级任务，供信令更新升级结果，任务不属于该设备时返回未找到
+func (g GB28181) EditUpgrade(deviceID, id string, changeFn func(*Upgrade)) error {
+	var u Upgrade
+	return g.store.Upgrade().Edit(context.TODO(), &u, changeFn, orm.Where("id=? AND device_id=?", id, deviceID))
+}
+
+// SaveAlarm 保存设备上报的报警
+func (g GB28181) SaveAlarm(alarm *Alarm) error {
+	return g.store.Alarm().Add(context.TODO(), alarm)
+}
+
+// SavePosition 保存设备上报的位置
+func (g GB28181) SavePosition(pos *Position) error {
+	return g.store.Position().Add(context.TODO(), pos)
+}
+
+// SaveChannel 目录订阅通知新增或更新单个通道
+func (g GB28181) SaveChannel(channel *Channel) error {
+	ctx := context.TODO()
+	var ch Channel
+	err := g.store.Channel().Edit(ctx, &ch, func(c *Channel) {
+		c.Name = channel.Name
+		c.IsOnline = channel.IsOnline
+		c.applyCatalog(channel)
+	}, orm.Where("device_id = ? AND channel_id = ?", channel.DeviceID, channel.ChannelID))
+	if err == nil {
+		return nil
+	}
+	if !orm.IsErrRecordNotFound(err) {
+		return err
+	}
+
+	var dev Device
+	if err := g.store.Device().Get(ctx, &dev, orm.Where("device_id=?", channel.DeviceID)); err != nil {
+		return err
+	}
+	channel.ID = g.uni.UniqueID(bz.IDPrefixGBChannel)
+	channel.DID = dev.ID
+	if err := g.store.Channel().Add(ctx, channel); err != nil {
+		return err
+	}
+	return g.refreshChannelCount(ctx, channel.DeviceID)
+}
+
+// DelChannel 目录订阅通知删除通道
+func (g GB28181) DelChannel(deviceID, channelID string) error {
+	ctx := context.TODO()
+	var ch Channel
+	if err := g.store.Channel().Del(ctx, &ch, orm.Where("device_id = ? AND channel_id = ?", deviceID, channelID)); err != nil {
+		return err
+	}
+	return g.refreshChannelCount(ctx, deviceID)
+}
+
+// EditChannelOnline 目录订阅通知通道上下线
+func (g GB28181) EditChannelOnline(deviceID, channelID string, online bool) error {
+	var ch Channel
+	return g.store.Channel().Edit(context.TODO(), &ch, func(c *Channel) {
+		c.IsOnline = online
+	}, orm.Where("device_id = ? AND channel_id = ?", deviceID, channelID))
+}
+
+// refreshChannelCount 重新统计设备通道数
+func (g GB28181) refreshChannelCount(ctx context.Context, deviceID string) error {
+	var items []*Channel
+	total, err := g.store.Channel().Find(ctx, &items, web.PagerFilter{Page: 1, Size: 1}, orm.Where("device_id=?", deviceID))
+	if err != nil {
+		return err
+	}
+	var dev Device
+	return g.store.Device().Edit(ctx, &dev, func(d *Device) {
+		d.Channels = int(total)
+	}, orm.Where("device_id=?", deviceID))
+}
+
+// SaveCatalogGroups 保存目录中的分组节点，prune 为 true 表示目录已收齐，删除本次目录中已不存在的分组
+func (g GB28181) SaveCatalogGroups(deviceID string, groups []*CatalogGroup, prune bool) error {
+	ids := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if err := g.SaveCatalogGroup(deviceID, group); err != nil {
+			return err
+		}
+		ids = append(ids, group.GroupID)
+	}
+	if !prune {
+		return nil
+	}
+	var out CatalogGroup
+	if len(ids) == 0 {
+		return g.store.CatalogGroup().Del(context.TODO(), &out, orm.Where("device_id = ?", deviceID))
+	}
+	return g.store.CatalogGroup().Del(context.TODO(), &out, orm.Where("device_id = ? AND group_id NOT IN ?", deviceID, ids))
+}
+
+// SaveCatalogGroup 新增或更新单个分组节点
+func (g GB28181) SaveCatalogGroup(deviceID string, group *CatalogGroup) error {
+	ctx := context.TODO()
+	var out CatalogGroup
+	err := g.store.CatalogGroup().Edit(ctx, &out, func(c *CatalogGroup) {
+		c.Name = group.Name
+		c.Type = group.Type
+		c.ParentID = group.ParentID
+		c.CivilCode = group.CivilCode
+		c.BusinessGroupID = group.BusinessGroupID
+	}, orm.Where("device_id = ? AND group_id = ?", deviceID, group.GroupID))
+	if err == nil || !orm.IsErrRecordNotFound(err) {
+		return err
+	}
+
+	var dev Device
+	if err := g.store.Device().Get(ctx, &dev, orm.Where("device_id=?", deviceID)); err != nil {
+		return err
+	}
+	group.ID = g.uni.UniqueID(bz.IDPrefixCatalog)
+	group.DID = dev.ID
+	group.DeviceID = deviceID
+	return g.store.CatalogGroup().Add(ctx, group)
+}
+
+// DelCatalogGroup 目录订阅通知删除分组节点
+func (g GB28181) DelCatalogGroup(deviceID, groupID string) error {
+	var out CatalogGroup
+	return g.store.CatalogGroup().Del(context.TODO(), &out, orm.Where("device_id = ? AND group_id = ?", deviceID, groupID))
+}
+
+// FindEnabledPlatforms 获取已启用的上级平台
+func (g GB28181) FindEnabledPlatforms(ctx context.Context) ([]*Platform, error) {
+	var out []*Platform
+	if _, err := g.store.Platform().Find(ctx, &out, web.NewPagerFilterMaxSize(), orm.Where("enabled=?", true)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EditPlatform 修改上级平台，供信令更新注册状态
+func (g GB28181) EditPlatform(id string, changeFn func(*Platform)) error {
+	var p Platform
+	return g.store.Platform().Edit(context.TODO(), &p, changeFn, orm.Where("id=?", id))
+}
+
+// FindPlatformChannels 获取共享给上级平台的通道，同时返回其中国标通道的详情，key 为通道 ID
+func (g GB28181) FindPlatformChannels(ctx context.Context, platformID string) ([]*PlatformChannel, map[string]*Channel, error) {
+	var shares []*PlatformChannel
+	if _, err := g.store.PlatformChannel().Find(ctx, &shares, web.NewPagerFilterMaxSize(), orm.Where("platform_id=?", platformID), orm.OrderBy("created_at ASC")); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, 0, len(shares))
+	for _, s := range shares {
+		if s.Source == PlatformSourceGB {
+			ids = append(ids, s.ChannelID)
+		}
+	}
+	channels := make(map[string]*Channel, len(ids))
+	if len(ids) == 0 {
+		return shares, channels, nil
+	}
+	var items []*Channel
+	if _, err := g.store.Channel().Find(ctx, &items, web.NewPagerFilterMaxSize(), orm.Where("id IN ?", ids)); err != nil {
+		return nil, nil, err
+	}
+	for _, ch := range items {
+		channels[ch.ID] = ch
+	}
+	return shares, channels, nil
+}
+
+// GetPlatformChannel 按上报的国标编码查询共享通道
+func (g GB28181) GetPlatformChannel(ctx context.Context, platformID, gbID string) (*PlatformChannel, error) {
+	var out PlatformChannel
+	if err := g.store.PlatformChannel().Get(ctx, &out, orm.Where("platform_id=? AND gb_id=?", platformID, gbID)); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
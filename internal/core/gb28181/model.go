@@ -14,6 +14,75 @@ type DeviceExt struct {
 	Model        string `json:"model"`        // 型号
 	Firmware     string `json:"firmware"`     // 固件版本
 	Name         string `json:"name"`         // 设备名
+	UserAgent    string `json:"user_agent"`   // 注册时的 User-Agent
+
+	Status *DeviceStatus `json:"status,omitempty"` // 设备状态，定时查询更新
+
+	// 以下为目录上报的通道属性 GB/T28181 A.2.6.4
+	Block       string       `json:"block,omitempty"`      // 警区
+	IPAddress   string       `json:"ip_address,omitempty"` // 设备/系统 IP 地址
+	Port        int          `json:"port,omitempty"`       // 设备/系统端口
+	Parental    int          `json:"parental"`             // 是否有子设备 1 有 0 没有
+	SafetyWay   int          `json:"safety_way"`           // 信令安全模式 0 不采用 2 S/MIME 签名 3 S/MIME 加密签名 4 数字摘要
+	RegisterWay int          `json:"register_way"`         // 注册方式 1 IETF RFC3261 2 基于口令的双向认证 3 基于数字证书的双向认证
+	CertNum     string       `json:"cert_num,omitempty"`   // 证书序列号
+	Certifiable int          `json:"certifiable"`          // 证书有效标识 0 无效 1 有效
+	ErrCode     int          `json:"err_code,omitempty"`   // 证书无效原因码
+	EndTime     string       `json:"end_time,omitempty"`   // 证书终止有效期
+	Secrecy     int          `json:"secrecy"`              // 保密属性 0 不涉密 1 涉密
+	Info        *ChannelInfo `json:"info,omitempty"`       // 扩展信息
+}
+
+// ChannelInfo 目录项扩展信息，GB/T28181-2016 与 2022 的 Info 字段
+type ChannelInfo struct {
+	PTZType                  int    `xml:"PTZType" json:"ptz_type"`                                                       // 摄像机类型 1 球机 2 半球 3 固定枪机 4 遥控枪机 5 遥控半球 6 多目设备的全景/拼接通道 7 多目设备的分割通道
+	PositionType             int    `xml:"PositionType" json:"position_type"`                                             // 摄像机位置类型
+	PhotoelectricImagingType string `xml:"PhotoelectricImagingTyp,omitempty" json:"photoelectric_imaging_type,omitempty"` // 摄像机光电成像类型，标准中即为此拼写
+	CapturePositionType      string `xml:"CapturePositionType,omitempty" json:"capture_position_type,omitempty"`          // 摄像机采集部位类型
+	RoomType                 int    `xml:"RoomType" json:"room_type"`                                                     // 安装位置室外/室内 1 室外 2 室内
+	UseType                  int    `xml:"UseType" json:"use_type"`                                                       // 用途 1 治安 2 交通 3 重点
+	SupplyLightType          int    `xml:"SupplyLightType" json:"supply_light_type"`                                      // 补光属性 1 无补光 2 红外补光 3 白光补光 4 激光补光 9 其他
+	DirectionType            int    `xml:"DirectionType" json:"direction_type"`                                           // 监视方位 1 东 2 西 3 南 4 北 5 东南 6 东北 7 西南 8 西北
+	Resolution               string `xml:"Resolution" json:"resolution"`                                                  // 支持的分辨率，多个以 "/" 分隔
+	StreamNumberList         string `xml:"StreamNumberList,omitempty" json:"stream_number_list,omitempty"`                // 支持的码流编号列表，如 0/1/2
+	BusinessGroupID          string `xml:"BusinessGroupID,omitempty" json:"business_group_id,omitempty"`                  // 虚拟组织所属的业务分组 ID，2016 版
+	DownloadSpeed            string `xml:"DownloadSpeed" json:"download_speed"`                                           // 下载倍速范围，如 1/2/4
+	SVCSpaceSupportMode      int    `xml:"SVCSpaceSupportMode" json:"svc_space_support_mode"`                             // 空域编码能力
+	SVCTimeSupportMode       int    `xml:"SVCTimeSupportMode" json:"svc_time_support_mode"`                               // 时域编码能力
+	SSVCRatioSupportList     string `xml:"SSVCRatioSupportList,omitempty" json:"ssvc_ratio_support_list,omitempty"`       // SSVC 增强层与基本层比例能力
+	MobileDeviceType         int    `xml:"MobileDeviceType,omitempty" json:"mobile_device_type,omitempty"`                // 移动采集设备类型 1 移动机器人 2 执法记录仪 3 移动摄像机 4 无人机
+	HorizontalFieldAngle     string `xml:"HorizontalFieldAngle,omitempty" json:"horizontal_field_angle,omitempty"`        // 水平视场角
+	VerticalFieldAngle       string `xml:"VerticalFieldAngle,omitempty" json:"vertical_field_angle,omitempty"`            // 竖直视场角
+	MaxViewDistance          string `xml:"MaxViewDistance,omitempty" json:"max_view_distance,omitempty"`                  // 可视距离
+	GrassrootsCode           string `xml:"GrassrootsCode,omitempty" json:"grassroots_code,omitempty"`                     // 基层组织编码
+	PoType                   int    `xml:"PoType,omitempty" json:"po_type,omitempty"`                                     // 监控点位类型
+	PoCommonName             string `xml:"PoCommonName,omitempty" json:"po_common_name,omitempty"`                        // 点位俗称
+	MAC                      string `xml:"MAC,omitempty" json:"mac,omitempty"`                                            // 设备 MAC 地址
+	FunctionType             string `xml:"FunctionType,omitempty" json:"function_type,omitempty"`                         // 摄像机卡口功能类型
+	EncodeType               string `xml:"EncodeType,omitempty" json:"encode_type,omitempty"`                             // 摄像机视频编码格式
+	InstallTime              string `xml:"InstallTime,omitempty" json:"install_time,omitempty"`                           // 摄像机安装使用时间
+	ManagementUnit           string `xml:"ManagementUnit,omitempty" json:"management_unit,omitempty"`                     // 摄像机所属管理单位
+	ContactInfo              string `xml:"ContactInfo,omitempty" json:"contact_info,omitempty"`                           // 摄像机所属管理单位联系方式
+	RecordSaveDays           int    `xml:"RecordSaveDays,omitempty" json:"record_save_days,omitempty"`                    // 录像保存天数
+	IndustrialClassification string `xml:"IndustrialClassification,omitempty" json:"industrial_classification,omitempty"` // 国民经济行业分类代码
+}
+
+// DeviceStatus 设备状态 GB/T28181 A.2.6.6
+type DeviceStatus struct {
+	Online     string        `json:"online"`           // 是否在线 ONLINE/OFFLINE
+	Status     string        `json:"status"`           // 是否正常工作 OK/ERROR
+	Reason     string        `json:"reason,omitempty"` // 不正常工作原因
+	Encode     string        `json:"encode"`           // 是否编码 ON/OFF
+	Record     string        `json:"record"`           // 是否录像 ON/OFF
+	DeviceTime string        `json:"device_time"`      // 设备时间
+	Alarms     []AlarmStatus `json:"alarms,omitempty"` // 报警设备状态列表
+	UpdatedAt  orm.Time      `json:"updated_at"`       // 查询时间
+}
+
+// AlarmStatus 报警设备状态
+type AlarmStatus struct {
+	DeviceID   string `json:"device_id"`   // 报警设备编码
+	DutyStatus string `json:"duty_status"` // 报警设备状态 ONDUTY/OFFDUTY/ALARM
 }
 
 // Scan implements orm.Scaner.
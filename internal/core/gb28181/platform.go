@@ -0,0 +1,180 @@
+package gb28181
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/jinzhu/copier"
+)
+
+// PlatformStorer Instantiation interface
+type PlatformStorer interface {
+	Find(context.Context, *[]*Platform, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Platform, ...orm.QueryOption) error
+	Add(context.Context, *Platform) error
+	Edit(context.Context, *Platform, func(*Platform), ...orm.QueryOption) error
+	Del(context.Context, *Platform, ...orm.QueryOption) error
+}
+
+// PlatformChannelStorer Instantiation interface
+type PlatformChannelStorer interface {
+	Find(context.Context, *[]*PlatformChannel, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *PlatformChannel, ...orm.QueryOption) error
+	Add(context.Context, *PlatformChannel) error
+	Del(context.Context, *PlatformChannel, ...orm.QueryOption) error
+}
+
+// FindPlatform Paginated search
+func (c Core) FindPlatform(ctx context.Context, in *FindPlatformInput) ([]*Platform, int64, error) {
+	items := make([]*Platform, 0)
+	query := orm.NewQuery(1).OrderBy("created_at DESC")
+	if in.Key != "" {
+		query.Where("name LIKE ? OR server_id LIKE ?", "%"+in.Key+"%", "%"+in.Key+"%")
+	}
+	total, err := c.store.Platform().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, total, nil
+}
+
+// GetPlatform Query a single object
+func (c Core) GetPlatform(ctx context.Context, id string) (*Platform, error) {
+	var out Platform
+	if err := c.store.Platform().Get(ctx, &out, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// AddPlatform Insert into database
+func (c Core) AddPlatform(ctx context.Context, in *AddPlatformInput) (*Platform, error) {
+	var out Platform
+	if err := copier.Copy(&out, in); err != nil {
+		slog.ErrorContext(ctx, "Copy", "err", err)
+	}
+	if err := out.Check(); err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	out.ID = c.uniqueID.UniqueID(bz.IDPrefixPlatform)
+	if err := c.store.Platform().Add(ctx, &out); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// EditPlatform Update object information
+func (c Core) EditPlatform(ctx context.Context, in *EditPlatformInput, id string) (*Platform, error) {
+	// 先校验再修改，避免写入非法参数
+	var check Platform
+	if err := copier.Copy(&check, in); err != nil {
+		slog.ErrorContext(ctx, "Copy", "err", err)
+	}
+	if err := check.Check(); err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+
+	var out Platform
+	if err := c.store.Platform().Edit(ctx, &out, func(p *Platform) {
+		// 仅修改配置项，保留注册状态
+		p.Name = check.Name
+		p.ServerID = check.ServerID
+		p.Domain = check.Domain
+		p.IP = check.IP
+		p.Port = check.Port
+		p.Transport = check.Transport
+		p.Username = check.Username
+		p.Password = check.Password
+		p.Expires = check.Expires
+		p.KeepaliveInterval = check.KeepaliveInterval
+		p.CatalogID = check.CatalogID
+		p.CatalogName = check.CatalogName
+		p.Enabled = check.Enabled
+	}, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Edit err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Edit err[%s] id[%s]`, err.Error(), id)
+	}
+	return &out, nil
+}
+
+// DelPlatform Delete object，同时删除共享通道
+func (c Core) DelPlatform(ctx context.Context, id string) (*Platform, error) {
+	var out Platform
+	if err := c.store.Platform().Del(ctx, &out, orm.Where("id=?", id)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	if err := c.store.PlatformChannel().Del(ctx, new(PlatformChannel), orm.Where("platform_id=?", id)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// FindPlatformChannel Paginated search
+func (c Core) FindPlatformChannel(ctx context.Context, in *FindPlatformChannelInput) ([]*PlatformChannel, int64, error) {
+	items := make([]*PlatformChannel, 0)
+	query := orm.NewQuery(1).Where("platform_id=?", in.PlatformID).OrderBy("created_at ASC")
+	total, err := c.store.PlatformChannel().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, total, nil
+}
+
+// AddPlatformChannel 共享通道给上级平台
+func (c Core) AddPlatformChannel(ctx context.Context, platformID string, in *AddPlatformChannelInput) (*PlatformChannel, error) {
+	out := PlatformChannel{
+		PlatformID: platformID,
+		ChannelID:  in.ChannelID,
+		Source:     PlatformSource(in.ChannelID),
+		GBID:       in.GBID,
+		Name:       in.Name,
+		ParentID:   in.ParentID,
+	}
+	switch out.Source {
+	case "":
+		return nil, reason.ErrBadRequest.SetMsg("不支持共享的通道 " + in.ChannelID)
+	case PlatformSourceGB:
+		ch, err := c.GetChannel(ctx, in.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		if out.GBID == "" {
+			out.GBID = ch.ChannelID
+		}
+		if out.Name == "" {
+			out.Name = ch.Name
+		}
+	}
+	if len(out.GBID) != 20 {
+		return nil, reason.ErrBadRequest.SetMsg("上报国标编码应为 20 位")
+	}
+	if out.ParentID != "" && len(out.ParentID) != 20 {
+		return nil, reason.ErrBadRequest.SetMsg("虚拟目录编码应为 20 位")
+	}
+
+	out.ID = c.uniqueID.UniqueID(bz.IDPrefixShare)
+	if err := c.store.PlatformChannel().Add(ctx, &out); err != nil {
+		if orm.IsDuplicatedKey(err) {
+			return nil, reason.ErrDB.SetMsg("通道或国标编码已共享，请勿重复添加")
+		}
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// DelPlatformChannel 取消共享
+func (c Core) DelPlatformChannel(ctx context.Context, platformID, id string) (*PlatformChannel, error) {
+	var out PlatformChannel
+	if err := c.store.PlatformChannel().Del(ctx, &out, orm.Where("platform_id=? AND id=?", platformID, id)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
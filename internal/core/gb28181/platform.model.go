@@ -0,0 +1,102 @@
+package gb28181
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// 级联共享通道的来源类型，由通道 ID 前缀区分
+const (
+	PlatformSourceGB   = "gb"   // 国标通道
+	PlatformSourceRTMP = "rtmp" // rtmp 推流
+	PlatformSourceRTSP = "rtsp" // rtsp 拉流代理
+)
+
+// Platform 上级平台，本平台作为下级向其注册并共享通道
+type Platform struct {
+	ID                string   `gorm:"primaryKey" json:"id"`
+	Name              string   `gorm:"column:name;notNull;default:'';comment:名称" json:"name"`                                    // 名称
+	ServerID          string   `gorm:"column:server_id;notNull;default:'';comment:上级国标编码" json:"server_id"`                      // 上级 SIP 服务器编码
+	Domain            string   `gorm:"column:domain;notNull;default:'';comment:上级域" json:"domain"`                               // 上级 SIP 域，作为认证 realm
+	IP                string   `gorm:"column:ip;notNull;default:'';comment:上级地址" json:"ip"`                                      // 上级 SIP 地址
+	Port              int      `gorm:"column:port;notNull;default:5060;comment:上级端口" json:"port"`                                // 上级 SIP 端口
+	Transport         string   `gorm:"column:transport;notNull;default:'udp';comment:信令传输协议" json:"transport"`                   // 信令传输协议 udp/tcp
+	Username          string   `gorm:"column:username;notNull;default:'';comment:认证用户名" json:"username"`                         // 认证用户名，为空时使用本平台编码
+	Password          string   `gorm:"column:password;notNull;default:'';comment:注册密码" json:"password"`                          // 注册密码
+	Expires           int      `gorm:"column:expires;notNull;default:3600;comment:注册有效期" json:"expires"`                         // 注册有效期，秒
+	KeepaliveInterval int      `gorm:"column:keepalive_interval;notNull;default:60;comment:心跳间隔" json:"keepalive_interval"`      // 心跳间隔，秒
+	CatalogID         string   `gorm:"column:catalog_id;notNull;default:'';comment:虚拟目录编码" json:"catalog_id"`                    // 共享通道默认挂载的虚拟目录编码
+	CatalogName       string   `gorm:"column:catalog_name;notNull;default:'';comment:虚拟目录名称" json:"catalog_name"`                // 虚拟目录名称
+	Enabled           bool     `gorm:"column:enabled;notNull;default:false;comment:是否启用" json:"enabled"`                         // 是否启用
+	IsOnline          bool     `gorm:"column:is_online;notNull;default:false;comment:是否注册成功" json:"is_online"`                   // 是否注册成功
+	Error             string   `gorm:"column:error;notNull;default:'';comment:最近错误" json:"error"`                                // 最近一次注册/心跳错误
+	RegisteredAt      orm.Time `gorm:"column:registered_at;notNull;default:CURRENT_TIMESTAMP;comment:注册时间" json:"registered_at"` // 注册时间
+	KeepaliveAt       orm.Time `gorm:"column:keepalive_at;notNull;default:CURRENT_TIMESTAMP;comment:心跳时间" json:"keepalive_at"`   // 心跳时间
+	CreatedAt         orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`       // 创建时间
+	UpdatedAt         orm.Time `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"`       // 更新时间
+}
+
+// TableName database table name
+func (*Platform) TableName() string {
+	return "platforms"
+}
+
+// Check 校验上级平台参数
+func (p *Platform) Check() error {
+	if len(p.ServerID) != 20 {
+		return fmt.Errorf("上级国标编码应为 20 位")
+	}
+	if p.IP == "" || p.Port <= 0 {
+		return fmt.Errorf("请填写上级地址与端口")
+	}
+	p.Transport = strings.ToLower(p.Transport)
+	if p.Transport != "udp" && p.Transport != "tcp" {
+		return fmt.Errorf("不支持的传输协议 %s", p.Transport)
+	}
+	if p.CatalogID != "" && len(p.CatalogID) != 20 {
+		return fmt.Errorf("虚拟目录编码应为 20 位")
+	}
+	if p.Domain == "" {
+		p.Domain = p.ServerID[:10]
+	}
+	if p.Expires <= 0 {
+		p.Expires = 3600
+	}
+	if p.KeepaliveInterval <= 0 {
+		p.KeepaliveInterval = 60
+	}
+	return nil
+}
+
+// PlatformChannel 共享给上级平台的通道，上级以 GBID 访问
+type PlatformChannel struct {
+	ID         string   `gorm:"primaryKey" json:"id"`
+	PlatformID string   `gorm:"column:platform_id;uniqueIndex:idx_platform_channel,priority:1;uniqueIndex:idx_platform_gbid,priority:1;notNull;default:'';comment:上级平台 ID" json:"platform_id"` // 上级平台 ID
+	ChannelID  string   `gorm:"column:channel_id;uniqueIndex:idx_platform_channel,priority:2;notNull;default:'';comment:通道 ID" json:"channel_id"`                                              // 本平台通道 ID，国标通道/rtmp/rtsp
+	Source     string   `gorm:"column:source;notNull;default:'';comment:来源类型" json:"source"`                                                                                                   // 来源类型
+	GBID       string   `gorm:"column:gb_id;uniqueIndex:idx_platform_gbid,priority:2;notNull;default:'';comment:上报国标编码" json:"gb_id"`                                                          // 上报给上级的国标编码
+	Name       string   `gorm:"column:name;notNull;default:'';comment:上报名称" json:"name"`                                                                                                       // 上报给上级的名称
+	ParentID   string   `gorm:"column:parent_id;notNull;default:'';comment:虚拟目录编码" json:"parent_id"`                                                                                           // 所属虚拟目录，为空时使用平台默认目录
+	CreatedAt  orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`                                                                            // 创建时间
+}
+
+// TableName database table name
+func (*PlatformChannel) TableName() string {
+	return "platform_channels"
+}
+
+// PlatformSource 按通道 ID 前缀判断来源类型，不支持的通道返回空
+func PlatformSource(channelID string) string {
+	switch {
+	case strings.HasPrefix(channelID, bz.IDPrefixGBChannel):
+		return PlatformSourceGB
+	case strings.HasPrefix(channelID, bz.IDPrefixRTMP):
+		return PlatformSourceRTMP
+	case strings.HasPrefix(channelID, bz.IDPrefixRTSP):
+		return PlatformSourceRTSP
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/web"
+
+type FindPlatformInput struct {
+	web.PagerFilter
+	Key string `form:"key"` // 名称/上级编码
+}
+
+type AddPlatformInput struct {
+	Name              string `json:"name"`                         // 名称
+	ServerID          string `json:"server_id" binding:"required"` // 上级国标编码
+	Domain            string `json:"domain"`                       // 上级域，为空时取编码前 10 位
+	IP                string `json:"ip" binding:"required"`        // 上级地址
+	Port              int    `json:"port" binding:"required"`      // 上级端口
+	Transport         string `json:"transport"`                    // 信令传输协议 udp/tcp
+	Username          string `json:"username"`                     // 认证用户名
+	Password          string `json:"password"`                     // 注册密码
+	Expires           int    `json:"expires"`                      // 注册有效期，秒
+	KeepaliveInterval int    `json:"keepalive_interval"`           // 心跳间隔，秒
+	CatalogID         string `json:"catalog_id"`                   // 虚拟目录编码
+	CatalogName       string `json:"catalog_name"`                 // 虚拟目录名称
+	Enabled           bool   `json:"enabled"`                      // 是否启用
+}
+
+type EditPlatformInput AddPlatformInput
+
+type FindPlatformChannelInput struct {
+	web.PagerFilter
+	PlatformID string `form:"-"`
+}
+
+// AddPlatformChannelInput 共享通道，GBID 为空时国标通道沿用原编码，其它来源必须指定
+type AddPlatformChannelInput struct {
+	ChannelID string `json:"channel_id" binding:"required"` // 本平台通道 ID
+	GBID      string `json:"gb_id"`                         // 上报国标编码
+	Name      string `json:"name"`                          // 上报名称，为空时使用通道名称
+	ParentID  string `json:"parent_id"`                     // 所属虚拟目录编码
+}
+
+type AddPlatformChannelsInput struct {
+	Items []AddPlatformChannelInput `json:"items" binding:"required"`
+}
@@ -0,0 +1,54 @@
+package gb28181
+
+import (
+	"context"
+	"time"
+
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// PositionStorer Instantiation interface
+type PositionStorer interface {
+	Find(context.Context, *[]*Position, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Position, ...orm.QueryOption) error
+	Add(context.Context, *Position) error
+}
+
+// maxTrackPoints 单次轨迹查询的最大点数
+const maxTrackPoints = 10000
+
+// GetLatestPosition 通道最新位置
+func (c Core) GetLatestPosition(ctx context.Context, deviceID, channelID string) (*Position, error) {
+	var out Position
+	if err := c.store.Position().Get(ctx, &out, orm.Where("device_id=? AND channel_id=?", deviceID, channelID), orm.OrderBy("report_at DESC")); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// FindTrack 查询时间段内的轨迹
+func (c Core) FindTrack(ctx context.Context, deviceID, channelID string, in *FindTrackInput) (*Track, error) {
+	if in.EndAt <= in.StartAt {
+		return nil, reason.ErrBadRequest.SetMsg("结束时间必须大于开始时间")
+	}
+	items := make([]*Position, 0, 64)
+	query := orm.NewQuery(4).OrderBy("report_at ASC").
+		Where("device_id=? AND channel_id=?", deviceID, channelID).
+		Where("report_at>=?", time.Unix(in.StartAt, 0)).
+		Where("report_at<=?", time.Unix(in.EndAt, 0))
+	pager := web.PagerFilter{Page: 1, Size: maxTrackPoints}
+	if _, err := c.store.Position().Find(ctx, &items, pager, query.Encode()...); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+
+	polyline := make([][2]float64, 0, len(items))
+	for _, item := range items {
+		polyline = append(polyline, [2]float64{item.Longitude, item.Latitude})
+	}
+	return &Track{Items: items, Polyline: polyline}, nil
+}
@@ -0,0 +1,22 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/orm"
+
+// Position 移动设备位置上报
+type Position struct {
+	ID        int      `gorm:"primaryKey" json:"id"`
+	DeviceID  string   `gorm:"column:device_id;index;notNull;default:'';comment:设备国标编码" json:"device_id"`                                                 // 设备国标编码
+	ChannelID string   `gorm:"column:channel_id;notNull;default:'';index:idx_positions_channel_id_report_at;comment:国标编码" json:"channel_id"`              // 上报位置的设备/通道国标编码
+	Longitude float64  `gorm:"column:longitude;notNull;default:0;comment:经度" json:"longitude"`                                                            // 经度
+	Latitude  float64  `gorm:"column:latitude;notNull;default:0;comment:纬度" json:"latitude"`                                                              // 纬度
+	Speed     float64  `gorm:"column:speed;notNull;default:0;comment:速度" json:"speed"`                                                                    // 速度，单位 km/h
+	Direction float64  `gorm:"column:direction;notNull;default:0;comment:方向" json:"direction"`                                                            // 方向，正北为 0 度顺时针
+	Altitude  float64  `gorm:"column:altitude;notNull;default:0;comment:海拔" json:"altitude"`                                                              // 海拔，单位 m
+	ReportAt  orm.Time `gorm:"column:report_at;notNull;default:CURRENT_TIMESTAMP;index:idx_positions_channel_id_report_at;comment:定位时间" json:"report_at"` // 定位时间
+	CreatedAt orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`                                        // 创建时间
+}
+
+// TableName database table name
+func (*Position) TableName() string {
+	return "positions"
+}
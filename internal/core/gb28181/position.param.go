@@ -0,0 +1,12 @@
+package gb28181
+
+type FindTrackInput struct {
+	StartAt int64 `form:"start_at" binding:"required"` // 开始时间，unix 秒
+	EndAt   int64 `form:"end_at" binding:"required"`   // 结束时间，unix 秒
+}
+
+// Track 轨迹
+type Track struct {
+	Items    []*Position  `json:"items"`
+	Polyline [][2]float64 `json:"polyline"` // 按时间排序的 [经度, 纬度]
+}
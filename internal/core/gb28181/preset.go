@@ -0,0 +1,79 @@
+package gb28181
+
+import (
+	"context"
+
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// PresetStorer Instantiation interface
+type PresetStorer interface {
+	Find(context.Context, *[]*Preset, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Preset, ...orm.QueryOption) error
+	Add(context.Context, *Preset) error
+	Edit(context.Context, *Preset, func(*Preset), ...orm.QueryOption) error
+	Del(context.Context, *Preset, ...orm.QueryOption) error
+}
+
+// FindPreset 查询通道的全部预置位
+func (c Core) FindPreset(ctx context.Context, cid string) ([]*Preset, error) {
+	items := make([]*Preset, 0, 8)
+	query := orm.NewQuery(1).OrderBy("preset_id ASC").Where("cid=?", cid)
+	if _, err := c.store.Preset().Find(ctx, &items, web.NewPagerFilterMaxSize(), query.Encode()...); err != nil {
+		return nil, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, nil
+}
+
+// GetPreset Query a single object
+func (c Core) GetPreset(ctx context.Context, cid string, presetID int) (*Preset, error) {
+	var out Preset
+	if err := c.store.Preset().Get(ctx, &out, orm.Where("cid=? AND preset_id=?", cid, presetID)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// AddPreset 新增预置位，编号已存在时更新名称
+func (c Core) AddPreset(ctx context.Context, cid string, in *AddPresetInput) (*Preset, error) {
+	var out Preset
+	if err := c.store.Preset().Edit(ctx, &out, func(p *Preset) {
+		p.Name = in.Name
+	}, orm.Where("cid=? AND preset_id=?", cid, in.PresetID)); err == nil {
+		return &out, nil
+	}
+
+	out = Preset{CID: cid, PresetID: in.PresetID, Name: in.Name}
+	if err := c.store.Preset().Add(ctx, &out); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// EditPreset Update object information
+func (c Core) EditPreset(ctx context.Context, in *EditPresetInput, cid string, presetID int) (*Preset, error) {
+	var out Preset
+	if err := c.store.Preset().Edit(ctx, &out, func(p *Preset) {
+		p.Name = in.Name
+	}, orm.Where("cid=? AND preset_id=?", cid, presetID)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Edit err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Edit err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// DelPreset Delete object
+func (c Core) DelPreset(ctx context.Context, cid string, presetID int) (*Preset, error) {
+	var out Preset
+	if err := c.store.Preset().Del(ctx, &out, orm.Where("cid=? AND preset_id=?", cid, presetID)); err != nil {
+		return nil, reason.ErrDB.Withf(`Del err[%s]`, err.Error())
+	}
+	return &out, nil
+}
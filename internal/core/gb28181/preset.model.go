@@ -0,0 +1,18 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/orm"
+
+// Preset 通道预置位
+type Preset struct {
+	ID        int      `gorm:"primaryKey" json:"id"`
+	CID       string   `gorm:"column:cid;notNull;default:'';uniqueIndex:idx_presets_cid_preset_id;comment:通道 ID" json:"cid"`            // 通道 ID
+	PresetID  int      `gorm:"column:preset_id;notNull;default:0;uniqueIndex:idx_presets_cid_preset_id;comment:预置位编号" json:"preset_id"` // 预置位编号 1~255
+	Name      string   `gorm:"column:name;notNull;default:'';comment:预置位名称" json:"name"`                                                // 预置位名称
+	CreatedAt orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"`                      // 创建时间
+	UpdatedAt orm.Time `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"`                      // 更新时间
+}
+
+// TableName database table name
+func (*Preset) TableName() string {
+	return "presets"
+}
@@ -0,0 +1,10 @@
+package gb28181
+
+type EditPresetInput struct {
+	Name string `json:"name"` // 预置位名称
+}
+
+type AddPresetInput struct {
+	PresetID int    `json:"preset_id" binding:"required,min=1,max=255"` // 预置位编号
+	Name     string `json:"name"`                                       // 预置位名称
+}
@@ -105,6 +105,7 @@ func (c *Cache) Change(deviceID string, changeFn func(*gb28181.Device), changeFn
 	dev2.Expires = dev.Expires
 	dev2.Password = dev.Password
 	dev2.Address = dev.Address
+	dev2.SetProfile(gbs.DeviceProfile(&dev))
 	changeFn2(dev2)
 	if !dev2.IsOnline {
 		if err := c.Storer.Channel().BatchEdit(context.TODO(), "is_online", false, orm.Where("did=?", dev.ID)); err != nil {
@@ -56,6 +56,7 @@ func (d *Device) Edit(ctx context.Context, dev *gb28181.Device, changeFn func(*g
 	if !ok {
 		return fmt.Errorf("edit device not found")
 	}
+	dev2.SetProfile(gbs.DeviceProfile(dev))
 	// 密码修改，设备需要重新注册
 	if dev2.Password != dev.Password && dev.Password != "" {
 		slog.InfoContext(ctx, " 修改密码，设备离线")
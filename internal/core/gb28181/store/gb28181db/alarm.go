@@ -0,0 +1,29 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.AlarmStorer = Alarm{}
+
+// Alarm Related business namespaces
+type Alarm DB
+
+// NewAlarm instance object
+func NewAlarm(db *gorm.DB) Alarm {
+	return Alarm{db: db}
+}
+
+// Find implements gb28181.AlarmStorer.
+func (d Alarm) Find(ctx context.Context, bs *[]*gb28181.Alarm, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Add implements gb28181.AlarmStorer.
+func (d Alarm) Add(ctx context.Context, model *gb28181.Alarm) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
@@ -0,0 +1,32 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+)
+
+func TestAlarmAdd(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alarmDB := NewAlarm(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "alarms" (.+) RETURNING (.+)"id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	out := gb28181.Alarm{DeviceID: "jack", ChannelID: "jack", Method: 5, Type: 2}
+	if err := alarmDB.Add(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != 1 {
+		t.Fatalf("expect id 1, got %d", out.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
@@ -0,0 +1,39 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.CatalogGroupStorer = CatalogGroup{}
+
+// CatalogGroup Related business namespaces
+type CatalogGroup DB
+
+// NewCatalogGroup instance object
+func NewCatalogGroup(db *gorm.DB) CatalogGroup {
+	return CatalogGroup{db: db}
+}
+
+// Find implements gb28181.CatalogGroupStorer.
+func (d CatalogGroup) Find(ctx context.Context, bs *[]*gb28181.CatalogGroup, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Add implements gb28181.CatalogGroupStorer.
+func (d CatalogGroup) Add(ctx context.Context, model *gb28181.CatalogGroup) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.CatalogGroupStorer.
+func (d CatalogGroup) Edit(ctx context.Context, model *gb28181.CatalogGroup, changeFn func(*gb28181.CatalogGroup), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
+
+// Del implements gb28181.CatalogGroupStorer.
+func (d CatalogGroup) Del(ctx context.Context, model *gb28181.CatalogGroup, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
@@ -0,0 +1,37 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+func TestCatalogGroupFind(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalogDB := NewCatalogGroup(db)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "catalog_groups" WHERE did=\$1`).
+		WithArgs("gb1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "catalog_groups" WHERE did=\$1`).
+		WithArgs("gb1", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "group_id", "type"}).AddRow("cg1", "34020000002150000001", gb28181.CatalogTypeBusinessGroup))
+	var items []*gb28181.CatalogGroup
+	total, err := catalogDB.Find(context.Background(), &items, web.PagerFilter{Page: 1, Size: 10}, orm.Where("did=?", "gb1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(items) != 1 || items[0].Type != gb28181.CatalogTypeBusinessGroup {
+		t.Fatalf("unexpected result total[%d] items[%v]", total, items)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
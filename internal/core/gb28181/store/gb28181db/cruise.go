@@ -0,0 +1,44 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.CruiseStorer = Cruise{}
+
+// Cruise Related business namespaces
+type Cruise DB
+
+// NewCruise instance object
+func NewCruise(db *gorm.DB) Cruise {
+	return Cruise{db: db}
+}
+
+// Find implements gb28181.CruiseStorer.
+func (d Cruise) Find(ctx context.Context, bs *[]*gb28181.Cruise, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.CruiseStorer.
+func (d Cruise) Get(ctx context.Context, model *gb28181.Cruise, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.CruiseStorer.
+func (d Cruise) Add(ctx context.Context, model *gb28181.Cruise) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.CruiseStorer.
+func (d Cruise) Edit(ctx context.Context, model *gb28181.Cruise, changeFn func(*gb28181.Cruise), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
+
+// Del implements gb28181.CruiseStorer.
+func (d Cruise) Del(ctx context.Context, model *gb28181.Cruise, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
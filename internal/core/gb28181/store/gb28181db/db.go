@@ -28,6 +28,56 @@ func (d DB) Channel() gb28181.ChannelStorer {
 	return Channel(d)
 }
 
+// Preset Get business instance
+func (d DB) Preset() gb28181.PresetStorer {
+	return Preset(d)
+}
+
+// Cruise Get business instance
+func (d DB) Cruise() gb28181.CruiseStorer {
+	return Cruise(d)
+}
+
+// Download Get business instance
+func (d DB) Download() gb28181.DownloadStorer {
+	return Download(d)
+}
+
+// Alarm Get business instance
+func (d DB) Alarm() gb28181.AlarmStorer {
+	return Alarm(d)
+}
+
+// Position Get business instance
+func (d DB) Position() gb28181.PositionStorer {
+	return Position(d)
+}
+
+// Firmware Get business instance
+func (d DB) Firmware() gb28181.FirmwareStorer {
+	return Firmware(d)
+}
+
+// Upgrade Get business instance
+func (d DB) Upgrade() gb28181.UpgradeStorer {
+	return Upgrade(d)
+}
+
+// CatalogGroup Get business instance
+func (d DB) CatalogGroup() gb28181.CatalogGroupStorer {
+	return CatalogGroup(d)
+}
+
+// Platform Get business instance
+func (d DB) Platform() gb28181.PlatformStorer {
+	return Platform(d)
+}
+
+// PlatformChannel Get business instance
+func (d DB) PlatformChannel() gb28181.PlatformChannelStorer {
+	return PlatformChannel(d)
+}
+
 // AutoMigrate sync database
 func (d DB) AutoMigrate(ok bool) DB {
 	if !ok {
@@ -36,6 +86,16 @@ func (d DB) AutoMigrate(ok bool) DB {
 	if err := d.db.AutoMigrate(
 		new(gb28181.Device),
 		new(gb28181.Channel),
+		new(gb28181.Preset),
+		new(gb28181.Cruise),
+		new(gb28181.Download),
+		new(gb28181.Alarm),
+		new(gb28181.Position),
+		new(gb28181.Firmware),
+		new(gb28181.Upgrade),
+		new(gb28181.CatalogGroup),
+		new(gb28181.Platform),
+		new(gb28181.PlatformChannel),
 	); err != nil {
 		panic(err)
 	}
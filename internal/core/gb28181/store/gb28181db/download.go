@@ -0,0 +1,44 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.DownloadStorer = Download{}
+
+// Download Related business namespaces
+type Download DB
+
+// NewDownload instance object
+func NewDownload(db *gorm.DB) Download {
+	return Download{db: db}
+}
+
+// Find implements gb28181.DownloadStorer.
+func (d Download) Find(ctx context.Context, bs *[]*gb28181.Download, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.DownloadStorer.
+func (d Download) Get(ctx context.Context, model *gb28181.Download, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.DownloadStorer.
+func (d Download) Add(ctx context.Context, model *gb28181.Download) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.DownloadStorer.
+func (d Download) Edit(ctx context.Context, model *gb28181.Download, changeFn func(*gb28181.Download), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
+
+// Del implements gb28181.DownloadStorer.
+func (d Download) Del(ctx context.Context, model *gb28181.Download, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
@@ -0,0 +1,29 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+func TestDownloadGet(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloadDB := NewDownload(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "downloads" WHERE id=\$1 (.+) LIMIT \$2`).
+		WithArgs("dl1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cid", "status"}).AddRow("dl1", "ch1", gb28181.DownloadStatusPending))
+	var out gb28181.Download
+	if err := downloadDB.Get(context.Background(), &out, orm.Where("id=?", "dl1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
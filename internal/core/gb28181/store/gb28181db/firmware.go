@@ -0,0 +1,39 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.FirmwareStorer = Firmware{}
+
+// Firmware Related business namespaces
+type Firmware DB
+
+// NewFirmware instance object
+func NewFirmware(db *gorm.DB) Firmware {
+	return Firmware{db: db}
+}
+
+// Find implements gb28181.FirmwareStorer.
+func (d Firmware) Find(ctx context.Context, bs *[]*gb28181.Firmware, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.FirmwareStorer.
+func (d Firmware) Get(ctx context.Context, model *gb28181.Firmware, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.FirmwareStorer.
+func (d Firmware) Add(ctx context.Context, model *gb28181.Firmware) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Del implements gb28181.FirmwareStorer.
+func (d Firmware) Del(ctx context.Context, model *gb28181.Firmware, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
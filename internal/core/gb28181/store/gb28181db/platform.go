@@ -0,0 +1,75 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var (
+	_ gb28181.PlatformStorer        = Platform{}
+	_ gb28181.PlatformChannelStorer = PlatformChannel{}
+)
+
+// Platform Related business namespaces
+type Platform DB
+
+// NewPlatform instance object
+func NewPlatform(db *gorm.DB) Platform {
+	return Platform{db: db}
+}
+
+// Find implements gb28181.PlatformStorer.
+func (d Platform) Find(ctx context.Context, bs *[]*gb28181.Platform, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.PlatformStorer.
+func (d Platform) Get(ctx context.Context, model *gb28181.Platform, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.PlatformStorer.
+func (d Platform) Add(ctx context.Context, model *gb28181.Platform) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.PlatformStorer.
+func (d Platform) Edit(ctx context.Context, model *gb28181.Platform, changeFn func(*gb28181.Platform), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
+
+// Del implements gb28181.PlatformStorer.
+func (d Platform) Del(ctx context.Context, model *gb28181.Platform, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
+
+// PlatformChannel Related business namespaces
+type PlatformChannel DB
+
+// NewPlatformChannel instance object
+func NewPlatformChannel(db *gorm.DB) PlatformChannel {
+	return PlatformChannel{db: db}
+}
+
+// Find implements gb28181.PlatformChannelStorer.
+func (d PlatformChannel) Find(ctx context.Context, bs *[]*gb28181.PlatformChannel, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.PlatformChannelStorer.
+func (d PlatformChannel) Get(ctx context.Context, model *gb28181.PlatformChannel, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.PlatformChannelStorer.
+func (d PlatformChannel) Add(ctx context.Context, model *gb28181.PlatformChannel) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Del implements gb28181.PlatformChannelStorer.
+func (d PlatformChannel) Del(ctx context.Context, model *gb28181.PlatformChannel, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
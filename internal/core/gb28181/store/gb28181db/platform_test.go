@@ -0,0 +1,33 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+func TestPlatformChannelGet(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	channelDB := NewPlatformChannel(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "platform_channels" WHERE platform_id=\$1 AND gb_id=\$2 (.+) LIMIT \$3`).
+		WithArgs("pf1", "34020000001320000001", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "platform_id", "channel_id", "source", "gb_id"}).
+			AddRow("ps1", "pf1", "gb1", gb28181.PlatformSourceGB, "34020000001320000001"))
+	var out gb28181.PlatformChannel
+	if err := channelDB.Get(context.Background(), &out, orm.Where("platform_id=? AND gb_id=?", "pf1", "34020000001320000001")); err != nil {
+		t.Fatal(err)
+	}
+	if out.ChannelID != "gb1" || out.Source != gb28181.PlatformSourceGB {
+		t.Fatalf("unexpected share %+v", out)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
@@ -0,0 +1,34 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.PositionStorer = Position{}
+
+// Position Related business namespaces
+type Position DB
+
+// NewPosition instance object
+func NewPosition(db *gorm.DB) Position {
+	return Position{db: db}
+}
+
+// Find implements gb28181.PositionStorer.
+func (d Position) Find(ctx context.Context, bs *[]*gb28181.Position, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.PositionStorer.
+func (d Position) Get(ctx context.Context, model *gb28181.Position, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.PositionStorer.
+func (d Position) Add(ctx context.Context, model *gb28181.Position) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
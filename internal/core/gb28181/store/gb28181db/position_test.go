@@ -0,0 +1,40 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+func TestPositionAdd(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	positionDB := NewPosition(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "positions" (.+) RETURNING (.+)"id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	out := gb28181.Position{
+		DeviceID:  "jack",
+		ChannelID: "jack",
+		Longitude: 116.39,
+		Latitude:  39.9,
+		ReportAt:  orm.Time{Time: time.Now()},
+	}
+	if err := positionDB.Add(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != 1 {
+		t.Fatalf("expect id 1, got %d", out.ID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
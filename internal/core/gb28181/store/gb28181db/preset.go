@@ -0,0 +1,44 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.PresetStorer = Preset{}
+
+// Preset Related business namespaces
+type Preset DB
+
+// NewPreset instance object
+func NewPreset(db *gorm.DB) Preset {
+	return Preset{db: db}
+}
+
+// Find implements gb28181.PresetStorer.
+func (d Preset) Find(ctx context.Context, bs *[]*gb28181.Preset, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.PresetStorer.
+func (d Preset) Get(ctx context.Context, model *gb28181.Preset, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.PresetStorer.
+func (d Preset) Add(ctx context.Context, model *gb28181.Preset) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.PresetStorer.
+func (d Preset) Edit(ctx context.Context, model *gb28181.Preset, changeFn func(*gb28181.Preset), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
+
+// Del implements gb28181.PresetStorer.
+func (d Preset) Del(ctx context.Context, model *gb28181.Preset, opts ...orm.QueryOption) error {
+	return orm.DeleteWithContext(ctx, d.db, model, opts...)
+}
@@ -0,0 +1,29 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+func TestPresetGet(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	presetDB := NewPreset(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "presets" WHERE cid=\$1 AND preset_id=\$2 (.+) LIMIT \$3`).
+		WithArgs("jack", 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cid", "preset_id"}).AddRow(1, "jack", 1))
+	var out gb28181.Preset
+	if err := presetDB.Get(context.Background(), &out, orm.Where("cid=? AND preset_id=?", "jack", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
@@ -0,0 +1,39 @@
+package gb28181db
+
+import (
+	"context"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+	"gorm.io/gorm"
+)
+
+var _ gb28181.UpgradeStorer = Upgrade{}
+
+// Upgrade Related business namespaces
+type Upgrade DB
+
+// NewUpgrade instance object
+func NewUpgrade(db *gorm.DB) Upgrade {
+	return Upgrade{db: db}
+}
+
+// Find implements gb28181.UpgradeStorer.
+func (d Upgrade) Find(ctx context.Context, bs *[]*gb28181.Upgrade, page orm.Pager, opts ...orm.QueryOption) (int64, error) {
+	return orm.FindWithContext(ctx, d.db, bs, page, opts...)
+}
+
+// Get implements gb28181.UpgradeStorer.
+func (d Upgrade) Get(ctx context.Context, model *gb28181.Upgrade, opts ...orm.QueryOption) error {
+	return orm.FirstWithContext(ctx, d.db, model, opts...)
+}
+
+// Add implements gb28181.UpgradeStorer.
+func (d Upgrade) Add(ctx context.Context, model *gb28181.Upgrade) error {
+	return d.db.WithContext(ctx).Create(model).Error
+}
+
+// Edit implements gb28181.UpgradeStorer.
+func (d Upgrade) Edit(ctx context.Context, model *gb28181.Upgrade, changeFn func(*gb28181.Upgrade), opts ...orm.QueryOption) error {
+	return orm.UpdateWithContext(ctx, d.db, model, changeFn, opts...)
+}
@@ -0,0 +1,32 @@
+package gb28181db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+func TestUpgradeGet(t *testing.T) {
+	db, mock, err := generateMockDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	upgradeDB := NewUpgrade(db)
+
+	mock.ExpectQuery(`SELECT \* FROM "upgrades" WHERE id=\$1 (.+) LIMIT \$2`).
+		WithArgs("up1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "did", "status"}).AddRow("up1", "gb1", gb28181.UpgradeStatusPending))
+	var out gb28181.Upgrade
+	if err := upgradeDB.Get(context.Background(), &out, orm.Where("id=?", "up1")); err != nil {
+		t.Fatal(err)
+	}
+	if out.Status != gb28181.UpgradeStatusPending {
+		t.Fatalf("expect status pending, got %s", out.Status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal("ExpectationsWereMet err:", err)
+	}
+}
@@ -0,0 +1,116 @@
+package gb28181
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// UpgradeStorer Instantiation interface
+type UpgradeStorer interface {
+	Find(context.Context, *[]*Upgrade, orm.Pager, ...orm.QueryOption) (int64, error)
+	Get(context.Context, *Upgrade, ...orm.QueryOption) error
+	Add(context.Context, *Upgrade) error
+	Edit(context.Context, *Upgrade, func(*Upgrade), ...orm.QueryOption) error
+}
+
+// FindUpgrade Paginated search
+func (c Core) FindUpgrade(ctx context.Context, in *FindUpgradeInput) ([]*Upgrade, int64, error) {
+	items := make([]*Upgrade, 0)
+	query := orm.NewQuery(2).OrderBy("created_at DESC")
+	if in.DID != "" {
+		query.Where("did=?", in.DID)
+	}
+	if in.Status != "" {
+		query.Where("status=?", in.Status)
+	}
+	total, err := c.store.Upgrade().Find(ctx, &items, in, query.Encode()...)
+	if err != nil {
+		return nil, 0, reason.ErrDB.Withf(`Find err[%s]`, err.Error())
+	}
+	return items, total, nil
+}
+
+// GetUpgrade Query a single object
+func (c Core) GetUpgrade(ctx context.Context, id string) (*Upgrade, error) {
+	var out Upgrade
+	if err := c.store.Upgrade().Get(ctx, &out, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Get err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// runningUpgrade 未结束的升级任务状态
+var runningUpgrade = []string{UpgradeStatusPending, UpgradeStatusDownloading}
+
+// AddUpgrade 创建升级任务，同一设备同时只允许一个未结束的任务，超时的任务标记为失败后允许重新升级
+func (c Core) AddUpgrade(ctx context.Context, dev *Device, fw *Firmware) (*Upgrade, error) {
+	var running Upgrade
+	err := c.store.Upgrade().Get(ctx, &running, orm.Where("did=? AND status IN ?", dev.ID, runningUpgrade))
+	if err == nil {
+		if !running.IsExpired(time.Now()) {
+			return nil, reason.ErrUsedLogic.SetMsg("设备存在未结束的升级任务")
+		}
+		if _, err := c.EditUpgrade(ctx, running.ID, func(u *Upgrade) { u.Finish(false, "升级超时") }); err != nil {
+			return nil, err
+		}
+	} else if !orm.IsErrRecordNotFound(err) {
+		return nil, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+	}
+
+	out := Upgrade{
+		ID:         c.uniqueID.UniqueID(bz.IDPrefixUpgrade),
+		DID:        dev.ID,
+		DeviceID:   dev.DeviceID,
+		FirmwareID: fw.ID,
+		Firmware:   fw.Version,
+		Status:     UpgradeStatusPending,
+	}
+	if err := c.store.Upgrade().Add(ctx, &out); err != nil {
+		return nil, reason.ErrDB.Withf(`Add err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// EditUpgrade 修改升级任务
+func (c Core) EditUpgrade(ctx context.Context, id string, changeFn func(*Upgrade)) (*Upgrade, error) {
+	var out Upgrade
+	if err := c.store.Upgrade().Edit(ctx, &out, changeFn, orm.Where("id=?", id)); err != nil {
+		if orm.IsErrRecordNotFound(err) {
+			return nil, reason.ErrNotFound.Withf(`Edit err[%s]`, err.Error())
+		}
+		return nil, reason.ErrDB.Withf(`Edit err[%s]`, err.Error())
+	}
+	return &out, nil
+}
+
+// CancelUpgrade 取消未结束的升级任务
+func (c Core) CancelUpgrade(ctx context.Context, id string) (*Upgrade, error) {
+	job, err := c.GetUpgrade(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsDone() {
+		return nil, reason.ErrUsedLogic.SetMsg("升级任务已结束")
+	}
+	return c.EditUpgrade(ctx, id, func(u *Upgrade) { u.Finish(false, "已取消") })
+}
+
+// firmwareInUse 固件是否被未超时的升级任务引用
+func (c Core) firmwareInUse(ctx context.Context, firmwareID string) (bool, error) {
+	var running Upgrade
+	err := c.store.Upgrade().Get(ctx, &running, orm.Where("firmware_id=? AND status IN ? AND updated_at>?", firmwareID, runningUpgrade, time.Now().Add(-UpgradeTimeout)))
+	if err == nil {
+		return true, nil
+	}
+	if orm.IsErrRecordNotFound(err) {
+		return false, nil
+	}
+	return false, reason.ErrDB.Withf(`Get err[%s]`, err.Error())
+}
@@ -0,0 +1,73 @@
+package gb28181
+
+import (
+	"time"
+
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// 升级任务状态
+const (
+	UpgradeStatusPending     = "pending"     // 已下发升级命令，等待设备下载
+	UpgradeStatusDownloading = "downloading" // 设备正在下载固件
+	UpgradeStatusSuccess     = "success"     // 升级成功
+	UpgradeStatusFailed      = "failed"      // 升级失败
+)
+
+// UpgradeTimeout 未结束的升级任务超过此时长未更新视为失败，避免设备不上报结果时阻塞后续升级
+const UpgradeTimeout = 30 * time.Minute
+
+// Upgrade 设备升级任务，ID 同时作为升级命令的 SessionID
+type Upgrade struct {
+	ID         string   `gorm:"primaryKey" json:"id"`
+	DID        string   `gorm:"column:did;index;notNull;default:'';comment:设备 ID" json:"did"`                       // 设备 ID
+	DeviceID   string   `gorm:"column:device_id;notNull;default:'';comment:国标编码" json:"device_id"`                  // 设备国标编码
+	FirmwareID string   `gorm:"column:firmware_id;notNull;default:'';comment:固件 ID" json:"firmware_id"`             // 固件 ID
+	Firmware   string   `gorm:"column:firmware;notNull;default:'';comment:目标固件版本" json:"firmware"`                  // 目标固件版本
+	Status     string   `gorm:"column:status;index;notNull;default:'';comment:任务状态" json:"status"`                  // 任务状态
+	Error      string   `gorm:"column:error;notNull;default:'';comment:失败原因" json:"error"`                          // 失败原因
+	CreatedAt  orm.Time `gorm:"column:created_at;notNull;default:CURRENT_TIMESTAMP;comment:创建时间" json:"created_at"` // 创建时间
+	UpdatedAt  orm.Time `gorm:"column:updated_at;notNull;default:CURRENT_TIMESTAMP;comment:更新时间" json:"updated_at"` // 更新时间
+}
+
+// TableName database table name
+func (*Upgrade) TableName() string {
+	return "upgrades"
+}
+
+// IsDone 任务是否已结束
+func (u *Upgrade) IsDone() bool {
+	return u.Status == UpgradeStatusSuccess || u.Status == UpgradeStatusFailed
+}
+
+// IsExpired 未结束的任务超过 UpgradeTimeout 未更新
+func (u *Upgrade) IsExpired(now time.Time) bool {
+	return !u.IsDone() && now.Sub(u.UpdatedAt.Time) > UpgradeTimeout
+}
+
+// Download 设备开始下载固件，仅等待中的任务进入下载中
+func (u *Upgrade) Download() bool {
+	if u.Status != UpgradeStatusPending {
+		return false
+	}
+	u.Status = UpgradeStatusDownloading
+	return true
+}
+
+// Finish 结束任务，已结束的任务不再变更，失败原因为空时使用默认描述
+func (u *Upgrade) Finish(ok bool, reason string) bool {
+	if u.IsDone() {
+		return false
+	}
+	if ok {
+		u.Status = UpgradeStatusSuccess
+		u.Error = ""
+		return true
+	}
+	u.Status = UpgradeStatusFailed
+	u.Error = reason
+	if u.Error == "" {
+		u.Error = "设备升级失败"
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package gb28181
+
+import "github.com/ixugo/goddd/pkg/web"
+
+type FindUpgradeInput struct {
+	web.PagerFilter
+	DID    string `form:"did"`    // 设备 ID
+	Status string `form:"status"` // 任务状态
+}
+
+type AddUpgradeInput struct {
+	FirmwareID string `json:"firmware_id" binding:"required"` // 固件 ID
+}
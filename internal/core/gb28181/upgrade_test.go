@@ -0,0 +1,155 @@
+package gb28181
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ixugo/goddd/domain/uniqueid"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"gorm.io/gorm"
+)
+
+func TestUpgradeTransitions(t *testing.T) {
+	cases := []struct {
+		name   string
+		status string
+		fn     func(*Upgrade) bool
+		expect string
+		change bool
+	}{
+		{"pending download", UpgradeStatusPending, (*Upgrade).Download, UpgradeStatusDownloading, true},
+		{"downloading download", UpgradeStatusDownloading, (*Upgrade).Download, UpgradeStatusDownloading, false},
+		{"failed download", UpgradeStatusFailed, (*Upgrade).Download, UpgradeStatusFailed, false},
+		{"downloading success", UpgradeStatusDownloading, func(u *Upgrade) bool { return u.Finish(true, "") }, UpgradeStatusSuccess, true},
+		{"pending failed", UpgradeStatusPending, func(u *Upgrade) bool { return u.Finish(false, "") }, UpgradeStatusFailed, true},
+		{"success failed", UpgradeStatusSuccess, func(u *Upgrade) bool { return u.Finish(false, "") }, UpgradeStatusSuccess, false},
+		{"failed success", UpgradeStatusFailed, func(u *Upgrade) bool { return u.Finish(true, "") }, UpgradeStatusFailed, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := Upgrade{Status: tc.status}
+			if got := tc.fn(&u); got != tc.change {
+				t.Fatalf("expect change %v, got %v", tc.change, got)
+			}
+			if u.Status != tc.expect {
+				t.Fatalf("expect status %s, got %s", tc.expect, u.Status)
+			}
+		})
+	}
+
+	u := Upgrade{Status: UpgradeStatusDownloading}
+	u.Finish(false, "")
+	if u.Error == "" {
+		t.Fatal("expect default error reason")
+	}
+}
+
+func TestUpgradeIsExpired(t *testing.T) {
+	now := time.Now()
+	old := orm.Time{Time: now.Add(-UpgradeTimeout - time.Minute)}
+	cases := []struct {
+		status  string
+		updated orm.Time
+		expect  bool
+	}{
+		{UpgradeStatusPending, orm.Time{Time: now}, false},
+		{UpgradeStatusPending, old, true},
+		{UpgradeStatusDownloading, old, true},
+		{UpgradeStatusSuccess, old, false},
+		{UpgradeStatusFailed, old, false},
+	}
+	for _, tc := range cases {
+		u := Upgrade{Status: tc.status, UpdatedAt: tc.updated}
+		if got := u.IsExpired(now); got != tc.expect {
+			t.Fatalf("status %s expect %v, got %v", tc.status, tc.expect, got)
+		}
+	}
+}
+
+// upgradeStore 仅保存一个设备的升级任务，查询条件由测试预设
+type upgradeStore struct {
+	UpgradeStorer
+	jobs map[string]*Upgrade
+}
+
+func (s *upgradeStore) running() *Upgrade {
+	for _, u := range s.jobs {
+		if !u.IsDone() {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *upgradeStore) Get(_ context.Context, out *Upgrade, _ ...orm.QueryOption) error {
+	u := s.running()
+	if u == nil {
+		return gorm.ErrRecordNotFound
+	}
+	*out = *u
+	return nil
+}
+
+func (s *upgradeStore) Add(_ context.Context, u *Upgrade) error {
+	u.UpdatedAt = orm.Time{Time: time.Now()}
+	v := *u
+	s.jobs[u.ID] = &v
+	return nil
+}
+
+func (s *upgradeStore) Edit(_ context.Context, out *Upgrade, fn func(*Upgrade), _ ...orm.QueryOption) error {
+	u := s.running()
+	if u == nil {
+		return gorm.ErrRecordNotFound
+	}
+	fn(u)
+	*out = *u
+	return nil
+}
+
+type upgradeStorer struct {
+	Storer
+	up *upgradeStore
+}
+
+func (s upgradeStorer) Upgrade() UpgradeStorer { return s.up }
+
+type uniqueIDStore struct{ uniqueid.UniqueIDStorer }
+
+func (uniqueIDStore) Add(context.Context, *uniqueid.UniqueID) error { return nil }
+
+type uniqueIDStorer struct{}
+
+func (uniqueIDStorer) UniqueID() uniqueid.UniqueIDStorer { return uniqueIDStore{} }
+
+func TestAddUpgradeRefuseRunning(t *testing.T) {
+	up := upgradeStore{jobs: make(map[string]*Upgrade)}
+	core := NewCore(upgradeStorer{up: &up}, uniqueid.NewCore(uniqueIDStorer{}, 6))
+	ctx := context.Background()
+	dev := Device{ID: "gb1", DeviceID: "34020000001320000001"}
+	fw := Firmware{ID: "fw1", Version: "v2"}
+
+	first, err := core.AddUpgrade(ctx, &dev, &fw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.AddUpgrade(ctx, &dev, &fw); !errors.Is(err, reason.ErrUsedLogic) {
+		t.Fatalf("expect ErrUsedLogic, got %v", err)
+	}
+
+	// 设备长时间未上报结果，旧任务超时后允许重新升级
+	up.jobs[first.ID].UpdatedAt = orm.Time{Time: time.Now().Add(-UpgradeTimeout - time.Minute)}
+	second, err := core.AddUpgrade(ctx, &dev, &fw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID {
+		t.Fatal("expect new upgrade job")
+	}
+	if old := up.jobs[first.ID]; old.Status != UpgradeStatusFailed {
+		t.Fatalf("expect expired job failed, got %s", old.Status)
+	}
+}
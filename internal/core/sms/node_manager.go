@@ -198,7 +198,9 @@ func (n *NodeManager) connection(server *MediaServer, serverPort int) error {
 		RtcExternIP:          zlm.NewString(server.IP),
 		GeneralMediaServerID: zlm.NewString(server.ID),
 		HookEnable:           zlm.NewString("1"),
-		HookOnFlowReport:     zlm.NewString(""),
+		HookOnFlowReport:     zlm.NewString(fmt.Sprintf("%s/on_flow_report", hookPrefix)),
+		// 播放器断开均上报流量，用于观看者计数
+		GeneralFlowThreshold: zlm.NewString("0"),
 		HookOnPlay:           zlm.NewString(fmt.Sprintf("%s/on_play", hookPrefix)),
 		// HookOnHTTPAccess:     zlm.NewString(""),
 		HookOnPublish:                  zlm.NewString(fmt.Sprintf("%s/on_publish", hookPrefix)),
@@ -214,9 +216,9 @@ func (n *NodeManager) connection(server *MediaServer, serverPort int) error {
 		// HookOnStreamNotFound: ,
 		HookOnServerKeepalive: zlm.NewString(fmt.Sprintf("%s/on_server_keepalive", hookPrefix)),
 		// HookOnSendRtpStopped: ,
-		// HookOnRtpServerTimeout: ,
-		// HookOnRecordMp4: ,
-		HookTimeoutSec: zlm.NewString("20"),
+		HookOnRtpServerTimeout: zlm.NewString(fmt.Sprintf("%s/on_rtp_server_timeout", hookPrefix)),
+		HookOnRecordMp4:        zlm.NewString(fmt.Sprintf("%s/on_record_mp4", hookPrefix)),
+		HookTimeoutSec:         zlm.NewString("20"),
 		// TODO: 回调时间间隔有问题
 		HookAliveInterval: zlm.NewString(fmt.Sprint(server.HookAliveInterval)),
 		// 推流断开后可以在超时时间内重新连接上继续推流，这样播放器会接着播放。
@@ -268,9 +270,34 @@ func (n *NodeManager) OpenRTPServer(server *MediaServer, in zlm.OpenRTPServerReq
 	return e.OpenRTPServer(in)
 }
 
+// ConnectRTPServer tcp 主动模式下连接设备收流
+func (n *NodeManager) ConnectRTPServer(server *MediaServer, in zlm.ConnectRTPServerRequest) error {
+	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
+	e := n.zlm.SetConfig(zlm.Config{
+		URL:    addr,
+		Secret: server.Secret,
+	})
+	return e.ConnectRTPServer(in)
+}
+
+// GetMediaList 获取流列表
+func (n *NodeManager) GetMediaList(server *MediaServer, in zlm.GetMediaListRequest) ([]zlm.MediaItem, error) {
+	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
+	e := n.zlm.SetConfig(zlm.Config{
+		URL:    addr,
+		Secret: server.Secret,
+	})
+	return e.GetMediaList(in)
+}
+
 // CloseRTPServer 关闭RTP服务器
-func (n *NodeManager) CloseRTPServer(in zlm.CloseRTPServerRequest) (*zlm.CloseRTPServerResponse, error) {
-	return n.zlm.CloseRTPServer(in)
+func (n *NodeManager) CloseRTPServer(server *MediaServer, in zlm.CloseRTPServerRequest) (*zlm.CloseRTPServerResponse, error) {
+	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
+	e := n.zlm.SetConfig(zlm.Config{
+		URL:    addr,
+		Secret: server.Secret,
+	})
+	return e.CloseRTPServer(in)
 }
 
 // AddStreamProxy 添加流代理
@@ -283,6 +310,26 @@ func (n *NodeManager) AddStreamProxy(server *MediaServer, in zlm.AddStreamProxyR
 	return e.AddStreamProxy(in)
 }
 
+// StartSendRTP 向设备推送 rtp 流
+func (n *NodeManager) StartSendRTP(server *MediaServer, in zlm.StartSendRTPRequest) (*zlm.StartSendRTPResponse, error) {
+	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
+	e := n.zlm.SetConfig(zlm.Config{
+		URL:    addr,
+		Secret: server.Secret,
+	})
+	return e.StartSendRTP(in)
+}
+
+// StopSendRTP 停止向设备推送 rtp 流
+func (n *NodeManager) StopSendRTP(server *MediaServer, in zlm.StopSendRTPRequest) error {
+	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
+	e := n.zlm.SetConfig(zlm.Config{
+		URL:    addr,
+		Secret: server.Secret,
+	})
+	return e.StopSendRTP(in)
+}
+
 func (n *NodeManager) GetSnapshot(server *MediaServer, in zlm.GetSnapRequest) ([]byte, error) {
 	addr := fmt.Sprintf("http://%s:%d", server.IP, server.Ports.HTTP)
 	e := n.zlm.SetConfig(zlm.Config{
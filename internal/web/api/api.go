@@ -28,6 +28,9 @@ var startRuntime = time.Now()
 
 func setupRouter(r *gin.Engine, uc *Usecase) {
 	uc.GB28181API.uc = uc
+	if uc.SipServer != nil {
+		uc.SipServer.SetCascadeSource(uc.GB28181API)
+	}
 	uc.SMSAPI.uc = uc
 	uc.WebHookAPI.uc = uc
 	const staticPrefix = "/web"
@@ -2,8 +2,9 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -15,10 +16,10 @@ import (
 	"github.com/gowvp/gb28181/internal/core/gb28181"
 	"github.com/gowvp/gb28181/internal/core/push"
 	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs"
 	"github.com/gowvp/gb28181/pkg/zlm"
 	"github.com/ixugo/goddd/domain/uniqueid"
 	"github.com/ixugo/goddd/pkg/hook"
-	"github.com/ixugo/goddd/pkg/orm"
 	"github.com/ixugo/goddd/pkg/reason"
 	"github.com/ixugo/goddd/pkg/web"
 )
@@ -59,14 +60,11 @@ func NewGB28181Core(store gb28181.Storer, uni uniqueid.Core) gb28181.Core {
 }
 
 func registerGB28181(g gin.IRouter, api GB28181API, handler ...gin.HandlerFunc) {
-	g.Any("/gb28181/snapshot", func(c *gin.Context) {
-		b, err := io.ReadAll(c.Request.Body)
-		if err != nil {
-			panic(err)
-		}
-		os.WriteFile(orm.GenerateRandomString(10)+".jpg", b, 0o644)
-		c.JSON(200, gin.H{"msg": "ok"})
-	})
+	// 设备上传抓拍图像，通过地址签名鉴权
+	g.POST("/gb28181/snapshot/:channel/:session", api.uploadSnapshot)
+	g.PUT("/gb28181/snapshot/:channel/:session", api.uploadSnapshot)
+	// 设备下载升级固件，通过地址签名鉴权
+	g.GET("/gb28181/firmware/:id", api.downloadFirmware)
 	{
 		group := g.Group("/devices", handler...)
 		group.GET("", web.WrapH(api.findDevice))
@@ -75,7 +73,18 @@ func registerGB28181(g gin.IRouter, api GB28181API, handler ...gin.HandlerFunc)
 		group.POST("", web.WrapH(api.addDevice))
 		group.DELETE("/:id", web.WrapH(api.delDevice))
 
-		group.POST("/:id/catalog", web.WrapH(api.queryCatalog)) // 刷新通道
+		group.POST("/:id/catalog", web.WrapH(api.queryCatalog))        // 刷新通道
+		group.POST("/:id/alarm/reset", web.WrapH(api.resetAlarm))      // 报警复位
+		group.POST("/:id/control", web.WrapH(api.deviceControl))       // 设备控制
+		group.POST("/:id/status", web.WrapH(api.queryDeviceStatus))    // 刷新设备状态
+		group.GET("/:id/config/:type", web.WrapH(api.getDeviceConfig)) // 设备配置查询
+		group.PUT("/:id/config/:type", web.WrapH(api.setDeviceConfig)) // 设备配置
+		group.POST("/:id/upgrade", web.WrapH(api.addUpgrade))          // 设备升级
+		group.GET("/:id/tree", web.WrapH(api.findDeviceTree))          // 设备目录树
+
+		group.POST("/:id/mobile_position/subscribe", web.WrapH(api.subscribeMobilePosition))     // 订阅移动位置
+		group.DELETE("/:id/mobile_position/subscribe", web.WrapH(api.unsubscribeMobilePosition)) // 取消订阅移动位置
+		group.POST("/:id/mobile_position/query", web.WrapH(api.queryMobilePosition))             // 查询移动位置
 
 		group.GET("/channels", web.WrapH(api.FindChannelsForDevice))
 	}
@@ -85,13 +94,83 @@ func registerGB28181(g gin.IRouter, api GB28181API, handler ...gin.HandlerFunc)
 		group.GET("", web.WrapH(api.findChannel))
 		group.PUT("/:id", web.WrapH(api.editChannel))
 		group.POST("/:id/play", web.WrapH(api.play))
-
-		group.POST("/:id/snapshot", web.WrapH(api.refreshSnapshot)) // 图像抓拍
-		group.GET("/:id/snapshot", api.getSnapshot)                 // 获取图像
+		group.GET("/:id/play/status", web.WrapH(api.playStatus))            // 点播状态，含传输模式切换记录
+		group.GET("/:id/records", web.WrapH(api.findRecord))                // 设备录像检索
+		group.POST("/:id/playback", web.WrapH(api.playback))                // 历史回放
+		group.POST("/:id/playback/control", web.WrapH(api.playbackControl)) // 回放控制
+
+		group.POST("/:id/ptz", web.WrapH(api.ptzControl))         // 云台控制
+		group.POST("/:id/broadcast", web.WrapH(api.broadcast))    // 语音广播
+		group.POST("/:id/control", web.WrapH(api.channelControl)) // 设备控制
+
+		group.GET("/:id/presets", web.WrapH(api.findPreset))                  // 预置位列表
+		group.POST("/:id/presets", web.WrapH(api.addPreset))                  // 设置预置位
+		group.POST("/:id/presets/sync", web.WrapH(api.syncPresets))           // 从设备同步预置位
+		group.PUT("/:id/presets/:preset_id", web.WrapH(api.editPreset))       // 修改预置位名称
+		group.DELETE("/:id/presets/:preset_id", web.WrapH(api.delPreset))     // 删除预置位
+		group.POST("/:id/presets/:preset_id/call", web.WrapH(api.callPreset)) // 调用预置位
+
+		group.GET("/:id/cruises", web.WrapH(api.findCruise))                    // 巡航轨迹列表
+		group.POST("/:id/cruises", web.WrapH(api.addCruise))                    // 新增巡航轨迹
+		group.POST("/:id/cruises/stop", web.WrapH(api.stopCruise))              // 停止巡航
+		group.PUT("/:id/cruises/:cruise_id", web.WrapH(api.editCruise))         // 修改巡航轨迹
+		group.DELETE("/:id/cruises/:cruise_id", web.WrapH(api.delCruise))       // 删除巡航轨迹
+		group.POST("/:id/cruises/:cruise_id/start", web.WrapH(api.startCruise)) // 开始巡航
+		group.POST("/:id/scan", web.WrapH(api.scanControl))                     // 自动扫描
+
+		group.GET("/:id/position", web.WrapH(api.getLatestPosition)) // 最新位置
+		group.GET("/:id/track", web.WrapH(api.findTrack))            // 轨迹
+
+		group.POST("/:id/snapshot", web.WrapH(api.refreshSnapshot))                     // 图像抓拍
+		group.GET("/:id/snapshot", api.getSnapshot)                                     // 获取图像
+		group.POST("/:id/snapshot/capture", web.WrapH(api.captureSnapshot))             // 设备图像抓拍
+		group.GET("/:id/snapshot/sessions/:session", web.WrapH(api.getSnapshotSession)) // 抓拍会话图像列表
+		group.GET("/:id/snapshot/sessions/:session/:name", api.getSnapshotImage)        // 获取抓拍图像
 		// group.GET("/:id", web.WrapH(api.getChannel))
 		// group.POST("", web.WrapH(api.addChannel))
 		// group.DELETE("/:id", web.WrapH(api.delChannel))
 	}
+
+	{
+		group := g.Group("/downloads", handler...)
+		group.GET("", web.WrapH(api.findDownload))
+		group.POST("", web.WrapH(api.addDownload))
+		group.GET("/:id", web.WrapH(api.getDownload))
+		group.DELETE("/:id", web.WrapH(api.delDownload))
+		group.POST("/:id/cancel", web.WrapH(api.cancelDownload)) // 取消下载
+		group.GET("/:id/file", api.getDownloadFile)              // 获取录制文件
+	}
+	g.GET("/tree", web.WrapHs(api.findTree, handler...)...)        // 全局目录树
+	g.GET("/profiles", web.WrapHs(api.findProfile, handler...)...) // 厂商适配列表
+	{
+		group := g.Group("/firmwares", handler...)
+		group.GET("", web.WrapH(api.findFirmware))
+		group.POST("", web.WrapH(api.addFirmware))
+		group.DELETE("/:id", web.WrapH(api.delFirmware))
+	}
+	{
+		group := g.Group("/upgrades", handler...)
+		group.GET("", web.WrapH(api.findUpgrade))
+		group.GET("/:id", web.WrapH(api.getUpgrade))
+		group.POST("/:id/cancel", web.WrapH(api.cancelUpgrade)) // 取消升级
+	}
+
+	{
+		group := g.Group("/platforms", handler...)
+		group.GET("", web.WrapH(api.findPlatform))                                   // 上级平台列表
+		group.GET("/:id", web.WrapH(api.getPlatform))                                // 上级平台详情
+		group.POST("", web.WrapH(api.addPlatform))                                   // 添加上级平台
+		group.PUT("/:id", web.WrapH(api.editPlatform))                               // 修改上级平台
+		group.DELETE("/:id", web.WrapH(api.delPlatform))                             // 删除上级平台
+		group.GET("/:id/channels", web.WrapH(api.findPlatformChannel))               // 共享通道列表
+		group.POST("/:id/channels", web.WrapH(api.addPlatformChannels))              // 共享通道
+		group.DELETE("/:id/channels/:channel_id", web.WrapH(api.delPlatformChannel)) // 取消共享
+	}
+
+	{
+		group := g.Group("/alarms", handler...)
+		group.GET("", web.WrapH(api.findAlarm)) // 报警记录
+	}
 }
 
 // >>> device >>>>>>>>>>>>>>>>>>>>
@@ -108,9 +187,18 @@ func (a GB28181API) getDevice(c *gin.Context, _ *struct{}) (any, error) {
 
 func (a GB28181API) editDevice(c *gin.Context, in *gb28181.EditDeviceInput) (any, error) {
 	deviceID := c.Param("id")
+	if _, ok := gbs.GetProfile(in.Profile); in.Profile != "" && !ok {
+		return nil, reason.ErrBadRequest.SetMsg("不支持的厂商适配 " + in.Profile)
+	}
 	return a.gb28181Core.EditDevice(c.Request.Context(), in, deviceID)
 }
 
+// findProfile 内置的厂商适配，设备可通过 profile 指定
+func (a GB28181API) findProfile(_ *gin.Context, _ *struct{}) (any, error) {
+	items := gbs.Profiles()
+	return gin.H{"items": items, "total": len(items)}, nil
+}
+
 func (a GB28181API) addDevice(c *gin.Context, in *gb28181.AddDeviceInput) (any, error) {
 	return a.gb28181Core.AddDevice(c.Request.Context(), in)
 }
@@ -128,6 +216,18 @@ func (a GB28181API) queryCatalog(c *gin.Context, _ *struct{}) (any, error) {
 	return gin.H{"msg": "ok"}, nil
 }
 
+// queryDeviceStatus 刷新设备状态，结果异步更新到设备属性
+func (a GB28181API) queryDeviceStatus(c *gin.Context, _ *struct{}) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.QueryDeviceStatus(dev.DeviceID); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
 func (a GB28181API) FindChannelsForDevice(c *gin.Context, in *gb28181.FindDeviceInput) (any, error) {
 	items, total, err := a.gb28181Core.FindChannelsForDevice(c.Request.Context(), in)
 	return gin.H{"items": items, "total": total}, err
@@ -159,62 +259,161 @@ func (a GB28181API) editChannel(c *gin.Context, in *gb28181.EditChannelInput) (a
 // 	return a.gb28181Core.DelChannel(c.Request.Context(), channelID)
 // }
 
+// playInput 点播参数，通过 query 传递
+type playInput struct {
+	Profile string `form:"profile"` // 码流 main/sub/third，默认主码流
+	gbs.VideoParam
+}
+
 func (a GB28181API) play(c *gin.Context, _ *struct{}) (*playOutput, error) {
 	channelID := c.Param("id")
+	var in playInput
+	if err := c.ShouldBindQuery(&in); err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	profile, err := gbs.ParseStreamProfile(in.Profile)
+	if err != nil {
+		return nil, reason.ErrBadRequest.SetMsg("不支持的码流类型 " + in.Profile)
+	}
+
+	svr, app, appStream, session, err := a.openStream(c.Request.Context(), channelID, profile)
+	if err != nil {
+		return nil, err
+	}
+	// 指定视频参数或 TCP 主动模式时立即点播以返回点播错误，否则由 on_stream_not_found 触发点播
+	if strings.HasPrefix(channelID, bz.IDPrefixGBChannel) && (!in.VideoParam.IsZero() || a.isTCPActive(c.Request.Context(), channelID)) {
+		if err := a.playChannel(c.Request.Context(), channelID, svr, profile, &in.VideoParam); err != nil {
+			return nil, err
+		}
+	}
+	out := newPlayOutput(c, a.uc.Conf.Server.HTTP.Port, svr, app, appStream, session)
+
+	// 取一张快照
+	go func() {
+		body, err := a.uc.SMSAPI.smsCore.GetSnapshot(svr, zlm.GetSnapRequest{
+			URL:        out.Items[0].RTSP,
+			TimeoutSec: 10,
+			ExpireSec:  15,
+		})
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "get snapshot", "err", err)
+		} else {
+			writeCover(a.uc.Conf.ConfigDir, channelID, body)
+		}
+	}()
+	return out, nil
+}
+
+type playStatusOutput struct {
+	Stream   string            `json:"stream"`
+	Attempts []gbs.PlayAttempt `json:"attempts"` // 点播尝试，收流超时后切换传输模式异步重试，需轮询获取
+}
+
+// playStatus 国标通道的点播状态，query 参数 profile 指定码流
+func (a GB28181API) playStatus(c *gin.Context, _ *struct{}) (*playStatusOutput, error) {
+	channelID := c.Param("id")
+	if !strings.HasPrefix(channelID, bz.IDPrefixGBChannel) {
+		return nil, reason.ErrBadRequest.SetMsg("仅支持国标通道")
+	}
+	profile, err := gbs.ParseStreamProfile(c.Query("profile"))
+	if err != nil {
+		return nil, reason.ErrBadRequest.SetMsg("不支持的码流类型 " + c.Query("profile"))
+	}
+	stream := profile.StreamID(channelID)
+	attempts := a.uc.SipServer.PlayAttempts(stream)
+	if attempts == nil {
+		attempts = []gbs.PlayAttempt{}
+	}
+	return &playStatusOutput{Stream: stream, Attempts: attempts}, nil
+}
+
+// playChannel 向设备点播国标通道
+func (a GB28181API) playChannel(ctx context.Context, channelID string, svr *sms.MediaServer, profile gbs.StreamProfile, video *gbs.VideoParam) error {
+	ch, err := a.gb28181Core.GetChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	dev, err := a.gb28181Core.GetDevice(ctx, ch.DID)
+	if err != nil {
+		return err
+	}
+	if err := a.uc.SipServer.Play(&gbs.PlayInput{
+		Channel:       ch,
+		StreamMode:    dev.StreamMode,
+		SMS:           svr,
+		StreamProfile: profile,
+		Video:         video,
+	}); err != nil {
+		return ErrDevice.SetMsg(err.Error())
+	}
+	return nil
+}
 
-	var app, appStream, host, stream, session string
+// isTCPActive 通道所属设备是否使用 TCP 主动模式收流
+func (a GB28181API) isTCPActive(ctx context.Context, channelID string) bool {
+	ch, err := a.gb28181Core.GetChannel(ctx, channelID)
+	if err != nil {
+		return false
+	}
+	dev, err := a.gb28181Core.GetDevice(ctx, ch.DID)
+	return err == nil && dev.StreamMode == 2
+}
+
+// openStream 定位通道所在的流媒体与流，rtsp 拉流代理会在此启动，国标通道按码流区分流 ID
+func (a GB28181API) openStream(ctx context.Context, channelID string, profile gbs.StreamProfile) (*sms.MediaServer, string, string, string, error) {
+	var app, appStream, session string
 	var svr *sms.MediaServer
 
 	// 国标逻辑
 	if strings.HasPrefix(channelID, bz.IDPrefixGBChannel) {
 		// 防止错误的配置，无法收到流
 		if a.uc.Conf.Media.SDPIP == "127.0.0.1" {
-			return nil, reason.ErrUsedLogic.SetMsg("请先配置流媒体 SDP 收流地址")
+			return nil, "", "", "", reason.ErrUsedLogic.SetMsg("请先配置流媒体 SDP 收流地址")
 		}
 		// a.uc.SipServer.
-		ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+		ch, err := a.gb28181Core.GetChannel(ctx, channelID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 
 		app = "rtp"
-		appStream = ch.ID
+		appStream = profile.StreamID(ch.ID)
 
-		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(c.Request.Context(), sms.DefaultMediaServerID)
+		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(ctx, sms.DefaultMediaServerID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 
 	} else if strings.HasPrefix(channelID, bz.IDPrefixRTMP) {
-		pu, err := a.uc.MediaAPI.pushCore.GetStreamPush(c.Request.Context(), channelID)
+		pu, err := a.uc.MediaAPI.pushCore.GetStreamPush(ctx, channelID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 		if pu.Status != push.StatusPushing {
-			return nil, reason.ErrNotFound.SetMsg("未推流")
+			return nil, "", "", "", reason.ErrNotFound.SetMsg("未推流")
 		}
 		app = pu.App
 		appStream = pu.Stream
 
-		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(c.Request.Context(), pu.MediaServerID)
+		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(ctx, pu.MediaServerID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 
 		if !pu.IsAuthDisabled && pu.Session != "" {
 			session = "session=" + pu.Session
 		}
 	} else if strings.HasPrefix(channelID, bz.IDPrefixRTSP) {
-		proxy, err := a.uc.ProxyAPI.proxyCore.GetStreamProxy(c.Request.Context(), channelID)
+		proxy, err := a.uc.ProxyAPI.proxyCore.GetStreamProxy(ctx, channelID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 		app = proxy.App
 		appStream = proxy.Stream
 
-		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(c.Request.Context(), sms.DefaultMediaServerID)
+		svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(ctx, sms.DefaultMediaServerID)
 		if err != nil {
-			return nil, err
+			return nil, "", "", "", err
 		}
 		resp, err := a.uc.SMSAPI.smsCore.AddStreamProxy(svr, zlm.AddStreamProxyRequest{
 			Vhost:      "__defaultVhost__",
@@ -232,24 +431,28 @@ func (a GB28181API) play(c *gin.Context, _ *struct{}) (*playOutput, error) {
 			// AutoClose:    zlm.NewBool(false),
 		})
 		if err != nil {
-			return nil, reason.ErrServer.SetMsg(err.Error())
+			return nil, "", "", "", reason.ErrServer.SetMsg(err.Error())
 		}
-		a.uc.ProxyAPI.proxyCore.EditStreamProxyKey(c.Request.Context(), resp.Data.Key, proxy.ID)
+		a.uc.ProxyAPI.proxyCore.EditStreamProxyKey(ctx, resp.Data.Key, proxy.ID)
 	} else {
-		return nil, reason.ErrNotFound.SetMsg("不支持的播放通道")
+		return nil, "", "", "", reason.ErrNotFound.SetMsg("不支持的播放通道")
 	}
-	stream = app + "/" + appStream
+	return svr, app, appStream, session, nil
+}
 
-	host = c.Request.Host
+// newPlayOutput 生成各协议播放地址
+func newPlayOutput(c *gin.Context, httpPort int, svr *sms.MediaServer, app, appStream, session string) *playOutput {
+	stream := app + "/" + appStream
+
+	host := c.Request.Host
 	if l := strings.Split(c.Request.Host, ":"); len(l) == 2 {
 		host = l[0]
 	}
-	httpPort := a.uc.Conf.Server.HTTP.Port
 
 	// 播放规则
 	// https://github.com/zlmediakit/ZLMediaKit/wiki/%E6%92%AD%E6%94%BEurl%E8%A7%84%E5%88%99
 
-	out := playOutput{
+	return &playOutput{
 		App:    app,
 		Stream: appStream,
 		Items: []streamAddrItem{
@@ -273,21 +476,111 @@ func (a GB28181API) play(c *gin.Context, _ *struct{}) (*playOutput, error) {
 			},
 		},
 	}
+}
 
-	// 取一张快照
-	go func() {
-		body, err := a.uc.SMSAPI.smsCore.GetSnapshot(svr, zlm.GetSnapRequest{
-			URL:        out.Items[0].RTSP,
-			TimeoutSec: 10,
-			ExpireSec:  15,
-		})
-		if err != nil {
-			slog.ErrorContext(c.Request.Context(), "get snapshot", "err", err)
-		} else {
-			writeCover(a.uc.Conf.ConfigDir, channelID, body)
+type findRecordInput struct {
+	Start int64 `form:"start" binding:"required"` // 开始时间，unix 秒
+	End   int64 `form:"end" binding:"required"`   // 结束时间，unix 秒
+}
+
+// findRecord 查询设备端(NVR/IPC)存储的录像，按天合并时间段
+func (a GB28181API) findRecord(c *gin.Context, in *findRecordInput) (any, error) {
+	if in.End <= in.Start {
+		return nil, reason.ErrBadRequest.SetMsg("结束时间应大于开始时间")
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	out, err := a.uc.SipServer.QueryRecordInfo(&gbs.RecordInfoInput{
+		Channel: ch,
+		Start:   in.Start,
+		End:     in.End,
+	})
+	if err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return out, nil
+}
+
+type playbackInput struct {
+	Start int64 `json:"start" binding:"required"` // 开始时间，unix 秒
+	End   int64 `json:"end" binding:"required"`   // 结束时间，unix 秒
+}
+
+// playback 历史回放，同一时间段复用同一路流
+func (a GB28181API) playback(c *gin.Context, in *playbackInput) (*playOutput, error) {
+	if in.End <= in.Start {
+		return nil, reason.ErrBadRequest.SetMsg("结束时间应大于开始时间")
+	}
+	if a.uc.Conf.Media.SDPIP == "127.0.0.1" {
+		return nil, reason.ErrUsedLogic.SetMsg("请先配置流媒体 SDP 收流地址")
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), ch.DID)
+	if err != nil {
+		return nil, err
+	}
+	svr, err := a.uc.SMSAPI.smsCore.GetMediaServer(c.Request.Context(), sms.DefaultMediaServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	streamID := fmt.Sprintf("%s_%d_%d", ch.ID, in.Start, in.End)
+	if err := a.uc.SipServer.Playback(&gbs.PlaybackInput{
+		Channel:    ch,
+		SMS:        svr,
+		StreamMode: dev.StreamMode,
+		StreamID:   streamID,
+		Start:      in.Start,
+		End:        in.End,
+	}); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return newPlayOutput(c, a.uc.Conf.Server.HTTP.Port, svr, "rtp", streamID, ""), nil
+}
+
+type playbackControlInput struct {
+	Stream string `json:"stream" binding:"required"` // 回放接口返回的 stream
+	// 控制动作 pause:暂停 resume:继续 speed:倍速 seek:拖动 stop:停止
+	Action string  `json:"action" binding:"required"`
+	Scale  float64 `json:"scale"` // 倍速，speed 有效
+	Range  int64   `json:"range"` // 相对开始时间的偏移秒数，seek 有效
+}
+
+func (a GB28181API) playbackControl(c *gin.Context, in *playbackControlInput) (any, error) {
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if !a.uc.SipServer.IsChannelPlayback(in.Stream, ch.DeviceID, ch.ChannelID) {
+		return nil, reason.ErrNotFound.SetMsg("该通道不存在此回放流")
+	}
+	if in.Action == "stop" {
+		if err := a.uc.SipServer.StopPlayback(in.Stream); err != nil {
+			return nil, ErrDevice.SetMsg(err.Error())
 		}
-	}()
-	return &out, nil
+		return gin.H{"msg": "ok"}, nil
+	}
+
+	if err := a.uc.SipServer.PlaybackControl(&gbs.PlaybackControlInput{
+		StreamID: in.Stream,
+		Action:   in.Action,
+		Scale:    in.Scale,
+		Range:    in.Range,
+	}); err != nil {
+		switch {
+		case errors.Is(err, gbs.ErrPlaybackAction):
+			return nil, reason.ErrBadRequest.SetMsg(err.Error())
+		case errors.Is(err, gbs.ErrStreamNotExist):
+			return nil, reason.ErrNotFound.SetMsg(err.Error())
+		}
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
 }
 
 type refreshSnapshotInput struct {
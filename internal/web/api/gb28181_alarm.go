@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs"
+)
+
+// >>> alarm >>>>>>>>>>>>>>>>>>>>
+
+func (a GB28181API) findAlarm(c *gin.Context, in *gb28181.FindAlarmInput) (any, error) {
+	items, total, err := a.gb28181Core.FindAlarm(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+type resetAlarmInput struct {
+	ChannelID string `json:"channel_id"`   // 报警源国标编码，为空时复位整个设备
+	Method    int    `json:"alarm_method"` // 报警方式，为 0 时复位全部
+	Type      int    `json:"alarm_type"`   // 报警类型
+}
+
+// resetAlarm 报警复位
+func (a GB28181API) resetAlarm(c *gin.Context, in *resetAlarmInput) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.ResetAlarm(&gbs.ResetAlarmInput{
+		DeviceID:  dev.DeviceID,
+		ChannelID: in.ChannelID,
+		Method:    in.Method,
+		Type:      in.Type,
+	}); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
+}
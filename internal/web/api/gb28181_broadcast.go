@@ -0,0 +1,83 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// >>> broadcast >>>>>>>>>>>>>>>>>>>>
+
+// broadcastApp 浏览器推送广播音频的默认应用名
+const broadcastApp = "broadcast"
+
+type broadcastInput struct {
+	Action     string   `json:"action" binding:"required,oneof=start stop"`
+	App        string   `json:"app"`         // 浏览器推送的音频流应用名，默认 broadcast
+	Stream     string   `json:"stream"`      // 音频流 ID，默认为路径中的通道 ID
+	ChannelIDs []string `json:"channel_ids"` // 同时广播的其它通道
+}
+
+type broadcastResult struct {
+	ChannelID string `json:"channel_id"`
+	Error     string `json:"error,omitempty"` // 为空表示成功
+}
+
+type broadcastOutput struct {
+	App    string            `json:"app"`
+	Stream string            `json:"stream"`
+	Items  []broadcastResult `json:"items"`
+}
+
+// broadcast 语音广播，将浏览器推送到流媒体的音频流转发到一个或多个通道，与实时点播同时使用即为双向对讲
+func (a GB28181API) broadcast(c *gin.Context, in *broadcastInput) (*broadcastOutput, error) {
+	ctx := c.Request.Context()
+	ids := append([]string{c.Param("id")}, in.ChannelIDs...)
+	out := broadcastOutput{App: in.App, Stream: in.Stream, Items: make([]broadcastResult, 0, len(ids))}
+	if out.App == "" {
+		out.App = broadcastApp
+	}
+	if out.Stream == "" {
+		out.Stream = ids[0]
+	}
+
+	var svr *sms.MediaServer
+	if in.Action == "start" {
+		if a.uc.Conf.Media.SDPIP == "127.0.0.1" {
+			return nil, reason.ErrUsedLogic.SetMsg("请先配置流媒体 SDP 收流地址")
+		}
+		var err error
+		if svr, err = a.uc.SMSAPI.smsCore.GetMediaServer(ctx, sms.DefaultMediaServerID); err != nil {
+			return nil, err
+		}
+	}
+
+	var success int
+	for _, id := range ids {
+		result := broadcastResult{ChannelID: id}
+		ch, err := a.gb28181Core.GetChannel(ctx, id)
+		if err == nil {
+			if in.Action == "start" {
+				err = a.uc.SipServer.Broadcast(&gbs.BroadcastInput{
+					Channel: ch,
+					SMS:     svr,
+					App:     out.App,
+					Stream:  out.Stream,
+				})
+			} else {
+				err = a.uc.SipServer.StopBroadcast(ch.DeviceID, ch.ChannelID)
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			success++
+		}
+		out.Items = append(out.Items, result)
+	}
+	if success == 0 {
+		return nil, ErrDevice.SetMsg(out.Items[0].Error)
+	}
+	return &out, nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// >>> device config >>>>>>>>>>>>>>>>>>>>
+
+type deviceConfigOutput struct {
+	Result string `json:"result"` // 设备应答结果 OK/ERROR
+}
+
+// getDeviceConfig 设备配置查询，query 参数 channel_id 可指定查询通道的配置
+func (a GB28181API) getDeviceConfig(c *gin.Context, _ *struct{}) (any, error) {
+	in, err := a.newConfigInput(c)
+	if err != nil {
+		return nil, err
+	}
+	params, err := a.uc.SipServer.QueryConfig(in)
+	if err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	out := params.Get(in.ConfigType)
+	if out == nil {
+		return nil, reason.ErrNotFound.SetMsg("设备未返回该配置")
+	}
+	return out, nil
+}
+
+// setDeviceConfig 设备配置，请求体为对应配置类型的 json
+func (a GB28181API) setDeviceConfig(c *gin.Context, in *json.RawMessage) (*deviceConfigOutput, error) {
+	cin, err := a.newConfigInput(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(*in) == 0 {
+		return nil, reason.ErrBadRequest.SetMsg("配置内容不能为空")
+	}
+	var params gbs.ConfigParams
+	if err := params.Set(cin.ConfigType, *in); err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	result, err := a.uc.SipServer.DeviceConfig(cin, &params)
+	if err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return &deviceConfigOutput{Result: result}, nil
+}
+
+func (a GB28181API) newConfigInput(c *gin.Context) (*gbs.ConfigInput, error) {
+	typ, err := gbs.ParseConfigType(c.Param("type"))
+	if err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	in := gbs.ConfigInput{DeviceID: dev.DeviceID, TargetID: dev.DeviceID, ConfigType: typ}
+	if id := c.Query("channel_id"); id != "" {
+		ch, err := a.gb28181Core.GetChannel(c.Request.Context(), id)
+		if err != nil {
+			return nil, err
+		}
+		if ch.DeviceID != dev.DeviceID {
+			return nil, reason.ErrBadRequest.SetMsg("通道不属于该设备")
+		}
+		in.TargetID = ch.ChannelID
+	}
+	return &in, nil
+}
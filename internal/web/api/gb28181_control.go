@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// >>> control >>>>>>>>>>>>>>>>>>>>
+
+type deviceControlInput struct {
+	// 控制命令 teleboot/record/stop_record/set_guard/reset_guard/iframe/home_position
+	Cmd          string            `json:"cmd" binding:"required"`
+	HomePosition *gbs.HomePosition `json:"home_position"` // 看守位参数，仅 home_position 有效
+}
+
+type deviceControlOutput struct {
+	Result string `json:"result"` // 设备应答结果 OK/ERROR
+}
+
+// deviceControl 设备控制，控制目标为设备自身
+func (a GB28181API) deviceControl(c *gin.Context, in *deviceControlInput) (*deviceControlOutput, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	return a.sendDeviceControl(dev.DeviceID, dev.DeviceID, in)
+}
+
+// channelControl 设备控制，控制目标为通道
+func (a GB28181API) channelControl(c *gin.Context, in *deviceControlInput) (*deviceControlOutput, error) {
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	return a.sendDeviceControl(ch.DeviceID, ch.ChannelID, in)
+}
+
+func (a GB28181API) sendDeviceControl(deviceID, targetID string, in *deviceControlInput) (*deviceControlOutput, error) {
+	result, err := a.uc.SipServer.DeviceControl(&gbs.DeviceControlInput{
+		DeviceID:     deviceID,
+		TargetID:     targetID,
+		Cmd:          in.Cmd,
+		HomePosition: in.HomePosition,
+	})
+	if err != nil {
+		if errors.Is(err, gbs.ErrControlCmd) {
+			return nil, reason.ErrBadRequest.SetMsg(err.Error())
+		}
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return &deviceControlOutput{Result: result}, nil
+}
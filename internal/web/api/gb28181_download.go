@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// >>> download >>>>>>>>>>>>>>>>>>>>
+
+func (a GB28181API) findDownload(c *gin.Context, in *gb28181.FindDownloadInput) (any, error) {
+	items, total, err := a.gb28181Core.FindDownload(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+func (a GB28181API) getDownload(c *gin.Context, _ *struct{}) (*gb28181.Download, error) {
+	return a.gb28181Core.GetDownload(c.Request.Context(), c.Param("id"))
+}
+
+// addDownload 创建下载任务，向设备发起 s=Download 会话，ZLM 收流后录制为 mp4
+func (a GB28181API) addDownload(c *gin.Context, in *gb28181.AddDownloadInput) (*gb28181.Download, error) {
+	if a.uc.Conf.Media.SDPIP == "127.0.0.1" {
+		return nil, reason.ErrUsedLogic.SetMsg("请先配置流媒体 SDP 收流地址")
+	}
+	ctx := c.Request.Context()
+	ch, err := a.gb28181Core.GetChannel(ctx, in.CID)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := a.gb28181Core.GetDevice(ctx, ch.DID)
+	if err != nil {
+		return nil, err
+	}
+	svr, err := a.uc.SMSAPI.smsCore.GetMediaServer(ctx, sms.DefaultMediaServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := a.gb28181Core.AddDownload(ctx, ch, in)
+	if err != nil {
+		return nil, err
+	}
+
+	speed, err := a.uc.SipServer.Download(&gbs.DownloadInput{
+		Channel:    ch,
+		SMS:        svr,
+		StreamMode: dev.StreamMode,
+		StreamID:   out.ID,
+		Start:      out.StartTime,
+		End:        out.EndTime,
+		Speed:      out.Speed,
+	})
+	if err != nil {
+		_, _ = a.gb28181Core.EditDownload(ctx, out.ID, func(d *gb28181.Download) {
+			d.Status = gb28181.DownloadStatusFailed
+			d.Error = err.Error()
+		})
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+
+	return a.gb28181Core.EditDownload(ctx, out.ID, func(d *gb28181.Download) {
+		d.Status = gb28181.DownloadStatusDownloading
+		d.Speed = speed
+		d.StartedAt = time.Now().Unix()
+	})
+}
+
+// cancelDownload 取消下载，已录制的部分仍会生成文件
+func (a GB28181API) cancelDownload(c *gin.Context, _ *struct{}) (*gb28181.Download, error) {
+	ctx := c.Request.Context()
+	dl, err := a.gb28181Core.GetDownload(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if dl.IsDone() {
+		return nil, reason.ErrUsedLogic.SetMsg("任务已结束")
+	}
+	out, err := a.gb28181Core.EditDownload(ctx, dl.ID, func(d *gb28181.Download) {
+		d.Status = gb28181.DownloadStatusCanceled
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.StopDownload(dl.ID); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return out, nil
+}
+
+func (a GB28181API) delDownload(c *gin.Context, _ *struct{}) (*gb28181.Download, error) {
+	id := c.Param("id")
+	_ = a.uc.SipServer.StopDownload(id)
+	return a.gb28181Core.DelDownload(c.Request.Context(), id)
+}
+
+// getDownloadFile 跳转到流媒体的录制文件
+func (a GB28181API) getDownloadFile(c *gin.Context) {
+	dl, err := a.gb28181Core.GetDownload(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		web.Fail(c, err)
+		return
+	}
+	if dl.FileURL == "" {
+		web.Fail(c, reason.ErrNotFound.SetMsg("文件尚未生成"))
+		return
+	}
+	c.Redirect(http.StatusFound, "/proxy/sms/"+strings.TrimPrefix(dl.FileURL, "/"))
+}
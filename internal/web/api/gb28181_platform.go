@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/bz"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs"
+)
+
+// OpenStream 实现 gbs.CascadeSource，上级点播时确保共享通道的流已就绪
+func (a GB28181API) OpenStream(ctx context.Context, channelID string) (*sms.MediaServer, string, string, error) {
+	svr, app, stream, _, err := a.openStream(ctx, channelID, gbs.StreamMain)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !strings.HasPrefix(channelID, bz.IDPrefixGBChannel) {
+		return svr, app, stream, nil
+	}
+
+	// 国标通道未在播放时，主动向设备点播
+	ch, err := a.gb28181Core.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !ch.IsPlaying {
+		if err := a.playChannel(ctx, channelID, svr, gbs.StreamMain, nil); err != nil {
+			return nil, "", "", err
+		}
+	}
+	return svr, app, stream, nil
+}
+
+// >>> platform >>>>>>>>>>>>>>>>>>>>
+
+func (a GB28181API) findPlatform(c *gin.Context, in *gb28181.FindPlatformInput) (any, error) {
+	items, total, err := a.gb28181Core.FindPlatform(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+func (a GB28181API) getPlatform(c *gin.Context, _ *struct{}) (*gb28181.Platform, error) {
+	return a.gb28181Core.GetPlatform(c.Request.Context(), c.Param("id"))
+}
+
+// addPlatform 添加上级平台，启用时立即发起注册
+func (a GB28181API) addPlatform(c *gin.Context, in *gb28181.AddPlatformInput) (*gb28181.Platform, error) {
+	out, err := a.gb28181Core.AddPlatform(c.Request.Context(), in)
+	if err != nil {
+		return nil, err
+	}
+	a.uc.SipServer.StartCascade(out)
+	return out, nil
+}
+
+// editPlatform 修改上级平台，按新配置重新注册
+func (a GB28181API) editPlatform(c *gin.Context, in *gb28181.EditPlatformInput) (*gb28181.Platform, error) {
+	out, err := a.gb28181Core.EditPlatform(c.Request.Context(), in, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	a.uc.SipServer.StartCascade(out)
+	return out, nil
+}
+
+// delPlatform 删除上级平台，先注销再删除
+func (a GB28181API) delPlatform(c *gin.Context, _ *struct{}) (*gb28181.Platform, error) {
+	id := c.Param("id")
+	a.uc.SipServer.StopCascade(id)
+	return a.gb28181Core.DelPlatform(c.Request.Context(), id)
+}
+
+func (a GB28181API) findPlatformChannel(c *gin.Context, in *gb28181.FindPlatformChannelInput) (any, error) {
+	in.PlatformID = c.Param("id")
+	items, total, err := a.gb28181Core.FindPlatformChannel(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+// addPlatformChannels 批量共享通道，遇到错误即停止
+func (a GB28181API) addPlatformChannels(c *gin.Context, in *gb28181.AddPlatformChannelsInput) (any, error) {
+	ctx := c.Request.Context()
+	platform, err := a.gb28181Core.GetPlatform(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*gb28181.PlatformChannel, 0, len(in.Items))
+	for _, item := range in.Items {
+		if item.Name == "" {
+			item.Name = a.channelName(ctx, item.ChannelID)
+		}
+		out, err := a.gb28181Core.AddPlatformChannel(ctx, platform.ID, &item)
+		if err != nil {
+			return gin.H{"items": items}, err
+		}
+		items = append(items, out)
+	}
+	return gin.H{"items": items}, nil
+}
+
+func (a GB28181API) delPlatformChannel(c *gin.Context, _ *struct{}) (*gb28181.PlatformChannel, error) {
+	return a.gb28181Core.DelPlatformChannel(c.Request.Context(), c.Param("id"), c.Param("channel_id"))
+}
+
+// channelName 推流取名称，拉流代理取流 ID，国标通道由 core 填充
+func (a GB28181API) channelName(ctx context.Context, channelID string) string {
+	switch gb28181.PlatformSource(channelID) {
+	case gb28181.PlatformSourceRTMP:
+		if pu, err := a.uc.MediaAPI.pushCore.GetStreamPush(ctx, channelID); err == nil {
+			return pu.Name
+		}
+	case gb28181.PlatformSourceRTSP:
+		if proxy, err := a.uc.ProxyAPI.proxyCore.GetStreamProxy(ctx, channelID); err == nil {
+			return proxy.Stream
+		}
+	}
+	return ""
+}
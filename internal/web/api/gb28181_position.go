@@ -0,0 +1,78 @@
+package api
+
+import (
+	"cmp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+)
+
+// >>> position >>>>>>>>>>>>>>>>>>>>
+
+// getLatestPosition 通道最新位置
+func (a GB28181API) getLatestPosition(c *gin.Context, _ *struct{}) (*gb28181.Position, error) {
+	ctx := c.Request.Context()
+	ch, err := a.gb28181Core.GetChannel(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	return a.gb28181Core.GetLatestPosition(ctx, ch.DeviceID, ch.ChannelID)
+}
+
+// findTrack 通道时间段内的轨迹
+func (a GB28181API) findTrack(c *gin.Context, in *gb28181.FindTrackInput) (*gb28181.Track, error) {
+	ctx := c.Request.Context()
+	ch, err := a.gb28181Core.GetChannel(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	return a.gb28181Core.FindTrack(ctx, ch.DeviceID, ch.ChannelID, in)
+}
+
+type subscribeMobilePositionInput struct {
+	Interval int `json:"interval" binding:"min=0"` // 上报间隔(秒)，为 0 时使用配置
+	Expires  int `json:"expires" binding:"min=0"`  // 订阅有效期(秒)，为 0 时使用配置
+}
+
+// subscribeMobilePosition 订阅移动设备位置
+func (a GB28181API) subscribeMobilePosition(c *gin.Context, in *subscribeMobilePositionInput) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.uc.Conf.Sip
+	if in.Interval == 0 {
+		in.Interval = cfg.MobilePositionInterval
+	}
+	if in.Expires == 0 {
+		in.Expires = cmp.Or(cfg.MobilePositionSubscribeExpires, 3600)
+	}
+	if err := a.uc.SipServer.SubscribeMobilePosition(dev.DeviceID, in.Interval, in.Expires); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+// unsubscribeMobilePosition 取消移动设备位置订阅
+func (a GB28181API) unsubscribeMobilePosition(c *gin.Context, _ *struct{}) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.UnsubscribeMobilePosition(dev.DeviceID); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+// queryMobilePosition 查询移动设备位置，结果异步上报
+func (a GB28181API) queryMobilePosition(c *gin.Context, _ *struct{}) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.QueryMobilePosition(dev.DeviceID); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return gin.H{"msg": "ok"}, nil
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+)
+
+// ptzError 参数类错误返回 400，其它视为设备错误
+func ptzError(err error) error {
+	if errors.Is(err, gbs.ErrPTZAction) || errors.Is(err, gbs.ErrPresetID) || errors.Is(err, gbs.ErrPTZParam) {
+		return reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	return ErrDevice.SetMsg(err.Error())
+}
+
+type ptzControlInput struct {
+	// 控制动作 up/down/left/right/upleft/upright/downleft/downright/zoomin/zoomout/focusnear/focusfar/irisopen/irisclose/stop
+	Action string `json:"action" binding:"required"`
+	// 速度 0~255
+	Speed uint8 `json:"speed"`
+}
+
+// ptzControl 云台控制，方向/变倍等动作会持续运动，直到发送 stop
+func (a GB28181API) ptzControl(c *gin.Context, in *ptzControlInput) (any, error) {
+	channelID := c.Param("id")
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.uc.SipServer.PTZControl(&gbs.PTZControlInput{
+		Channel: ch,
+		Action:  in.Action,
+		Speed:   in.Speed,
+	}); err != nil {
+		return nil, ptzError(err)
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+func presetIDParam(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("preset_id"))
+	if err != nil || id < 1 || id > 255 {
+		return 0, reason.ErrBadRequest.SetMsg(gbs.ErrPresetID.Error())
+	}
+	return id, nil
+}
+
+func (a GB28181API) findPreset(c *gin.Context, _ *struct{}) (any, error) {
+	channelID := c.Param("id")
+	items, err := a.gb28181Core.FindPreset(c.Request.Context(), channelID)
+	return gin.H{"items": items, "total": len(items)}, err
+}
+
+// addPreset 将通道当前位置设置为预置位，并记录名称
+func (a GB28181API) addPreset(c *gin.Context, in *gb28181.AddPresetInput) (any, error) {
+	channelID := c.Param("id")
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.SetPreset(&gbs.PresetInput{Channel: ch, PresetID: in.PresetID}); err != nil {
+		return nil, ptzError(err)
+	}
+	return a.gb28181Core.AddPreset(c.Request.Context(), channelID, in)
+}
+
+// editPreset 国标没有重命名指令，仅修改平台记录
+func (a GB28181API) editPreset(c *gin.Context, in *gb28181.EditPresetInput) (any, error) {
+	presetID, err := presetIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	return a.gb28181Core.EditPreset(c.Request.Context(), in, c.Param("id"), presetID)
+}
+
+func (a GB28181API) delPreset(c *gin.Context, _ *struct{}) (any, error) {
+	channelID := c.Param("id")
+	presetID, err := presetIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.DelPreset(&gbs.PresetInput{Channel: ch, PresetID: presetID}); err != nil {
+		return nil, ptzError(err)
+	}
+	return a.gb28181Core.DelPreset(c.Request.Context(), channelID, presetID)
+}
+
+func (a GB28181API) callPreset(c *gin.Context, _ *struct{}) (any, error) {
+	presetID, err := presetIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.CallPreset(&gbs.PresetInput{Channel: ch, PresetID: presetID}); err != nil {
+		return nil, ptzError(err)
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+// syncPresets 通过 PresetQuery 查询设备预置位并入库
+func (a GB28181API) syncPresets(c *gin.Context, _ *struct{}) (any, error) {
+	channelID := c.Param("id")
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.QueryPreset(ch); err != nil {
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	items, err := a.gb28181Core.FindPreset(c.Request.Context(), channelID)
+	return gin.H{"items": items, "total": len(items)}, err
+}
+
+func cruiseIDParam(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("cruise_id"))
+	if err != nil || id < 0 || id > 255 {
+		return 0, reason.ErrBadRequest.SetMsg("cruise id must be between 0 and 255")
+	}
+	return id, nil
+}
+
+func (a GB28181API) findCruise(c *gin.Context, _ *struct{}) (any, error) {
+	items, err := a.gb28181Core.FindCruise(c.Request.Context(), c.Param("id"))
+	return gin.H{"items": items, "total": len(items)}, err
+}
+
+// addCruise 下发巡航轨迹到设备并保存
+func (a GB28181API) addCruise(c *gin.Context, in *gb28181.AddCruiseInput) (any, error) {
+	channelID := c.Param("id")
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.SetCruise(&gbs.CruiseInput{
+		Channel:   ch,
+		CruiseID:  in.CruiseID,
+		Points:    in.Points,
+		Speed:     in.Speed,
+		DwellTime: in.DwellTime,
+	}); err != nil {
+		return nil, ptzError(err)
+	}
+	return a.gb28181Core.AddCruise(c.Request.Context(), channelID, in)
+}
+
+func (a GB28181API) editCruise(c *gin.Context, in *gb28181.EditCruiseInput) (any, error) {
+	channelID := c.Param("id")
+	cruiseID, err := cruiseIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.gb28181Core.GetCruise(c.Request.Context(), channelID, cruiseID); err != nil {
+		return nil, err
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.SetCruise(&gbs.CruiseInput{
+		Channel:   ch,
+		CruiseID:  cruiseID,
+		Points:    in.Points,
+		Speed:     in.Speed,
+		DwellTime: in.DwellTime,
+	}); err != nil {
+		return nil, ptzError(err)
+	}
+	return a.gb28181Core.EditCruise(c.Request.Context(), in, channelID, cruiseID)
+}
+
+func (a GB28181API) delCruise(c *gin.Context, _ *struct{}) (any, error) {
+	channelID := c.Param("id")
+	cruiseID, err := cruiseIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.DelCruise(ch, cruiseID); err != nil {
+		return nil, ptzError(err)
+	}
+	return a.gb28181Core.DelCruise(c.Request.Context(), channelID, cruiseID)
+}
+
+func (a GB28181API) startCruise(c *gin.Context, _ *struct{}) (any, error) {
+	cruiseID, err := cruiseIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.StartCruise(ch, cruiseID); err != nil {
+		return nil, ptzError(err)
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+func (a GB28181API) stopCruise(c *gin.Context, _ *struct{}) (any, error) {
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.StopCruise(ch); err != nil {
+		return nil, ptzError(err)
+	}
+	return gin.H{"msg": "ok"}, nil
+}
+
+type scanControlInput struct {
+	// 扫描组号 0~255
+	ScanID int `json:"scan_id"`
+	// 动作 left:设置左边界 right:设置右边界 speed:设置速度 start:开始扫描 stop:停止
+	Action string `json:"action" binding:"required"`
+	// 扫描速度 0~4095，仅 speed 动作有效
+	Speed int `json:"speed"`
+}
+
+func (a GB28181API) scanControl(c *gin.Context, in *scanControlInput) (any, error) {
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := a.uc.SipServer.ScanControl(&gbs.ScanInput{
+		Channel: ch,
+		ScanID:  in.ScanID,
+		Action:  in.Action,
+		Speed:   in.Speed,
+	}); err != nil {
+		return nil, ptzError(err)
+	}
+	return gin.H{"msg": "ok"}, nil
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/orm"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// >>> device snapshot >>>>>>>>>>>>>>>>>>>>
+
+const (
+	snapshotDir = "snapshot"
+	// 上传地址有效期
+	snapshotUploadExpire = 10 * time.Minute
+	// 单张图像最大字节数
+	snapshotMaxSize = 10 << 20
+	// 单个会话最多图像数，与协议连拍上限一致
+	snapshotMaxNum = 10
+)
+
+var snapshotSessionRe = regexp.MustCompile(`^[0-9a-zA-Z]{1,32}$`)
+
+type captureSnapshotInput struct {
+	SnapNum  int `json:"snap_num"` // 连拍张数 1~10，默认 1
+	Interval int `json:"interval"` // 单张抓拍间隔(秒)，默认 1
+}
+
+type captureSnapshotOutput struct {
+	SessionID string `json:"session_id"` // 抓拍会话，用于查询上传的图像
+	Result    string `json:"result"`     // 设备应答结果 OK/ERROR
+}
+
+type snapshotSessionOutput struct {
+	SessionID string   `json:"session_id"`
+	Images    []string `json:"images"` // 图像地址，按上传顺序
+}
+
+// captureSnapshot 设备图像抓拍，设备异步上传图像
+func (a GB28181API) captureSnapshot(c *gin.Context, in *captureSnapshotInput) (*captureSnapshotOutput, error) {
+	ch, err := a.gb28181Core.GetChannel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if in.SnapNum <= 0 {
+		in.SnapNum = 1
+	}
+	if in.Interval <= 0 {
+		in.Interval = 1
+	}
+
+	session := orm.GenerateRandomString(32)
+	result, err := a.uc.SipServer.QuerySnapshot(&gbs.SnapshotInput{
+		DeviceID:  ch.DeviceID,
+		ChannelID: ch.ChannelID,
+		SnapNum:   in.SnapNum,
+		Interval:  in.Interval,
+		UploadURL: a.snapshotUploadURL(ch.ChannelID, session),
+		SessionID: session,
+	})
+	if err != nil {
+		if errors.Is(err, gbs.ErrSnapshotParam) {
+			return nil, reason.ErrBadRequest.SetMsg(err.Error())
+		}
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return &captureSnapshotOutput{SessionID: session, Result: result}, nil
+}
+
+// getSnapshotSession 查询抓拍会话已上传的图像
+func (a GB28181API) getSnapshotSession(c *gin.Context, _ *struct{}) (*snapshotSessionOutput, error) {
+	channelID, session := c.Param("id"), c.Param("session")
+	if !snapshotSessionRe.MatchString(session) || strings.Contains(channelID, "..") {
+		return nil, reason.ErrBadRequest.SetMsg("无效的会话")
+	}
+	names := a.snapshotFiles(channelID, session)
+	out := snapshotSessionOutput{SessionID: session, Images: make([]string, 0, len(names))}
+	token := c.GetString("token")
+	for _, name := range names {
+		out.Images = append(out.Images, fmt.Sprintf("%s/channels/%s/snapshot/sessions/%s/%s?token=%s", web.GetBaseURL(c.Request), channelID, session, name, token))
+	}
+	return &out, nil
+}
+
+// getSnapshotImage 获取抓拍图像
+func (a GB28181API) getSnapshotImage(c *gin.Context) {
+	channelID, session, name := c.Param("id"), c.Param("session"), c.Param("name")
+	if !snapshotSessionRe.MatchString(session) || strings.Contains(channelID, "..") ||
+		filepath.Base(name) != name || !strings.HasPrefix(name, session+"_") {
+		web.Fail(c, reason.ErrBadRequest.SetMsg("无效的图像"))
+		return
+	}
+	path := filepath.Join(a.snapshotPath(channelID), name)
+	if _, err := os.Stat(path); err != nil {
+		web.Fail(c, reason.ErrNotFound.SetMsg("图像不存在"))
+		return
+	}
+	c.File(path)
+}
+
+// uploadSnapshot 接收设备上传的抓拍图像，通过签名校验，支持单图 body 与 multipart 多图
+func (a GB28181API) uploadSnapshot(c *gin.Context) {
+	channelID, session := c.Param("channel"), c.Param("session")
+	if !snapshotSessionRe.MatchString(session) || filepath.Base(channelID) != channelID || !a.verifySignedURL(c) {
+		web.Fail(c, reason.ErrUnauthorizedToken)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, snapshotMaxNum*snapshotMaxSize)
+
+	var images [][]byte
+	if form, err := c.MultipartForm(); err == nil {
+		for _, files := range form.File {
+			for _, fh := range files {
+				f, err := fh.Open()
+				if err != nil {
+					web.Fail(c, reason.ErrBadRequest.SetMsg(err.Error()))
+					return
+				}
+				b, err := io.ReadAll(io.LimitReader(f, snapshotMaxSize))
+				f.Close()
+				if err != nil {
+					web.Fail(c, reason.ErrBadRequest.SetMsg(err.Error()))
+					return
+				}
+				images = append(images, b)
+			}
+		}
+	} else {
+		b, err := io.ReadAll(io.LimitReader(c.Request.Body, snapshotMaxSize))
+		if err != nil {
+			web.Fail(c, reason.ErrBadRequest.SetMsg(err.Error()))
+			return
+		}
+		images = append(images, b)
+	}
+
+	dir := a.snapshotPath(channelID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		web.Fail(c, reason.ErrServer.SetMsg(err.Error()))
+		return
+	}
+	for _, b := range images {
+		if len(b) == 0 {
+			continue
+		}
+		if _, err := saveSnapshot(dir, session, b); err != nil {
+			if errors.Is(err, errSnapshotFull) {
+				break
+			}
+			web.Fail(c, reason.ErrServer.SetMsg(err.Error()))
+			return
+		}
+		// 最新图像同时作为通道封面
+		if err := writeCover(a.uc.Conf.ConfigDir, channelID, b); err != nil {
+			slog.Error("write cover", "err", err, "channelID", channelID)
+		}
+	}
+	web.Success(c, gin.H{"msg": "ok"})
+}
+
+var errSnapshotFull = errors.New("snapshot session is full")
+
+// saveSnapshot 按会话内序号保存图像，以独占创建占用序号，并发上传不会互相覆盖
+func saveSnapshot(dir, session string, b []byte) (string, error) {
+	for idx := 1; idx <= snapshotMaxNum; idx++ {
+		name := fmt.Sprintf("%s_%02d.jpg", session, idx)
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(b)
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		return name, err
+	}
+	return "", errSnapshotFull
+}
+
+func (a GB28181API) snapshotPath(channelID string) string {
+	return filepath.Join(a.uc.Conf.ConfigDir, snapshotDir, channelID)
+}
+
+// snapshotFiles 会话已上传的图像文件名
+func (a GB28181API) snapshotFiles(channelID, session string) []string {
+	matches, _ := filepath.Glob(filepath.Join(a.snapshotPath(channelID), session+"_*.jpg"))
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// snapshotUploadURL 生成带签名的图像上传地址
+func (a GB28181API) snapshotUploadURL(channelID, session string) string {
+	return a.signedURL(fmt.Sprintf("/gb28181/snapshot/%s/%s", channelID, session), snapshotUploadExpire)
+}
+
+// signedURL 生成设备可访问的带签名地址，设备无法携带 token，以签名鉴权
+func (a GB28181API) signedURL(path string, ttl time.Duration) string {
+	base := a.uc.Conf.Server.HTTP.ExternalURL
+	if base == "" {
+		base = fmt.Sprintf("http://%s:%d", a.uc.Conf.Media.WebHookIP, a.uc.Conf.Server.HTTP.Port)
+	}
+	expire := time.Now().Add(ttl).Unix()
+	return fmt.Sprintf("%s%s?expire=%d&sign=%s", strings.TrimSuffix(base, "/"), path, expire, a.sign(path, expire))
+}
+
+// verifySignedURL 校验 signedURL 生成的地址
+func (a GB28181API) verifySignedURL(c *gin.Context) bool {
+	expire, _ := strconv.ParseInt(c.Query("expire"), 10, 64)
+	if time.Now().Unix() > expire {
+		return false
+	}
+	return hmac.Equal([]byte(c.Query("sign")), []byte(a.sign(c.Request.URL.Path, expire)))
+}
+
+func (a GB28181API) sign(path string, expire int64) string {
+	h := hmac.New(sha256.New, []byte(a.uc.Conf.Server.HTTP.JwtSecret))
+	fmt.Fprintf(h, "%s:%d", path, expire)
+	return hex.EncodeToString(h.Sum(nil))
+}
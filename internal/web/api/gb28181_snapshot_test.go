@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/conf"
+)
+
+func newSignTestAPI() GB28181API {
+	var cfg conf.Bootstrap
+	cfg.Server.HTTP.JwtSecret = "secret"
+	cfg.Server.HTTP.ExternalURL = "http://127.0.0.1:15123/"
+	return GB28181API{uc: &Usecase{Conf: &cfg}}
+}
+
+func verifyURL(a GB28181API, url string) bool {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", strings.TrimPrefix(url, "http://127.0.0.1:15123"), nil)
+	return a.verifySignedURL(c)
+}
+
+func TestSignedURL(t *testing.T) {
+	a := newSignTestAPI()
+	url := a.signedURL("/gb28181/snapshot/34020000001320000001/abc", time.Minute)
+	if !strings.HasPrefix(url, "http://127.0.0.1:15123/gb28181/snapshot/") {
+		t.Fatalf("unexpected url %s", url)
+	}
+	if !verifyURL(a, url) {
+		t.Fatal("expect valid url")
+	}
+
+	last := "0"
+	if strings.HasSuffix(url, last) {
+		last = "1"
+	}
+	cases := map[string]string{
+		"other path":   strings.Replace(url, "/abc?", "/abd?", 1),
+		"other expire": strings.Replace(url, "expire=", "expire=9", 1),
+		"other sign":   url[:len(url)-1] + last,
+		"no sign":      url[:strings.Index(url, "&sign=")],
+	}
+	for name, u := range cases {
+		if u == url {
+			t.Fatalf("%s: url not changed", name)
+		}
+		if verifyURL(a, u) {
+			t.Fatalf("%s: expect tampered url rejected", name)
+		}
+	}
+
+	other := newSignTestAPI()
+	other.uc.Conf.Server.HTTP.JwtSecret = "other"
+	if verifyURL(other, url) {
+		t.Fatal("expect url rejected by other secret")
+	}
+
+	if verifyURL(a, a.signedURL("/gb28181/snapshot/34020000001320000001/abc", -time.Second)) {
+		t.Fatal("expect expired url rejected")
+	}
+}
+
+func TestSaveSnapshotConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		ok   int
+		full int
+	)
+	for range snapshotMaxNum * 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := saveSnapshot(dir, "abc", []byte("jpg"))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, errSnapshotFull):
+				full++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if ok != snapshotMaxNum || full != snapshotMaxNum {
+		t.Fatalf("expect %d saved and %d full, got %d and %d", snapshotMaxNum, snapshotMaxNum, ok, full)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != snapshotMaxNum {
+		t.Fatalf("expect %d files, got %d", snapshotMaxNum, len(entries))
+	}
+}
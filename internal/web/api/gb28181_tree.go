@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+)
+
+// >>> catalog tree >>>>>>>>>>>>>>>>>>>>
+
+type findDeviceTreeInput struct {
+	ParentID string `form:"parent_id"` // 父节点国标编码，为空时返回根节点
+}
+
+// findDeviceTree 设备目录树，按层级懒加载
+func (a GB28181API) findDeviceTree(c *gin.Context, in *findDeviceTreeInput) (any, error) {
+	dev, err := a.gb28181Core.GetDevice(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.gb28181Core.FindDeviceTree(c.Request.Context(), dev.ID, in.ParentID)
+	return gin.H{"items": items}, err
+}
+
+// findTree 全局目录树，根节点为设备，指定 did 后展开该设备目录
+func (a GB28181API) findTree(c *gin.Context, in *gb28181.FindTreeInput) (any, error) {
+	items, err := a.gb28181Core.FindTree(c.Request.Context(), in)
+	return gin.H{"items": items}, err
+}
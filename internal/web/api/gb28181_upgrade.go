@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/ixugo/goddd/pkg/reason"
+	"github.com/ixugo/goddd/pkg/web"
+)
+
+// >>> firmware & upgrade >>>>>>>>>>>>>>>>>>>>
+
+const (
+	firmwareDir = "firmware"
+	// 固件下载地址有效期，设备可能排队下载
+	firmwareURLExpire = 2 * time.Hour
+)
+
+func (a GB28181API) firmwarePath(id string) string {
+	return filepath.Join(a.uc.Conf.ConfigDir, firmwareDir, id)
+}
+
+func (a GB28181API) findFirmware(c *gin.Context, in *gb28181.FindFirmwareInput) (any, error) {
+	items, total, err := a.gb28181Core.FindFirmware(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+// addFirmware 上传固件，multipart 表单，文件字段为 file
+func (a GB28181API) addFirmware(c *gin.Context, in *gb28181.AddFirmwareInput) (*gb28181.Firmware, error) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, reason.ErrBadRequest.SetMsg("请上传固件文件")
+	}
+	src, err := fh.Open()
+	if err != nil {
+		return nil, reason.ErrBadRequest.SetMsg(err.Error())
+	}
+	defer src.Close()
+
+	id := a.gb28181Core.NewFirmwareID()
+	path := a.firmwarePath(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, reason.ErrServer.SetMsg(err.Error())
+	}
+	dst, err := os.Create(path)
+	if err != nil {
+		return nil, reason.ErrServer.SetMsg(err.Error())
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, h), src)
+	dst.Close()
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, reason.ErrServer.SetMsg(err.Error())
+	}
+
+	name := in.Name
+	if name == "" {
+		name = strings.TrimSuffix(fh.Filename, filepath.Ext(fh.Filename))
+	}
+	out, err := a.gb28181Core.AddFirmware(c.Request.Context(), &gb28181.Firmware{
+		ID:           id,
+		Name:         name,
+		Version:      in.Version,
+		Manufacturer: in.Manufacturer,
+		FileName:     fh.Filename,
+		FileSize:     size,
+		Checksum:     hex.EncodeToString(h.Sum(nil)),
+	})
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (a GB28181API) delFirmware(c *gin.Context, _ *struct{}) (*gb28181.Firmware, error) {
+	out, err := a.gb28181Core.DelFirmware(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(a.firmwarePath(out.ID))
+	return out, nil
+}
+
+// downloadFirmware 设备下载固件，通过地址签名鉴权，首次下载时任务进入下载中
+func (a GB28181API) downloadFirmware(c *gin.Context) {
+	if !a.verifySignedURL(c) {
+		web.Fail(c, reason.ErrUnauthorizedToken)
+		return
+	}
+	ctx := c.Request.Context()
+	job, err := a.gb28181Core.GetUpgrade(ctx, c.Param("id"))
+	if err != nil {
+		web.Fail(c, err)
+		return
+	}
+	if job.IsDone() || job.IsExpired(time.Now()) {
+		web.Fail(c, reason.ErrUsedLogic.SetMsg("升级任务已结束"))
+		return
+	}
+	fw, err := a.gb28181Core.GetFirmware(ctx, job.FirmwareID)
+	if err != nil {
+		web.Fail(c, err)
+		return
+	}
+	if job.Status == gb28181.UpgradeStatusPending {
+		if _, err := a.gb28181Core.EditUpgrade(ctx, job.ID, func(u *gb28181.Upgrade) { u.Download() }); err != nil {
+			web.Fail(c, err)
+			return
+		}
+	}
+	c.Header("X-Checksum-Sha256", fw.Checksum)
+	c.FileAttachment(a.firmwarePath(fw.ID), fw.FileName)
+}
+
+func (a GB28181API) findUpgrade(c *gin.Context, in *gb28181.FindUpgradeInput) (any, error) {
+	items, total, err := a.gb28181Core.FindUpgrade(c.Request.Context(), in)
+	return gin.H{"items": items, "total": total}, err
+}
+
+func (a GB28181API) getUpgrade(c *gin.Context, _ *struct{}) (*gb28181.Upgrade, error) {
+	return a.gb28181Core.GetUpgrade(c.Request.Context(), c.Param("id"))
+}
+
+// cancelUpgrade 取消未结束的升级任务，设备长时间未上报结果时可手动结束
+func (a GB28181API) cancelUpgrade(c *gin.Context, _ *struct{}) (*gb28181.Upgrade, error) {
+	return a.gb28181Core.CancelUpgrade(c.Request.Context(), c.Param("id"))
+}
+
+// addUpgrade 创建升级任务并向设备下发升级命令，升级结果由设备异步通知
+func (a GB28181API) addUpgrade(c *gin.Context, in *gb28181.AddUpgradeInput) (*gb28181.Upgrade, error) {
+	ctx := c.Request.Context()
+	dev, err := a.gb28181Core.GetDevice(ctx, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	fw, err := a.gb28181Core.GetFirmware(ctx, in.FirmwareID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := a.gb28181Core.AddUpgrade(ctx, dev, fw)
+	if err != nil {
+		return nil, err
+	}
+
+	manufacturer := fw.Manufacturer
+	if manufacturer == "" {
+		manufacturer = dev.Ext.Manufacturer
+	}
+	result, err := a.uc.SipServer.DeviceControl(&gbs.DeviceControlInput{
+		DeviceID: dev.DeviceID,
+		TargetID: dev.DeviceID,
+		Cmd:      gbs.ControlUpgrade,
+		Upgrade: &gbs.DeviceUpgrade{
+			Firmware:     fw.Version,
+			FileURL:      a.signedURL("/gb28181/firmware/"+job.ID, firmwareURLExpire),
+			Manufacturer: manufacturer,
+			SessionID:    job.ID,
+		},
+	})
+	if err == nil && !strings.EqualFold(result, "OK") {
+		err = reason.ErrUsedLogic.SetMsg("设备拒绝升级")
+	}
+	if err != nil {
+		_, _ = a.gb28181Core.EditUpgrade(ctx, job.ID, func(u *gb28181.Upgrade) { u.Finish(false, err.Error()) })
+		return nil, ErrDevice.SetMsg(err.Error())
+	}
+	return job, nil
+}
@@ -3,16 +3,22 @@ package api
 import (
 	"log/slog"
 	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gowvp/gb28181/internal/conf"
+	"github.com/gowvp/gb28181/internal/core/bz"
 	"github.com/gowvp/gb28181/internal/core/gb28181"
 	"github.com/gowvp/gb28181/internal/core/push"
 	"github.com/gowvp/gb28181/internal/core/sms"
 	"github.com/gowvp/gb28181/pkg/gbs"
+	"github.com/gowvp/gb28181/pkg/zlm"
 	"github.com/ixugo/goddd/pkg/web"
 )
 
+// downloadMp4MaxSecond 下载录制的 mp4 切片时长，足够长以保证一个任务生成一个文件
+const downloadMp4MaxSecond = 24 * 60 * 60
+
 type WebHookAPI struct {
 	smsCore     sms.Core
 	mediaCore   push.Core
@@ -41,9 +47,11 @@ func registerZLMWebhookAPI(r gin.IRouter, api WebHookAPI, handler ...gin.Handler
 		group.POST("/on_stream_changed", web.WrapH(api.onStreamChanged))
 		group.POST("/on_publish", web.WrapH(api.onPublish))
 		group.POST("/on_play", web.WrapH(api.onPlay))
+		group.POST("/on_flow_report", web.WrapH(api.onFlowReport))
 		group.POST("/on_stream_none_reader", web.WrapH(api.onStreamNoneReader))
 		group.POST("/on_rtp_server_timeout", web.WrapH(api.onRTPServerTimeout))
 		group.POST("/on_stream_not_found", web.WrapH(api.onStreamNotFound))
+		group.POST("/on_record_mp4", web.WrapH(api.onRecordMP4))
 	}
 }
 
@@ -75,6 +83,14 @@ func (w WebHookAPI) onPublish(c *gin.Context, in *onPublishInput) (*onPublishOut
 			return &onPublishOutput{DefaultOutput: DefaultOutput{Code: 1, Msg: err.Error()}}, nil
 		}
 	}
+	// 国标录像下载，录制为单个 mp4 文件
+	if in.App == "rtp" && strings.HasPrefix(in.Stream, bz.IDPrefixDownload) {
+		return &onPublishOutput{
+			DefaultOutput: newDefaultOutputOK(),
+			EnableMp4:     zlm.NewBool(true),
+			Mp4MaxSecond:  zlm.NewInt(downloadMp4MaxSecond),
+		}, nil
+	}
 	return &onPublishOutput{
 		DefaultOutput: newDefaultOutputOK(),
 	}, nil
@@ -85,14 +101,29 @@ func (w WebHookAPI) onPublish(c *gin.Context, in *onPublishInput) (*onPublishOut
 func (w WebHookAPI) onStreamChanged(c *gin.Context, in *onStreamChangedInput) (DefaultOutput, error) {
 	w.log.InfoContext(c.Request.Context(), "流状态变化", "app", in.App, "stream", in.Stream, "schema", in.Schema, "mediaServerID", in.MediaServerID, "regist", in.Regist)
 	if in.App == "rtp" {
+		// 收到实时流，记住切换后的传输模式
+		if in.Schema == "rtmp" && in.Regist {
+			w.gbs.PlayStarted(in.Stream)
+		}
 		// 防止多次触发
 		if in.Schema == "rtmp" && !in.Regist {
-			ch, err := w.gb28181Core.GetChannel(c.Request.Context(), in.Stream)
+			// 下载流注销时结束会话，录制文件由 on_record_mp4 回调
+			if w.gbs.HasDownload(in.Stream) {
+				_ = w.gbs.StopDownload(in.Stream)
+				return newDefaultOutputOK(), nil
+			}
+			// 回放结束
+			if w.gbs.HasPlayback(in.Stream) {
+				_ = w.gbs.StopPlayback(in.Stream)
+				return newDefaultOutputOK(), nil
+			}
+			channelID, profile := gbs.ParseStreamID(in.Stream)
+			ch, err := w.gb28181Core.GetChannel(c.Request.Context(), channelID)
 			if err != nil {
 				w.log.Warn("获取通道失败", "err", err)
 				return newDefaultOutputOK(), nil
 			}
-			w.gbs.StopPlay(&gbs.StopPlayInput{Channel: ch})
+			w.gbs.StopPlay(&gbs.StopPlayInput{Channel: ch, StreamProfile: profile})
 		}
 		return newDefaultOutputOK(), nil
 	}
@@ -114,27 +145,22 @@ func (w WebHookAPI) onStreamChanged(c *gin.Context, in *onStreamChangedInput) (D
 // 播放rtsp流时，如果该流开启了rtsp专用认证（on_rtsp_realm），则不会触发on_play事件。
 // https://docs.zlmediakit.com/guide/media_server/web_hook_api.html#_6-on-play
 func (w WebHookAPI) onPlay(c *gin.Context, in *onPublishInput) (DefaultOutput, error) {
+	// 国标流记录观看者，同一通道的多个观看者复用一路点播
+	if in.App == "rtp" {
+		n := w.gbs.AddViewer(in.Stream, in.ID)
+		w.log.DebugContext(c.Request.Context(), "观看者接入", "stream", in.Stream, "schema", in.Schema, "viewers", n)
+	}
 	return newDefaultOutputOK(), nil
+}
 
-	switch in.Schema {
-	case "rtmp":
-		params, err := url.ParseQuery(in.Params)
-		if err != nil {
-			w.log.InfoContext(c.Request.Context(), "onPlay 鉴权失败", "err", err)
-			return DefaultOutput{Code: 1, Msg: err.Error()}, nil
-		}
-		session := params.Get("session")
-		if err := w.mediaCore.OnPlay(c.Request.Context(), push.OnPlayInput{
-			App:     in.App,
-			Stream:  in.Stream,
-			Session: session,
-		}); err != nil {
-			w.log.InfoContext(c.Request.Context(), "onPlay 鉴权失败", "err", err)
-			return DefaultOutput{Code: 1, Msg: err.Error()}, nil
-		}
-	case "rtsp":
+// onFlowReport 播放器或推流器断开时的流量统计事件，对回复不敏感
+// general.flowThreshold 设为 0，每个播放器断开都会上报
+// https://docs.zlmediakit.com/zh/guide/media_server/web_hook_api.html#_1%E3%80%81on-flow-report
+func (w WebHookAPI) onFlowReport(c *gin.Context, in *onFlowReportInput) (DefaultOutput, error) {
+	if in.App == "rtp" && in.Player {
+		n := w.gbs.RemoveViewer(in.Stream, in.ID)
+		w.log.InfoContext(c.Request.Context(), "观看者断开", "stream", in.Stream, "schema", in.Schema, "viewers", n)
 	}
-
 	return newDefaultOutputOK(), nil
 }
 
@@ -150,12 +176,26 @@ func (w WebHookAPI) onStreamNoneReader(c *gin.Context, in *onStreamNoneReaderInp
 	w.log.InfoContext(c.Request.Context(), "无人观看", "app", in.App, "stream", in.Stream, "mediaServerID", in.MediaServerID)
 
 	if in.App == "rtp" {
-		ch, err := w.gb28181Core.GetChannel(c.Request.Context(), in.Stream)
+		// 下载流由 mp4 录制消费，无人观看时不关闭
+		if w.gbs.HasDownload(in.Stream) {
+			return onStreamNoneReaderOutput{Close: false}, nil
+		}
+		if w.gbs.HasPlayback(in.Stream) {
+			_ = w.gbs.StopPlayback(in.Stream)
+			return onStreamNoneReaderOutput{Close: true}, nil
+		}
+		// 仍有观看者时保留点播，由最后一名观看者断开后的无人观看事件关闭
+		if n := w.gbs.Viewers(in.Stream); n > 0 {
+			w.log.InfoContext(c.Request.Context(), "仍有观看者，保留点播", "stream", in.Stream, "viewers", n)
+			return onStreamNoneReaderOutput{Close: false}, nil
+		}
+		channelID, profile := gbs.ParseStreamID(in.Stream)
+		ch, err := w.gb28181Core.GetChannel(c.Request.Context(), channelID)
 		if err != nil {
 			w.log.WarnContext(c.Request.Context(), "获取通道失败", "err", err)
 			return onStreamNoneReaderOutput{Close: true}, nil
 		}
-		_ = w.gbs.StopPlay(&gbs.StopPlayInput{Channel: ch})
+		_ = w.gbs.StopPlay(&gbs.StopPlayInput{Channel: ch, StreamProfile: profile})
 	}
 	// 存在录像计划时，不关闭流
 	return onStreamNoneReaderOutput{Close: true}, nil
@@ -166,6 +206,46 @@ func (w WebHookAPI) onStreamNoneReader(c *gin.Context, in *onStreamNoneReaderInp
 // https://docs.zlmediakit.com/zh/guide/media_server/web_hook_api.html#_17%E3%80%81on-rtp-server-timeout
 func (w WebHookAPI) onRTPServerTimeout(c *gin.Context, in *onRTPServerTimeoutInput) (DefaultOutput, error) {
 	w.log.InfoContext(c.Request.Context(), "rtp 收流超时", "local_port", in.LocalPort, "ssrc", in.SSRC, "stream_id", in.StreamID, "mediaServerID", in.MediaServerID)
+	if w.gbs.HasDownload(in.StreamID) {
+		_ = w.gbs.StopDownload(in.StreamID)
+		if _, err := w.gb28181Core.EditDownload(c.Request.Context(), in.StreamID, func(d *gb28181.Download) {
+			if !d.IsDone() {
+				d.Status = gb28181.DownloadStatusFailed
+				d.Error = "设备推流超时"
+			}
+		}); err != nil {
+			w.log.ErrorContext(c.Request.Context(), "EditDownload", "err", err)
+		}
+		return newDefaultOutputOK(), nil
+	}
+	// 实时点播切换传输模式重试，重新 INVITE 较慢，不阻塞回调
+	go func() {
+		if ok, err := w.gbs.PlayTimeout(in.StreamID); ok && err != nil {
+			w.log.Warn("点播失败", "stream_id", in.StreamID, "err", err)
+		}
+	}()
+	return newDefaultOutputOK(), nil
+}
+
+// onRecordMP4 录制 mp4 完成后通知事件；此事件对回复不敏感。
+// https://docs.zlmediakit.com/zh/guide/media_server/web_hook_api.html#_8%E3%80%81on-record-mp4
+func (w WebHookAPI) onRecordMP4(c *gin.Context, in *onRecordMP4Input) (DefaultOutput, error) {
+	w.log.InfoContext(c.Request.Context(), "mp4 录制完成", "app", in.App, "stream", in.Stream, "file_path", in.FilePath, "mediaServerID", in.MediaServerID)
+	if in.App != "rtp" || !strings.HasPrefix(in.Stream, bz.IDPrefixDownload) {
+		return newDefaultOutputOK(), nil
+	}
+	if _, err := w.gb28181Core.EditDownload(c.Request.Context(), in.Stream, func(d *gb28181.Download) {
+		d.FilePath = in.FilePath
+		d.FileURL = in.URL
+		d.FileSize = in.FileSize
+		// 取消的任务保留已录制的文件，状态不变
+		if d.Status == gb28181.DownloadStatusDownloading {
+			d.Status = gb28181.DownloadStatusCompleted
+			d.Progress = 100
+		}
+	}); err != nil {
+		w.log.ErrorContext(c.Request.Context(), "EditDownload", "err", err, "stream", in.Stream)
+	}
 	return newDefaultOutputOK(), nil
 }
 
@@ -178,7 +258,9 @@ func (w WebHookAPI) onStreamNotFound(c *gin.Context, in *onStreamNotFoundInput)
 		if in.Schema != "rtmp" {
 			return newDefaultOutputOK(), nil
 		}
-		ch, err := w.gb28181Core.GetChannel(c.Request.Context(), in.Stream)
+		// 子码流的流 ID 带有码流后缀
+		channelID, profile := gbs.ParseStreamID(in.Stream)
+		ch, err := w.gb28181Core.GetChannel(c.Request.Context(), channelID)
 		if err != nil {
 			// slog.Error("获取通道失败", "err", err)
 			return newDefaultOutputOK(), nil
@@ -197,9 +279,10 @@ func (w WebHookAPI) onStreamNotFound(c *gin.Context, in *onStreamNotFoundInput)
 		}
 
 		if err := w.gbs.Play(&gbs.PlayInput{
-			Channel:    ch,
-			StreamMode: dev.StreamMode,
-			SMS:        svr,
+			Channel:       ch,
+			StreamMode:    dev.StreamMode,
+			SMS:           svr,
+			StreamProfile: profile,
 		}); err != nil {
 			w.log.ErrorContext(c.Request.Context(), "play", "err", err, "channel", ch.ID)
 			return newDefaultOutputOK(), nil
@@ -202,6 +202,21 @@ type onRTPServerTimeoutInput struct {
 	MediaServerID string `json:"mediaServerId"` // 服务器 id,通过配置文件设置
 }
 
+type onFlowReportInput struct {
+	MediaServerID string `json:"mediaServerId"` // 服务器 id,通过配置文件设置
+	App           string `json:"app"`           // 流应用名
+	Duration      int    `json:"duration"`      // tcp 链接维持时间，单位秒
+	Params        string `json:"params"`        // 推流或播放 url 参数
+	Player        bool   `json:"player"`        // true 为播放器，false 为推流器
+	Schema        string `json:"schema"`        // 播放或推流的协议，可能是 rtsp、rtmp、http
+	Stream        string `json:"stream"`        // 流 ID
+	TotalBytes    int64  `json:"totalBytes"`    // 耗费上下行流量总和，单位字节
+	Vhost         string `json:"vhost"`         // 流虚拟主机
+	IP            string `json:"ip"`            // 客户端 ip
+	Port          int    `json:"port"`          // 客户端端口号
+	ID            string `json:"id"`            // TCP 链接唯一 ID
+}
+
 type onStreamNotFoundInput struct {
 	MediaServerID string `json:"mediaServerId"` // 服务器 id,通过配置文件设置
 	App           string `json:"app"`           // 流应用名
@@ -213,3 +228,17 @@ type onStreamNotFoundInput struct {
 	Stream        string `json:"stream"`        // 流 ID
 	Vhost         string `json:"vhost"`         // 流虚拟主机
 }
+
+type onRecordMP4Input struct {
+	MediaServerID string  `json:"mediaServerId"` // 服务器 id,通过配置文件设置
+	App           string  `json:"app"`           // 录制的流应用名
+	FileName      string  `json:"file_name"`     // 文件名
+	FilePath      string  `json:"file_path"`     // 文件绝对路径
+	FileSize      int64   `json:"file_size"`     // 文件大小，单位字节
+	Folder        string  `json:"folder"`        // 文件所在目录路径
+	StartTime     int64   `json:"start_time"`    // 开始录制时间戳
+	Stream        string  `json:"stream"`        // 录制的流 ID
+	TimeLen       float64 `json:"time_len"`      // 录制时长，单位秒
+	URL           string  `json:"url"`           // http/rtsp/rtmp 点播相对 url 路径
+	Vhost         string  `json:"vhost"`         // 流虚拟主机
+}
@@ -0,0 +1,137 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// AlarmNotify 报警通知 GB/T28181 A.2.5.3
+type AlarmNotify struct {
+	XMLName          xml.Name   `xml:"Notify"`
+	CmdType          string     `xml:"CmdType"`
+	SN               int        `xml:"SN"`
+	DeviceID         string     `xml:"DeviceID"`         // 报警设备编码或报警中心编码
+	AlarmPriority    string     `xml:"AlarmPriority"`    // 报警级别 1:一级警情 2:二级警情 3:三级警情 4:四级警情
+	AlarmMethod      string     `xml:"AlarmMethod"`      // 报警方式
+	AlarmTime        string     `xml:"AlarmTime"`        // 报警时间
+	AlarmDescription string     `xml:"AlarmDescription"` // 报警内容描述
+	Longitude        string     `xml:"Longitude"`        // 经度
+	Latitude         string     `xml:"Latitude"`         // 纬度
+	Info             *AlarmInfo `xml:"Info"`
+}
+
+type AlarmInfo struct {
+	AlarmType      int             `xml:"AlarmType"` // 报警类型，取值与报警方式相关
+	AlarmTypeParam *AlarmTypeParam `xml:"AlarmTypeParam"`
+}
+
+type AlarmTypeParam struct {
+	EventType int `xml:"EventType"` // 报警类型扩展参数，入侵检测时 1:进入区域 2:离开区域
+}
+
+// AlarmResponse 报警通知应答
+type AlarmResponse struct {
+	XMLName  xml.Name `xml:"Response"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	DeviceID string   `xml:"DeviceID"`
+	Result   string   `xml:"Result"`
+}
+
+// toAlarm 转换为报警记录，部分设备的报警方式为逗号分隔的多值，取首个
+func (a *AlarmNotify) toAlarm(deviceID string) *gb28181.Alarm {
+	out := gb28181.Alarm{
+		DeviceID:    deviceID,
+		ChannelID:   a.DeviceID,
+		Description: a.AlarmDescription,
+		AlarmAt:     orm.Now(),
+	}
+	out.Priority, _ = strconv.Atoi(a.AlarmPriority)
+	method, _, _ := strings.Cut(a.AlarmMethod, ",")
+	out.Method, _ = strconv.Atoi(method)
+	out.Longitude, _ = strconv.ParseFloat(a.Longitude, 64)
+	out.Latitude, _ = strconv.ParseFloat(a.Latitude, 64)
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", a.AlarmTime, time.Local); err == nil {
+		out.AlarmAt = orm.Time{Time: t}
+	}
+	if a.Info != nil {
+		out.Type = a.Info.AlarmType
+		if a.Info.AlarmTypeParam != nil {
+			out.EventType = a.Info.AlarmTypeParam.EventType
+		}
+	}
+	return &out
+}
+
+// sipMessageAlarm 报警通知，设备可能通过 MESSAGE 或 NOTIFY 上报
+// GB/T28181 9.4
+func (g *GB28181API) sipMessageAlarm(ctx *sip.Context) {
+	var msg AlarmNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageAlarm", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	alarm := msg.toAlarm(ctx.DeviceID)
+	ctx.Log.Info("收到报警", "channelID", alarm.ChannelID, "method", alarm.Method, "type", alarm.Type, "priority", alarm.Priority)
+	if err := g.core.SaveAlarm(alarm); err != nil {
+		ctx.Log.Error("SaveAlarm", "err", err)
+	}
+
+	// 报警通知应答
+	ipc, ok := g.svr.memoryStorer.Load(ctx.DeviceID)
+	if !ok {
+		return
+	}
+	body, _ := sip.XMLEncode(AlarmResponse{
+		CmdType:  "Alarm",
+		SN:       msg.SN,
+		DeviceID: msg.DeviceID,
+		Result:   "OK",
+	})
+	if _, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, body); err != nil {
+		ctx.Log.Warn("alarm response", "err", err)
+	}
+}
+
+type ResetAlarmInput struct {
+	DeviceID  string // 设备国标编码
+	ChannelID string // 报警源国标编码，为空时复位整个设备
+	Method    int    // 报警方式，为 0 时复位全部
+	Type      int    // 报警类型
+}
+
+// ResetAlarm 报警复位
+// GB/T28181 A.2.3.1.6
+func (g *GB28181API) ResetAlarm(in *ResetAlarmInput) error {
+	ipc, ok := g.svr.memoryStorer.Load(in.DeviceID)
+	if !ok {
+		return ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return ErrDeviceOffline
+	}
+	target := in.DeviceID
+	if in.ChannelID != "" {
+		target = in.ChannelID
+	}
+	slog.Debug("ResetAlarm", "deviceID", in.DeviceID, "target", target, "method", in.Method, "type", in.Type)
+
+	body := NewDeviceControl(target).SetResetAlarm(in.Method, in.Type).Marshal()
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
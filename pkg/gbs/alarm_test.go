@@ -0,0 +1,39 @@
+package gbs
+
+import (
+	"testing"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+func TestAlarmNotify(t *testing.T) {
+	body := `<?xml version="1.0" encoding="GB2312"?>
+<Notify>
+<CmdType>Alarm</CmdType>
+<SN>17</SN>
+<DeviceID>34020000001340000001</DeviceID>
+<AlarmPriority>1</AlarmPriority>
+<AlarmMethod>5</AlarmMethod>
+<AlarmTime>2024-05-01T10:20:30</AlarmTime>
+<Longitude>116.3</Longitude>
+<Latitude>39.9</Latitude>
+<Info>
+<AlarmType>6</AlarmType>
+<AlarmTypeParam><EventType>1</EventType></AlarmTypeParam>
+</Info>
+</Notify>`
+	var msg AlarmNotify
+	if err := sip.XMLDecode([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	alarm := msg.toAlarm("34020000001110000001")
+	if alarm.ChannelID != "34020000001340000001" || alarm.Priority != 1 || alarm.Method != 5 || alarm.Type != 6 || alarm.EventType != 1 {
+		t.Fatalf("unexpected alarm %+v", alarm)
+	}
+	if alarm.AlarmAt.Format("2006-01-02 15:04:05") != "2024-05-01 10:20:30" {
+		t.Fatalf("unexpected alarm time %s", alarm.AlarmAt)
+	}
+	if alarm.Longitude != 116.3 || alarm.Latitude != 39.9 {
+		t.Fatalf("unexpected position %f,%f", alarm.Longitude, alarm.Latitude)
+	}
+}
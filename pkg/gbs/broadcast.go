@@ -0,0 +1,445 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/gowvp/gb28181/pkg/zlm"
+	sdp "github.com/panjjo/gosdp"
+)
+
+// BroadcastNotify 语音广播通知 GB/T28181 A.2.5.7
+type BroadcastNotify struct {
+	XMLName  xml.Name `xml:"Notify"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	SourceID string   `xml:"SourceID"` // 语音输入设备编码，即本平台编码
+	TargetID string   `xml:"TargetID"` // 语音输出设备编码
+}
+
+// BroadcastResponse 语音广播应答
+type BroadcastResponse struct {
+	CmdType  string `xml:"CmdType"`
+	SN       int    `xml:"SN"`
+	DeviceID string `xml:"DeviceID"`
+	Result   string `xml:"Result"`
+}
+
+type BroadcastInput struct {
+	Channel *gb28181.Channel
+	SMS     *sms.MediaServer
+	App     string // 浏览器推送到流媒体的音频流
+	Stream  string
+}
+
+// broadcastSession 语音广播会话，设备收到广播通知后主动 INVITE 平台，平台应答后由流媒体向设备推送音频
+type broadcastSession struct {
+	mu        sync.Mutex
+	deviceID  string
+	channelID string
+	sms       *sms.MediaServer
+	app       string
+	stream    string
+	ssrc      string
+	invite    *sip.Request  // 设备发起的 INVITE
+	answer    *sip.Response // 平台的 200 应答，用于会话内 BYE
+	ready     chan error    // INVITE 处理结果
+}
+
+// done 通知 INVITE 处理结果，广播已超时或已被拒绝时丢弃
+func (s *broadcastSession) done(err error) {
+	select {
+	case s.ready <- err:
+	default:
+	}
+}
+
+func broadcastKey(deviceID, channelID string) string {
+	return deviceID + ":" + channelID
+}
+
+// Broadcast 语音广播，阻塞至设备 INVITE 完成
+// GB/T28181 9.12
+func (g *GB28181API) Broadcast(in *BroadcastInput) error {
+	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+	if !ch.device.IsOnline {
+		return ErrDeviceOffline
+	}
+
+	// 重复广播时结束旧会话，设备同一时刻仅接受一路广播
+	if err := g.StopBroadcast(in.Channel.DeviceID, in.Channel.ChannelID); err != nil {
+		slog.Warn("StopBroadcast", "err", err)
+	}
+
+	key := broadcastKey(in.Channel.DeviceID, in.Channel.ChannelID)
+	sess := broadcastSession{
+		deviceID:  in.Channel.DeviceID,
+		channelID: in.Channel.ChannelID,
+		sms:       in.SMS,
+		app:       in.App,
+		stream:    in.Stream,
+		ready:     make(chan error, 1),
+	}
+	g.broadcasts.Store(key, &sess)
+
+	body, _ := sip.XMLEncode(BroadcastNotify{
+		CmdType:  "Broadcast",
+		SN:       sip.RandInt(100000, 999999),
+		SourceID: g.cfg.ID,
+		TargetID: in.Channel.ChannelID,
+	})
+	tx, err := g.svr.wrapRequest(ch.device, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err == nil {
+		_, err = sipResponse(tx)
+	}
+	if err == nil {
+		select {
+		case err = <-sess.ready:
+		case <-time.After(10 * time.Second):
+			err = ErrBroadcastTimeout
+		}
+	}
+	if err != nil {
+		g.broadcasts.CompareAndDelete(key, &sess)
+		return err
+	}
+	return nil
+}
+
+// StopBroadcast 停止语音广播
+func (g *GB28181API) StopBroadcast(deviceID, channelID string) error {
+	sess, ok := g.broadcasts.LoadAndDelete(broadcastKey(deviceID, channelID))
+	if !ok {
+		return nil
+	}
+	return g.closeBroadcast(sess, true)
+}
+
+// closeBroadcast 停止推流，bye 为 true 时通知设备挂断
+func (g *GB28181API) closeBroadcast(sess *broadcastSession, bye bool) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.invite == nil {
+		return nil
+	}
+	g.stopBroadcastRTP(sess, sess.ssrc)
+	if !bye {
+		return nil
+	}
+	req := sip.NewRequestFromInvite(sip.MethodBYE, sess.invite, sess.answer, 1)
+	_, err := g.svr.Request(req)
+	return err
+}
+
+// stopBroadcasts 设备离线时清理广播会话
+func (g *GB28181API) stopBroadcasts(deviceID string) {
+	g.broadcasts.Range(func(key string, sess *broadcastSession) bool {
+		if sess.deviceID == deviceID {
+			g.broadcasts.Delete(key)
+			_ = g.closeBroadcast(sess, false)
+		}
+		return true
+	})
+}
+
+// findBroadcast 查找设备 INVITE 对应的广播会话
+// 相同 Call-ID 的 INVITE 属于已建立的会话；新会话按语音输出通道编码匹配，
+// 仅能确定设备编码时，只在该设备只有一路等待中的广播时匹配
+func (g *GB28181API) findBroadcast(callID, deviceID, channelID string) *broadcastSession {
+	if _, sess := g.findBroadcastByCallID(callID); sess != nil {
+		return sess
+	}
+	var (
+		byChannel *broadcastSession
+		byDevice  []*broadcastSession
+	)
+	g.broadcasts.Range(func(_ string, sess *broadcastSession) bool {
+		sess.mu.Lock()
+		invited := sess.invite != nil
+		sess.mu.Unlock()
+		if invited {
+			return true
+		}
+		// 设备可能以语音输出通道编码作为 From 发起 INVITE
+		if sess.channelID == channelID || sess.channelID == deviceID {
+			byChannel = sess
+			return false
+		}
+		if sess.deviceID == deviceID {
+			byDevice = append(byDevice, sess)
+		}
+		return true
+	})
+	if byChannel != nil {
+		return byChannel
+	}
+	if len(byDevice) == 1 {
+		return byDevice[0]
+	}
+	return nil
+}
+
+// findBroadcastByCallID 查找已建立的广播会话
+func (g *GB28181API) findBroadcastByCallID(callID string) (string, *broadcastSession) {
+	var (
+		key string
+		out *broadcastSession
+	)
+	g.broadcasts.Range(func(k string, sess *broadcastSession) bool {
+		sess.mu.Lock()
+		invite := sess.invite
+		sess.mu.Unlock()
+		if invite == nil {
+			return true
+		}
+		if v, ok := invite.CallID(); ok && string(*v) == callID {
+			key, out = k, sess
+			return false
+		}
+		return true
+	})
+	return key, out
+}
+
+// sipMessageBroadcast 语音广播应答
+func (g *GB28181API) sipMessageBroadcast(ctx *sip.Context) {
+	var msg BroadcastResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageBroadcast", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	if strings.EqualFold(msg.Result, "OK") {
+		return
+	}
+	if sess, ok := g.broadcasts.Load(broadcastKey(ctx.DeviceID, msg.DeviceID)); ok {
+		sess.done(ErrBroadcastRefused)
+	}
+}
+
+// sipInvite 设备收到广播通知后发起的 INVITE，SDP 中携带设备的音频接收地址
+func (g *GB28181API) sipInvite(ctx *sip.Context) {
+	offer, err := decodeSDP(ctx.Request.Body())
+	if err != nil {
+		ctx.Log.Error("sipInvite", "err", err, "body", string(ctx.Request.Body()))
+		ctx.String(http.StatusBadRequest, "sdp decode error")
+		return
+	}
+	var callID string
+	if v, ok := ctx.Request.CallID(); ok {
+		callID = string(*v)
+	}
+	sess := g.findBroadcast(callID, ctx.DeviceID, offer.Origin.Username)
+	if sess == nil {
+		ctx.Log.Warn("未找到广播会话", "origin", offer.Origin.Username)
+		ctx.String(http.StatusNotFound, "broadcast not found")
+		return
+	}
+
+	// 会话内的重复 INVITE 沿用已协商的 SDP
+	sess.mu.Lock()
+	prev := sess.answer
+	sess.mu.Unlock()
+	if prev != nil {
+		if _, err := g.answerInvite(ctx, prev.Body()); err != nil {
+			ctx.Log.Error("answerInvite", "err", err)
+		}
+		return
+	}
+
+	answer, ssrc, err := g.startBroadcastRTP(sess, offer)
+	if err != nil {
+		ctx.Log.Error("startBroadcastRTP", "err", err)
+		ctx.String(488, "Not Acceptable Here")
+		sess.done(err)
+		return
+	}
+
+	resp, err := g.answerInvite(ctx, answer)
+	if err != nil {
+		// 应答失败设备不会收流，停止已启动的推流
+		g.stopBroadcastRTP(sess, ssrc)
+		sess.done(err)
+		return
+	}
+
+	sess.mu.Lock()
+	sess.invite = ctx.Request
+	sess.answer = resp
+	sess.ssrc = ssrc
+	sess.mu.Unlock()
+	sess.done(nil)
+	ctx.Log.Info("语音广播已建立", "channelID", sess.channelID, "ssrc", ssrc)
+}
+
+// answerInvite 以 SDP 应答 INVITE，返回的应答用于会话内 BYE
+func (g *GB28181API) answerInvite(ctx *sip.Context, answer []byte) (*sip.Response, error) {
+	resp := sip.NewResponseFromRequest("", ctx.Request, http.StatusOK, "OK", answer)
+	if to, ok := resp.To(); ok {
+		if to.Params == nil {
+			to.Params = sip.NewParams()
+		}
+		if !to.Params.Has("tag") {
+			to.Params.Add("tag", sip.String{Str: sip.RandString(32)})
+		}
+	}
+	resp.AppendHeader(&sip.ContactHeader{Address: g.svr.fromAddress.URI, Params: sip.NewParams()})
+	resp.AppendHeader(&sip.ContentTypeSDP)
+	if err := ctx.Tx.Respond(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// stopBroadcastRTP 停止向设备推流
+func (g *GB28181API) stopBroadcastRTP(sess *broadcastSession, ssrc string) {
+	if err := g.sms.StopSendRTP(sess.sms, zlm.StopSendRTPRequest{
+		Vhost:  "__defaultVhost__",
+		App:    sess.app,
+		Stream: sess.stream,
+		SSRC:   ssrc,
+	}); err != nil {
+		slog.Warn("StopSendRTP", "err", err, "stream", sess.stream)
+	}
+}
+
+// startBroadcastRTP 按设备 SDP 启动流媒体推流，返回平台应答的 SDP
+func (g *GB28181API) startBroadcastRTP(sess *broadcastSession, offer *sdp.Message) ([]byte, string, error) {
+	var audio *sdp.Media
+	for i := range offer.Medias {
+		if offer.Medias[i].Description.Type == "audio" {
+			audio = &offer.Medias[i]
+			break
+		}
+	}
+	if audio == nil || len(audio.Description.Formats) == 0 {
+		return nil, "", fmt.Errorf("sdp without audio media")
+	}
+
+	dstIP := audio.Connection.IP
+	if dstIP == nil {
+		dstIP = offer.Connection.IP
+	}
+	if dstIP == nil {
+		return nil, "", fmt.Errorf("sdp without connection address")
+	}
+	pt := audio.Description.Formats[0]
+	rtpmap := audio.PayloadFormat(pt)
+	if rtpmap == "" {
+		rtpmap = "PCMA/8000"
+	}
+	ptNum, _ := strconv.Atoi(pt)
+	// 负载为 PS 时封装后发送，否则直接发送 G.711 等音频裸流
+	usePS := 0
+	if strings.HasPrefix(strings.ToUpper(rtpmap), "PS") {
+		usePS = 1
+	}
+	protocol := audio.Description.Protocol
+	isUDP := !strings.Contains(strings.ToUpper(protocol), "TCP")
+	ssrc := offer.SSRC
+	if ssrc == "" {
+		ssrc = g.ssrcs.Next(false)
+	}
+
+	resp, err := g.sms.StartSendRTP(sess.sms, zlm.StartSendRTPRequest{
+		Vhost:     "__defaultVhost__",
+		App:       sess.app,
+		Stream:    sess.stream,
+		SSRC:      ssrc,
+		DstURL:    dstIP.String(),
+		DstPort:   audio.Description.Port,
+		IsUDP:     isUDP,
+		PT:        ptNum,
+		UsePS:     zlm.NewInt(usePS),
+		OnlyAudio: zlm.NewBool(true),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	ip, err := GetIP(sess.sms.GetSDPIP())
+	if err != nil {
+		g.stopBroadcastRTP(sess, ssrc)
+		return nil, "", err
+	}
+	media := sdp.Media{
+		Description: sdp.MediaDescription{
+			Type:     "audio",
+			Port:     resp.LocalPort,
+			Formats:  []string{pt},
+			Protocol: protocol,
+		},
+	}
+	media.AddAttribute("sendonly")
+	if !isUDP {
+		// 流媒体主动连接设备
+		media.AddAttribute("setup", "active")
+		media.AddAttribute("connection", "new")
+	}
+	media.AddAttribute("rtpmap", pt, rtpmap)
+	msg := sdp.Message{
+		Origin: sdp.Origin{
+			Username:    g.cfg.ID,
+			NetworkType: "IN",
+			AddressType: "IP4",
+			Address:     ip,
+		},
+		Name: "Play",
+		Connection: sdp.ConnectionData{
+			NetworkType: "IN",
+			AddressType: "IP4",
+			IP:          net.ParseIP(ip),
+		},
+		Timing: []sdp.Timing{{}},
+		Medias: []sdp.Media{media},
+		SSRC:   ssrc,
+	}
+	return msg.Append(nil).AppendTo(nil), ssrc, nil
+}
+
+// sipAck INVITE 2xx 的 ACK，无需应答
+func (g *GB28181API) sipAck(*sip.Context) {}
+
+// sipBye 设备主动挂断，对话不存在时应答 481
+func (g *GB28181API) sipBye(ctx *sip.Context) {
+	v, ok := ctx.Request.CallID()
+	if !ok {
+		ctx.String(http.StatusBadRequest, "missing call-id")
+		return
+	}
+	callID := string(*v)
+	if key, sess := g.findBroadcastByCallID(callID); sess != nil {
+		ctx.String(http.StatusOK, "OK")
+		g.broadcasts.Delete(key)
+		_ = g.closeBroadcast(sess, false)
+		ctx.Log.Info("设备结束语音广播", "channelID", sess.channelID)
+		return
+	}
+	// 点播、回放会话由设备挂断时，流由收流超时或媒体通知清理
+	var found bool
+	g.streams.Range(func(_ string, stream *Streams) bool {
+		found = stream.CallID == callID
+		return !found
+	})
+	if found {
+		ctx.String(http.StatusOK, "OK")
+		return
+	}
+	ctx.String(481, "Call/Transaction Does Not Exist")
+}
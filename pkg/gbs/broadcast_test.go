@@ -0,0 +1,37 @@
+package gbs
+
+import (
+	"testing"
+
+	"github.com/ixugo/goddd/pkg/conc"
+)
+
+func TestFindBroadcast(t *testing.T) {
+	g := GB28181API{broadcasts: &conc.Map[string, *broadcastSession]{}}
+	const dev = "34020000001320000001"
+	a := broadcastSession{deviceID: dev, channelID: "34020000001370000001"}
+	g.broadcasts.Store(broadcastKey(a.deviceID, a.channelID), &a)
+
+	if sess := g.findBroadcast("c1", dev, a.channelID); sess != &a {
+		t.Fatal("expect match by channel")
+	}
+	if sess := g.findBroadcast("c1", a.channelID, ""); sess != &a {
+		t.Fatal("expect match by channel as from")
+	}
+	if sess := g.findBroadcast("c1", dev, ""); sess != &a {
+		t.Fatal("expect match by the only pending broadcast of device")
+	}
+	if sess := g.findBroadcast("c1", "34020000001320000002", ""); sess != nil {
+		t.Fatal("expect no match for other device")
+	}
+
+	// 同一设备多路广播时无法仅凭设备编码区分
+	b := broadcastSession{deviceID: dev, channelID: "34020000001370000002"}
+	g.broadcasts.Store(broadcastKey(b.deviceID, b.channelID), &b)
+	if sess := g.findBroadcast("c1", dev, ""); sess != nil {
+		t.Fatal("expect no match when ambiguous")
+	}
+	if sess := g.findBroadcast("c1", dev, b.channelID); sess != &b {
+		t.Fatal("expect match by channel when ambiguous")
+	}
+}
@@ -0,0 +1,894 @@
+package gbs
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/gowvp/gb28181/pkg/zlm"
+	"github.com/ixugo/goddd/pkg/orm"
+	sdp "github.com/panjjo/gosdp"
+)
+
+const (
+	// cascadeRetryInterval 注册失败后的重试间隔
+	cascadeRetryInterval = 30 * time.Second
+	// cascadeMaxKeepaliveFailures 连续心跳失败次数，超过后重新注册
+	cascadeMaxKeepaliveFailures = 3
+	// cascadeCatalogBatch 目录应答每条消息携带的条目数，避免 UDP 报文过大
+	cascadeCatalogBatch = 5
+)
+
+// CascadeSource 级联共享通道的流来源，由上层注入
+type CascadeSource interface {
+	// OpenStream 确保通道流在流媒体上就绪，返回流所在的流媒体与 app/stream
+	OpenStream(ctx context.Context, channelID string) (*sms.MediaServer, string, string, error)
+}
+
+// cascadeClient 本平台作为下级向上级平台注册的客户端
+type cascadeClient struct {
+	platform *gb28181.Platform
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	mu     sync.Mutex
+	conn   sip.Connection
+	source net.Addr
+	to     *sip.Address
+	callID sip.CallID
+	cseq   uint32
+}
+
+var _ Targeter = &cascadeClient{}
+
+// Conn implements Targeter.
+func (c *cascadeClient) Conn() sip.Connection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// Source implements Targeter.
+func (c *cascadeClient) Source() net.Addr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
+}
+
+// To implements Targeter.
+func (c *cascadeClient) To() *sip.Address {
+	return c.to
+}
+
+// isTCP 信令是否使用 tcp
+func (c *cascadeClient) isTCP() bool {
+	return c.platform.Transport == "tcp"
+}
+
+// viaTransport 按平台信令协议设置 Via
+func (c *cascadeClient) viaTransport(req *sip.Request) {
+	if via, ok := req.ViaHop(); ok && c.isTCP() {
+		via.Transport = "TCP"
+	}
+}
+
+// dial 准备与上级的连接，udp 复用本地监听端口，tcp 主动建立连接并复用服务端的读取流程
+func (c *cascadeClient) dial(s *Server) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return nil
+	}
+	addr := net.JoinHostPort(c.platform.IP, strconv.Itoa(c.platform.Port))
+	if !c.isTCP() {
+		udpAddr, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			return err
+		}
+		c.conn = s.UDPConn()
+		c.source = udpAddr
+		return nil
+	}
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	go s.ProcessTcpConn(conn)
+	c.conn = sip.NewTCPConnection(conn)
+	c.source = conn.RemoteAddr()
+	return nil
+}
+
+// resetConn 连接异常时丢弃，下次注册重新建立
+func (c *cascadeClient) resetConn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil && c.conn.Network() == "tcp" {
+		_ = c.conn.Close()
+	}
+	c.conn = nil
+}
+
+func newCascadeClient(p *gb28181.Platform) (*cascadeClient, error) {
+	uri, err := sip.ParseURI(fmt.Sprintf("sip:%s@%s", p.ServerID, net.JoinHostPort(p.IP, strconv.Itoa(p.Port))))
+	if err != nil {
+		return nil, err
+	}
+	return &cascadeClient{
+		platform: p,
+		done:     make(chan struct{}),
+		to:       &sip.Address{URI: uri, Params: sip.NewParams()},
+		callID:   sip.CallID(sip.RandString(32)),
+	}, nil
+}
+
+// cascadeCall 上级平台的点播会话
+type cascadeCall struct {
+	platformID string
+	channelID  string
+	sms        *sms.MediaServer
+	app        string
+	stream     string
+	ssrc       string
+}
+
+// SetCascadeSource 设置级联共享通道的流来源
+func (g *GB28181API) SetCascadeSource(src CascadeSource) {
+	g.source = src
+}
+
+// startCascades 服务启动时注册已启用的上级平台
+func (g *GB28181API) startCascades() {
+	platforms, err := g.core.FindEnabledPlatforms(context.Background())
+	if err != nil {
+		slog.Error("FindEnabledPlatforms", "err", err)
+		return
+	}
+	for _, p := range platforms {
+		g.StartCascade(p)
+	}
+}
+
+// StartCascade 启动向上级平台的注册，已存在时先注销，平台未启用时仅注销
+func (g *GB28181API) StartCascade(p *gb28181.Platform) {
+	g.StopCascade(p.ID)
+	if !p.Enabled {
+		return
+	}
+	c, err := newCascadeClient(p)
+	if err != nil {
+		slog.Error("newCascadeClient", "err", err, "platform", p.ID)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	g.cascades.Store(p.ID, c)
+	go g.runCascade(ctx, c)
+}
+
+// StopCascade 注销并停止向上级平台的注册，结束该平台的点播会话
+func (g *GB28181API) StopCascade(platformID string) {
+	c, ok := g.cascades.LoadAndDelete(platformID)
+	if !ok {
+		return
+	}
+	c.cancel()
+	<-c.done
+	c.resetConn()
+
+	g.cascadeCalls.Range(func(key string, call *cascadeCall) bool {
+		if call.platformID == platformID {
+			g.cascadeCalls.Delete(key)
+			g.stopCascadeCall(call)
+		}
+		return true
+	})
+}
+
+// findCascade 按上级平台编码查找注册中的客户端
+func (g *GB28181API) findCascade(serverID string) *cascadeClient {
+	var out *cascadeClient
+	g.cascades.Range(func(_ string, c *cascadeClient) bool {
+		if c.platform.ServerID == serverID {
+			out = c
+			return false
+		}
+		return true
+	})
+	return out
+}
+
+// runCascade 注册、心跳、到期前刷新注册，失败后间隔重试
+func (g *GB28181API) runCascade(ctx context.Context, c *cascadeClient) {
+	defer close(c.done)
+	log := slog.With("platform", c.platform.ID, "serverID", c.platform.ServerID)
+
+	var wait time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait = cascadeRetryInterval
+
+		if err := g.cascadeRegister(c, c.platform.Expires); err != nil {
+			log.Warn("向上级平台注册失败", "err", err)
+			c.resetConn()
+			g.editCascadeStatus(c, false, err)
+			continue
+		}
+		log.Info("向上级平台注册成功")
+		g.editCascadeStatus(c, true, nil)
+
+		if err := g.cascadeKeepalive(ctx, c); err != nil {
+			log.Warn("上级平台心跳失败", "err", err)
+			c.resetConn()
+			g.editCascadeStatus(c, false, err)
+			continue
+		}
+		if ctx.Err() != nil {
+			if err := g.cascadeRegister(c, 0); err != nil {
+				log.Warn("向上级平台注销失败", "err", err)
+			}
+			g.editCascadeStatus(c, false, nil)
+			return
+		}
+		// 注册即将到期，立即刷新
+		wait = 0
+	}
+}
+
+// cascadeKeepalive 周期发送心跳，注册即将到期或服务停止时返回 nil，连续失败时返回错误
+func (g *GB28181API) cascadeKeepalive(ctx context.Context, c *cascadeClient) error {
+	p := c.platform
+	ticker := time.NewTicker(time.Duration(p.KeepaliveInterval) * time.Second)
+	defer ticker.Stop()
+	// 有效期的 4/5 时刷新注册
+	refresh := time.NewTimer(time.Duration(p.Expires) * time.Second * 4 / 5)
+	defer refresh.Stop()
+
+	var failures int
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-refresh.C:
+			return nil
+		case <-ticker.C:
+			if err := g.sendCascadeKeepalive(c); err != nil {
+				failures++
+				if failures >= cascadeMaxKeepaliveFailures {
+					return err
+				}
+				continue
+			}
+			failures = 0
+			if err := g.core.EditPlatform(p.ID, func(p *gb28181.Platform) {
+				p.KeepaliveAt = orm.Now()
+			}); err != nil {
+				slog.Error("EditPlatform", "err", err, "platform", p.ID)
+			}
+		}
+	}
+}
+
+// editCascadeStatus 更新注册状态
+func (g *GB28181API) editCascadeStatus(c *cascadeClient, online bool, cause error) {
+	if err := g.core.EditPlatform(c.platform.ID, func(p *gb28181.Platform) {
+		p.IsOnline = online
+		p.Error = ""
+		if cause != nil {
+			p.Error = cause.Error()
+		}
+		if online {
+			p.RegisteredAt = orm.Now()
+			p.KeepaliveAt = orm.Now()
+		}
+	}); err != nil {
+		slog.Error("EditPlatform", "err", err, "platform", c.platform.ID)
+	}
+}
+
+// cascadeRegister 向上级注册，expires 为 0 时注销，收到 401 质询后携带摘要认证重试
+// GB/T28181 9.1.2
+func (g *GB28181API) cascadeRegister(c *cascadeClient, expires int) error {
+	if err := c.dial(g.svr); err != nil {
+		return err
+	}
+
+	resp, err := g.sendCascadeRegister(c, expires, "")
+	if err != nil {
+		return err
+	}
+	if code := resp.StatusCode(); code == http.StatusUnauthorized || code == http.StatusProxyAuthRequired {
+		name := "WWW-Authenticate"
+		if code == http.StatusProxyAuthRequired {
+			name = "Proxy-Authenticate"
+		}
+		hdrs := resp.GetHeaders(name)
+		if len(hdrs) == 0 {
+			return fmt.Errorf("missing %s header", name)
+		}
+		challenge, ok := hdrs[0].(*sip.GenericHeader)
+		if !ok {
+			return fmt.Errorf("invalid %s header", name)
+		}
+		username := c.platform.Username
+		if username == "" {
+			username = g.cfg.ID
+		}
+		auth := sip.AuthFromValue(challenge.Contents).
+			SetUsername(username).
+			SetPassword(c.platform.Password).
+			SetMethod(sip.MethodRegister).
+			SetURI(c.to.URI.String()).
+			SetCNonce("00000001", sip.RandString(16))
+		auth.CalcResponse()
+
+		if resp, err = g.sendCascadeRegister(c, expires, auth.String()); err != nil {
+			return err
+		}
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("register: %d %s", resp.StatusCode(), resp.Reason())
+	}
+	return nil
+}
+
+// sendCascadeRegister 发送 REGISTER，同一客户端复用 Call-ID，CSeq 递增
+func (g *GB28181API) sendCascadeRegister(c *cascadeClient, expires int, authorization string) (*sip.Response, error) {
+	c.mu.Lock()
+	c.cseq++
+	seq := c.cseq
+	conn, source := c.conn, c.source
+	c.mu.Unlock()
+
+	from := g.svr.fromAddress
+	hb := sip.NewHeaderBuilder().
+		SetTo(&from).
+		SetFrom(&from).
+		SetContact(&from).
+		SetMethod(sip.MethodRegister).
+		SetSeqNo(uint(seq)).
+		SetCallID(&c.callID).
+		AddVia(&sip.ViaHop{
+			Params: sip.NewParams().Add("branch", sip.String{Str: sip.GenerateBranch()}),
+		})
+	req := sip.NewRequest("", sip.MethodRegister, c.to.URI, sip.DefaultSipVersion, hb.Build(), nil)
+	e := sip.Expires(expires)
+	req.AppendHeader(&e)
+	if authorization != "" {
+		req.AppendHeader(&sip.GenericHeader{HeaderName: "Authorization", Contents: authorization})
+	}
+	req.SetConnection(conn)
+	req.SetSource(source)
+	req.SetDestination(source)
+	c.viaTransport(req)
+
+	tx, err := g.svr.Request(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := waitResponse(tx, 10*time.Second)
+	if resp == nil {
+		return nil, ErrCascadeTimeout
+	}
+	return resp, nil
+}
+
+// waitResponse 等待最终应答，超时返回 nil
+func waitResponse(tx *sip.Transaction, timeout time.Duration) *sip.Response {
+	ch := make(chan *sip.Response, 1)
+	go func() {
+		ch <- tx.GetResponse()
+	}()
+	select {
+	case resp := <-ch:
+		return resp
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// CascadeKeepalive 向上级发送的心跳
+type CascadeKeepalive struct {
+	XMLName  xml.Name `xml:"Notify"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	DeviceID string   `xml:"DeviceID"`
+	Status   string   `xml:"Status"`
+}
+
+// sendCascadeKeepalive 发送心跳
+// GB/T28181 9.6
+func (g *GB28181API) sendCascadeKeepalive(c *cascadeClient) error {
+	body, err := sip.XMLEncode(CascadeKeepalive{
+		CmdType:  "Keepalive",
+		SN:       sip.RandInt(100000, 999999),
+		DeviceID: g.cfg.ID,
+		Status:   "OK",
+	})
+	if err != nil {
+		return err
+	}
+	return g.sendCascadeMessage(c, body)
+}
+
+// sendCascadeMessage 向上级发送 MESSAGE 并等待应答
+func (g *GB28181API) sendCascadeMessage(c *cascadeClient, body []byte) error {
+	tx, err := g.svr.wrapRequest(c, sip.MethodMessage, &sip.ContentTypeXML, body, c.viaTransport)
+	if err != nil {
+		return err
+	}
+	resp := waitResponse(tx, 10*time.Second)
+	if resp == nil {
+		return ErrCascadeTimeout
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return fmt.Errorf("message: %d %s", resp.StatusCode(), resp.Reason())
+	}
+	return nil
+}
+
+// CascadeQuery 上级平台的查询请求
+type CascadeQuery struct {
+	CmdType  string `xml:"CmdType"`
+	SN       int    `xml:"SN"`
+	DeviceID string `xml:"DeviceID"`
+}
+
+// cascadeMessage 处理上级平台的查询，非上级平台的请求交由后续处理
+func (g *GB28181API) cascadeMessage(ctx *sip.Context) {
+	c := g.findCascade(ctx.DeviceID)
+	if c == nil {
+		return
+	}
+	ctx.Abort()
+
+	var msg CascadeQuery
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("cascadeMessage", "err", err)
+		ctx.String(http.StatusBadRequest, ErrXMLDecode.Error())
+		return
+	}
+
+	var reply func() error
+	switch msg.CmdType {
+	case "Catalog":
+		reply = func() error { return g.cascadeCatalog(c, msg.SN) }
+	case "DeviceInfo":
+		reply = func() error { return g.cascadeDeviceInfo(c, &msg) }
+	case "DeviceStatus":
+		reply = func() error { return g.cascadeDeviceStatus(c, &msg) }
+	default:
+		ctx.String(http.StatusNotImplemented, "Not Implemented")
+		return
+	}
+	ctx.String(http.StatusOK, "OK")
+	go func() {
+		if err := reply(); err != nil {
+			ctx.Log.Error("应答上级平台查询失败", "cmdType", msg.CmdType, "err", err)
+		}
+	}()
+}
+
+// CascadeCatalogItem 向上级上报的目录项
+type CascadeCatalogItem struct {
+	DeviceID     string  `xml:"DeviceID"`
+	Name         string  `xml:"Name"`
+	Manufacturer string  `xml:"Manufacturer"`
+	Model        string  `xml:"Model"`
+	Owner        string  `xml:"Owner"`
+	CivilCode    string  `xml:"CivilCode"`
+	Address      string  `xml:"Address"`
+	Parental     int     `xml:"Parental"`
+	ParentID     string  `xml:"ParentID"`
+	SafetyWay    int     `xml:"SafetyWay"`
+	RegisterWay  int     `xml:"RegisterWay"`
+	Secrecy      int     `xml:"Secrecy"`
+	Status       string  `xml:"Status"`
+	Longitude    float64 `xml:"Longitude,omitempty"`
+	Latitude     float64 `xml:"Latitude,omitempty"`
+}
+
+// CascadeCatalogResponse 向上级应答的目录
+// GB/T28181 A.2.6.4
+type CascadeCatalogResponse struct {
+	XMLName    xml.Name `xml:"Response"`
+	CmdType    string   `xml:"CmdType"`
+	SN         int      `xml:"SN"`
+	DeviceID   string   `xml:"DeviceID"`
+	SumNum     int      `xml:"SumNum"`
+	DeviceList struct {
+		Num   int                  `xml:"Num,attr"`
+		Items []CascadeCatalogItem `xml:"Item"`
+	} `xml:"DeviceList"`
+}
+
+// cascadeCatalogItems 生成共享通道目录，配置了虚拟目录时首项为目录节点
+func (g *GB28181API) cascadeCatalogItems(p *gb28181.Platform) ([]CascadeCatalogItem, error) {
+	shares, channels, err := g.core.FindPlatformChannels(context.Background(), p.ID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]CascadeCatalogItem, 0, len(shares)+1)
+	parentID := g.cfg.ID
+	if p.CatalogID != "" {
+		parentID = p.CatalogID
+		name := p.CatalogName
+		if name == "" {
+			name = p.CatalogID
+		}
+		items = append(items, CascadeCatalogItem{
+			DeviceID:  p.CatalogID,
+			Name:      name,
+			ParentID:  g.cfg.ID,
+			CivilCode: p.CatalogID[:6],
+			Status:    "ON",
+		})
+	}
+	for _, s := range shares {
+		item := CascadeCatalogItem{
+			DeviceID:     s.GBID,
+			Name:         s.Name,
+			Manufacturer: "gowvp",
+			Model:        s.Source,
+			CivilCode:    s.GBID[:6],
+			ParentID:     parentID,
+			RegisterWay:  1,
+			Status:       "ON",
+		}
+		if s.ParentID != "" {
+			item.ParentID = s.ParentID
+		}
+		if s.Source == gb28181.PlatformSourceGB {
+			ch, ok := channels[s.ChannelID]
+			if !ok {
+				continue
+			}
+			item.Manufacturer = cmp.Or(ch.Ext.Manufacturer, item.Manufacturer)
+			item.Model = cmp.Or(ch.Ext.Model, item.Model)
+			item.Owner = ch.Owner
+			item.Address = ch.Address
+			item.CivilCode = cmp.Or(ch.CivilCode, item.CivilCode)
+			item.Longitude = ch.Longitude
+			item.Latitude = ch.Latitude
+			if !ch.IsOnline {
+				item.Status = "OFF"
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// cascadeCatalog 应答上级目录查询，分多条消息发送
+func (g *GB28181API) cascadeCatalog(c *cascadeClient, sn int) error {
+	items, err := g.cascadeCatalogItems(c.platform)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(items) || i == 0; i += cascadeCatalogBatch {
+		end := min(i+cascadeCatalogBatch, len(items))
+		msg := CascadeCatalogResponse{
+			CmdType:  "Catalog",
+			SN:       sn,
+			DeviceID: g.cfg.ID,
+			SumNum:   len(items),
+		}
+		msg.DeviceList.Items = items[i:end]
+		msg.DeviceList.Num = len(msg.DeviceList.Items)
+		body, err := sip.XMLEncode(msg)
+		if err != nil {
+			return err
+		}
+		if err := g.sendCascadeMessage(c, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CascadeDeviceInfo 向上级应答的设备信息
+// GB/T28181 A.2.6.5
+type CascadeDeviceInfo struct {
+	XMLName      xml.Name `xml:"Response"`
+	CmdType      string   `xml:"CmdType"`
+	SN           int      `xml:"SN"`
+	DeviceID     string   `xml:"DeviceID"`
+	DeviceName   string   `xml:"DeviceName"`
+	Manufacturer string   `xml:"Manufacturer"`
+	Model        string   `xml:"Model"`
+	Firmware     string   `xml:"Firmware"`
+	Channel      int      `xml:"Channel"`
+	Result       string   `xml:"Result"`
+}
+
+// cascadeDeviceInfo 应答上级设备信息查询，查询共享通道时返回通道名称
+func (g *GB28181API) cascadeDeviceInfo(c *cascadeClient, q *CascadeQuery) error {
+	out := CascadeDeviceInfo{
+		CmdType:      "DeviceInfo",
+		SN:           q.SN,
+		DeviceID:     q.DeviceID,
+		DeviceName:   "gowvp",
+		Manufacturer: "gowvp",
+		Model:        "gowvp",
+		Firmware:     "gowvp",
+		Result:       "OK",
+	}
+	if q.DeviceID == g.cfg.ID {
+		shares, _, err := g.core.FindPlatformChannels(context.Background(), c.platform.ID)
+		if err != nil {
+			return err
+		}
+		out.Channel = len(shares)
+	} else if share, err := g.core.GetPlatformChannel(context.Background(), c.platform.ID, q.DeviceID); err == nil {
+		out.DeviceName = share.Name
+		out.Channel = 1
+	} else {
+		out.Result = "ERROR"
+	}
+	body, err := sip.XMLEncode(out)
+	if err != nil {
+		return err
+	}
+	return g.sendCascadeMessage(c, body)
+}
+
+// CascadeDeviceStatus 向上级应答的设备状态
+// GB/T28181 A.2.6.6
+type CascadeDeviceStatus struct {
+	XMLName    xml.Name `xml:"Response"`
+	CmdType    string   `xml:"CmdType"`
+	SN         int      `xml:"SN"`
+	DeviceID   string   `xml:"DeviceID"`
+	Result     string   `xml:"Result"`
+	Online     string   `xml:"Online"`
+	Status     string   `xml:"Status"`
+	Encode     string   `xml:"Encode"`
+	Record     string   `xml:"Record"`
+	DeviceTime string   `xml:"DeviceTime"`
+}
+
+// cascadeDeviceStatus 应答上级设备状态查询，在线状态与目录中上报的状态一致，查询未共享的编码时返回 ERROR
+func (g *GB28181API) cascadeDeviceStatus(c *cascadeClient, q *CascadeQuery) error {
+	out := CascadeDeviceStatus{
+		CmdType:    "DeviceStatus",
+		SN:         q.SN,
+		DeviceID:   q.DeviceID,
+		Result:     "OK",
+		Online:     "ONLINE",
+		Status:     "OK",
+		Encode:     "ON",
+		Record:     "OFF",
+		DeviceTime: time.Now().Format("2006-01-02T15:04:05"),
+	}
+	if q.DeviceID != g.cfg.ID {
+		items, err := g.cascadeCatalogItems(c.platform)
+		if err != nil {
+			return err
+		}
+		idx := slices.IndexFunc(items, func(item CascadeCatalogItem) bool { return item.DeviceID == q.DeviceID })
+		switch {
+		case idx < 0:
+			out.Result = "ERROR"
+			out.Online = "OFFLINE"
+			out.Status = "ERROR"
+		case items[idx].Status != "ON":
+			out.Online = "OFFLINE"
+			out.Status = "ERROR"
+		}
+	}
+	body, err := sip.XMLEncode(out)
+	if err != nil {
+		return err
+	}
+	return g.sendCascadeMessage(c, body)
+}
+
+// cascadeInvite 上级平台点播共享通道，由流媒体向上级推送 ps 流，非上级平台的请求交由后续处理
+// GB/T28181 9.2
+func (g *GB28181API) cascadeInvite(ctx *sip.Context) {
+	c := g.findCascade(ctx.DeviceID)
+	if c == nil {
+		return
+	}
+	ctx.Abort()
+
+	offer, err := decodeSDP(ctx.Request.Body())
+	if err != nil {
+		ctx.Log.Error("cascadeInvite", "err", err, "body", string(ctx.Request.Body()))
+		ctx.String(http.StatusBadRequest, "sdp decode error")
+		return
+	}
+	// 仅支持实时点播
+	if !strings.EqualFold(offer.Name, "Play") {
+		ctx.String(488, "Not Acceptable Here")
+		return
+	}
+	gbID := cascadeTarget(ctx.Request, offer)
+	share, err := g.core.GetPlatformChannel(context.Background(), c.platform.ID, gbID)
+	if err != nil {
+		ctx.Log.Warn("上级点播的通道未共享", "gbID", gbID, "err", err)
+		ctx.String(http.StatusNotFound, "channel not found")
+		return
+	}
+	if g.source == nil {
+		ctx.String(http.StatusInternalServerError, ErrCascadeSource.Error())
+		return
+	}
+	ctx.String(http.StatusContinue, "Trying")
+
+	svr, app, stream, err := g.source.OpenStream(context.Background(), share.ChannelID)
+	if err != nil {
+		ctx.Log.Error("OpenStream", "err", err, "channelID", share.ChannelID)
+		ctx.String(http.StatusNotFound, "stream not found")
+		return
+	}
+	call := cascadeCall{
+		platformID: c.platform.ID,
+		channelID:  share.ChannelID,
+		sms:        svr,
+		app:        app,
+		stream:     stream,
+	}
+	answer, err := g.startCascadeRTP(&call, offer)
+	if err != nil {
+		ctx.Log.Error("startCascadeRTP", "err", err, "channelID", share.ChannelID)
+		ctx.String(488, "Not Acceptable Here")
+		return
+	}
+	if _, err := g.answerInvite(ctx, answer); err != nil {
+		g.stopCascadeCall(&call)
+		return
+	}
+	if callID, ok := ctx.Request.CallID(); ok {
+		g.cascadeCalls.Store(callID.String(), &call)
+	}
+	ctx.Log.Info("上级平台点播", "gbID", gbID, "channelID", share.ChannelID, "ssrc", call.ssrc)
+}
+
+// cascadeTarget 上级点播的通道编码，优先取请求 URI，其次取 SDP u= 行
+func cascadeTarget(req *sip.Request, offer *sdp.Message) string {
+	if uri := req.Recipient(); uri != nil {
+		if user := uri.User(); user != nil && user.String() != "" {
+			return user.String()
+		}
+	}
+	id, _, _ := strings.Cut(offer.URI, ":")
+	return id
+}
+
+// startCascadeRTP 按上级 SDP 启动流媒体推流，返回本平台应答的 SDP
+// 流媒体刚开始拉流时可能尚未就绪，推流失败会在一段时间内重试
+func (g *GB28181API) startCascadeRTP(call *cascadeCall, offer *sdp.Message) ([]byte, error) {
+	var video *sdp.Media
+	for i := range offer.Medias {
+		if offer.Medias[i].Description.Type == "video" {
+			video = &offer.Medias[i]
+			break
+		}
+	}
+	if video == nil {
+		return nil, fmt.Errorf("sdp without video media")
+	}
+	dstIP := video.Connection.IP
+	if dstIP == nil {
+		dstIP = offer.Connection.IP
+	}
+	if dstIP == nil {
+		return nil, fmt.Errorf("sdp without connection address")
+	}
+	protocol := video.Description.Protocol
+	isUDP := !strings.Contains(strings.ToUpper(protocol), "TCP")
+	// 上级 setup:active 时需由本平台被动监听，暂不支持
+	if !isUDP && strings.EqualFold(video.Attribute("setup"), "active") {
+		return nil, ErrCascadeTransport
+	}
+	call.ssrc = offer.SSRC
+	if call.ssrc == "" {
+		call.ssrc = g.ssrcs.Next(false)
+	}
+
+	var resp *zlm.StartSendRTPResponse
+	var err error
+	for range 10 {
+		resp, err = g.sms.StartSendRTP(call.sms, zlm.StartSendRTPRequest{
+			Vhost:   "__defaultVhost__",
+			App:     call.app,
+			Stream:  call.stream,
+			SSRC:    call.ssrc,
+			DstURL:  dstIP.String(),
+			DstPort: video.Description.Port,
+			IsUDP:   isUDP,
+			PT:      96,
+			UsePS:   zlm.NewInt(1),
+		})
+		if err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := GetIP(call.sms.GetSDPIP())
+	if err != nil {
+		g.stopCascadeCall(call)
+		return nil, err
+	}
+	media := sdp.Media{
+		Description: sdp.MediaDescription{
+			Type:     "video",
+			Port:     resp.LocalPort,
+			Formats:  []string{"96"},
+			Protocol: protocol,
+		},
+	}
+	media.AddAttribute("sendonly")
+	if !isUDP {
+		media.AddAttribute("setup", "active")
+		media.AddAttribute("connection", "new")
+	}
+	media.AddAttribute("rtpmap", "96", "PS/90000")
+	msg := sdp.Message{
+		Origin: sdp.Origin{
+			Username:    g.cfg.ID,
+			NetworkType: "IN",
+			AddressType: "IP4",
+			Address:     ip,
+		},
+		Name: "Play",
+		Connection: sdp.ConnectionData{
+			NetworkType: "IN",
+			AddressType: "IP4",
+			IP:          net.ParseIP(ip),
+		},
+		Timing: []sdp.Timing{{}},
+		Medias: []sdp.Media{media},
+		SSRC:   call.ssrc,
+	}
+	return msg.Append(nil).AppendTo(nil), nil
+}
+
+// stopCascadeCall 停止向上级推流
+func (g *GB28181API) stopCascadeCall(call *cascadeCall) {
+	if err := g.sms.StopSendRTP(call.sms, zlm.StopSendRTPRequest{
+		Vhost:  "__defaultVhost__",
+		App:    call.app,
+		Stream: call.stream,
+		SSRC:   call.ssrc,
+	}); err != nil {
+		slog.Warn("StopSendRTP", "err", err, "stream", call.stream)
+	}
+}
+
+// cascadeBye 上级平台挂断点播，非上级点播会话交由后续处理
+func (g *GB28181API) cascadeBye(ctx *sip.Context) {
+	callID, ok := ctx.Request.CallID()
+	if !ok {
+		return
+	}
+	call, ok := g.cascadeCalls.LoadAndDelete(callID.String())
+	if !ok {
+		return
+	}
+	ctx.Abort()
+	ctx.String(http.StatusOK, "OK")
+	g.stopCascadeCall(call)
+	ctx.Log.Info("上级平台结束点播", "channelID", call.channelID)
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/xml"
 	"log/slog"
 	"net"
+	"strings"
 
+	"github.com/gowvp/gb28181/internal/core/gb28181"
 	"github.com/gowvp/gb28181/pkg/gbs/sip"
 )
 
@@ -81,7 +83,7 @@ func (g *GB28181API) QueryCatalog(deviceID string) error {
 	}
 
 	g.catalog.Run(deviceID)
-	g.catalog.Wait(deviceID)
+	g.catalog.WaitWithTimeout(deviceID, ipc.Profile().CatalogTimeout)
 	return nil
 }
 
@@ -108,6 +110,10 @@ func (s *Server) wrapRequest(t Targeter, method string, contentType *sip.Content
 			Params: sip.NewParams().Add("branch", sip.String{Str: sip.GenerateBranch()}),
 		})
 
+	if p, ok := t.(profiler); ok && *contentType == sip.ContentTypeXML {
+		body = p.Profile().encodeXML(body)
+	}
+
 	req := sip.NewRequest("", method, to.URI, sip.DefaultSipVersion, hb.Build(), body)
 	req.SetConnection(conn)
 	req.SetSource(source)
@@ -119,3 +125,138 @@ func (s *Server) wrapRequest(t Targeter, method string, contentType *sip.Content
 
 	return s.Request(req)
 }
+
+// 目录变化事件 GB/T28181 A.2.5.2
+const (
+	CatalogEventAdd    = "ADD"    // 增加
+	CatalogEventDel    = "DEL"    // 删除
+	CatalogEventUpdate = "UPDATE" // 更新
+	CatalogEventOn     = "ON"     // 上线
+	CatalogEventOff    = "OFF"    // 离线
+	CatalogEventVLost  = "VLOST"  // 视频丢失
+	CatalogEventDefect = "DEFECT" // 故障
+)
+
+// CatalogNotify 目录订阅通知
+type CatalogNotify struct {
+	XMLName  xml.Name   `xml:"Notify"`
+	CmdType  string     `xml:"CmdType"`
+	SN       int        `xml:"SN"`
+	DeviceID string     `xml:"DeviceID"`
+	SumNum   int        `xml:"SumNum"`
+	Item     []Channels `xml:"DeviceList>Item"`
+}
+
+// sipNotifyCatalog 目录订阅的变化通知
+// GB/T28181 9.11.2
+func (g *GB28181API) sipNotifyCatalog(ctx *sip.Context) {
+	var msg CatalogNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipNotifyCatalog", "err", err)
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	deviceID := ctx.DeviceID
+	ipc, ok := g.svr.memoryStorer.Load(deviceID)
+	for _, item := range msg.Item {
+		if gb28181.IsCatalogGroup(item.ChannelID) {
+			if err := g.applyCatalogGroupEvent(deviceID, &item); err != nil {
+				ctx.Log.Error("applyCatalogGroupEvent", "err", err, "groupID", item.ChannelID, "event", item.Event)
+			}
+			continue
+		}
+		if err := g.applyCatalogEvent(deviceID, &item); err != nil {
+			ctx.Log.Error("applyCatalogEvent", "err", err, "channelID", item.ChannelID, "event", item.Event)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(item.Event) {
+		case CatalogEventDel:
+			ipc.Channels.Delete(item.ChannelID)
+		case "", CatalogEventAdd, CatalogEventUpdate:
+			ch := Channel{ChannelID: item.ChannelID, device: ipc}
+			ch.init(g.cfg.Domain)
+			ipc.Channels.Store(ch.ChannelID, &ch)
+		}
+	}
+}
+
+// applyCatalogEvent 将目录变化事件写入通道，未携带事件的通知视为全量目录中的条目
+func (g *GB28181API) applyCatalogEvent(deviceID string, item *Channels) error {
+	switch strings.ToUpper(item.Event) {
+	case CatalogEventDel:
+		return g.core.DelChannel(deviceID, item.ChannelID)
+	case CatalogEventOn:
+		return g.core.EditChannelOnline(deviceID, item.ChannelID, true)
+	case CatalogEventOff, CatalogEventVLost, CatalogEventDefect:
+		return g.core.EditChannelOnline(deviceID, item.ChannelID, false)
+	}
+	return g.core.SaveChannel(item.toChannel(deviceID))
+}
+
+// applyCatalogGroupEvent 分组节点的变化事件，分组没有在线状态
+func (g *GB28181API) applyCatalogGroupEvent(deviceID string, item *Channels) error {
+	switch strings.ToUpper(item.Event) {
+	case CatalogEventDel:
+		return g.core.DelCatalogGroup(deviceID, item.ChannelID)
+	case "", CatalogEventAdd, CatalogEventUpdate:
+		return g.core.SaveCatalogGroup(deviceID, item.toCatalogGroup())
+	}
+	return nil
+}
+
+func (c *Channels) toChannel(deviceID string) *gb28181.Channel {
+	out := gb28181.Channel{
+		DeviceID:        deviceID,
+		ChannelID:       c.ChannelID,
+		Name:            c.Name,
+		IsOnline:        c.Status == "OK" || c.Status == "ON",
+		ParentID:        c.ParentID,
+		CivilCode:       c.CivilCode,
+		BusinessGroupID: c.BusinessGroupID,
+		Owner:           c.Owner,
+		Address:         c.Address,
+		Longitude:       c.Longitude,
+		Latitude:        c.Latitude,
+		Ext: gb28181.DeviceExt{
+			Manufacturer: c.Manufacturer,
+			Model:        c.Model,
+			Block:        c.Block,
+			IPAddress:    c.IPAddress,
+			Port:         c.Port,
+			Parental:     c.Parental,
+			SafetyWay:    c.SafetyWay,
+			RegisterWay:  c.RegisterWay,
+			CertNum:      c.CertNum,
+			Certifiable:  c.Certifiable,
+			ErrCode:      c.ErrCode,
+			EndTime:      c.EndTime,
+			Secrecy:      c.Secrecy,
+			Info:         c.Info,
+		},
+	}
+	if c.Info != nil {
+		out.PTZType = c.Info.PTZType
+		out.PositionType = c.Info.PositionType
+		// 2016 版的业务分组编码在 Info 中
+		if out.BusinessGroupID == "" {
+			out.BusinessGroupID = c.Info.BusinessGroupID
+		}
+	}
+	return &out
+}
+
+func (c *Channels) toCatalogGroup() *gb28181.CatalogGroup {
+	return &gb28181.CatalogGroup{
+		GroupID:         c.ChannelID,
+		Name:            c.Name,
+		Type:            gb28181.CatalogType(c.ChannelID),
+		ParentID:        c.ParentID,
+		CivilCode:       c.CivilCode,
+		BusinessGroupID: c.BusinessGroupID,
+	}
+}
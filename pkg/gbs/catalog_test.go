@@ -0,0 +1,67 @@
+package gbs
+
+import (
+	"testing"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+func TestCatalogItemToChannel(t *testing.T) {
+	body := `<?xml version="1.0" encoding="GB2312"?>
+<Response>
+<CmdType>Catalog</CmdType>
+<SN>1</SN>
+<DeviceID>34020000002000000001</DeviceID>
+<SumNum>2</SumNum>
+<DeviceList Num="2">
+<Item>
+<DeviceID>34020000002160000001</DeviceID>
+<Name>园区</Name>
+<BusinessGroupID>34020000002150000001</BusinessGroupID>
+</Item>
+<Item>
+<DeviceID>34020000001320000001</DeviceID>
+<Name>东门</Name>
+<Manufacturer>Hikvision</Manufacturer>
+<Owner>Owner</Owner>
+<CivilCode>340200</CivilCode>
+<Address>东门岗亭</Address>
+<Parental>0</Parental>
+<ParentID>34020000002160000001</ParentID>
+<SafetyWay>0</SafetyWay>
+<RegisterWay>1</RegisterWay>
+<Secrecy>0</Secrecy>
+<IPAddress>192.168.1.64</IPAddress>
+<Port></Port>
+<Status>ON</Status>
+<Longitude>117.2272</Longitude>
+<Latitude>31.8206</Latitude>
+<Info><PTZType>1</PTZType><PositionType>4</PositionType><DirectionType>3</DirectionType><Resolution>6/3</Resolution><StreamNumberList>0/1</StreamNumberList></Info>
+</Item>
+</DeviceList>
+</Response>`
+	var msg MessageDeviceListResponse
+	if err := sip.XMLDecode([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Item) != 2 {
+		t.Fatalf("expect 2 items, got %d", len(msg.Item))
+	}
+
+	group := msg.Item[0].toCatalogGroup()
+	if group.Type != gb28181.CatalogTypeVirtualOrg || group.BusinessGroupID != "34020000002150000001" {
+		t.Fatalf("unexpected group %+v", group)
+	}
+
+	ch := msg.Item[1].toChannel(msg.DeviceID)
+	if !ch.IsOnline || ch.Address != "东门岗亭" || ch.CivilCode != "340200" || ch.ParentID != "34020000002160000001" {
+		t.Fatalf("unexpected channel %+v", ch)
+	}
+	if ch.Longitude != 117.2272 || ch.Latitude != 31.8206 || ch.PTZType != 1 || ch.PositionType != 4 {
+		t.Fatalf("unexpected channel position/info %+v", ch)
+	}
+	if ch.Ext.IPAddress != "192.168.1.64" || ch.Ext.RegisterWay != 1 || ch.Ext.Info == nil || ch.Ext.Info.StreamNumberList != "0/1" {
+		t.Fatalf("unexpected channel ext %+v", ch.Ext)
+	}
+}
@@ -2,40 +2,62 @@ package gbs
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
-	"fmt"
+	"errors"
 	"log/slog"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/gowvp/gb28181/pkg/gbs/sip"
 )
 
-// 配置参数类型常量定义
+// 配置参数类型 GB/T28181 A.2.4.7
 const (
-	// basicParam 基本参数配置
-	basicParam = "BasicParam"
-	// videoParamOpt 视频参数范围配置
-	// videoParamOpt = "VideoParamOpt"
-	// // SVACEncodeConfig SVAC编码配置
-	// SVACEncodeConfig = "SVACEncodeConfig"
-	// // SVACDecodeConfig SVAC解码配置
-	// SVACDecodeConfig = "SVACDecodeConfig"
-	// // videoParamAttribute 视频参数属性配置
-	// videoParamAttribute = "VideoParamAttribute"
-	// // videoRecordPlan 录像计划
-	// videoRecordPlan = "VideoRecordPlan"
-	// // videoAlarmRecord 报警录像
-	// videoAlarmRecord = "VideoAlarmRecord"
-	// // pictureMask 视频画面遮挡
-	// pictureMask = "PictureMask"
-	// // frameMirror 画面翻转
-	// frameMirror = "FrameMirror"
-	// // AlarmReport 报警上报开关
-	// AlarmReport = "AlarmReport"
-	// // OSDConfig 前端OSD设置
-	// OSDConfig = "OSDConfig"
+	ConfigTypeBasicParam          = "BasicParam"          // 基本参数配置
+	ConfigTypeVideoParamOpt       = "VideoParamOpt"       // 视频参数范围，只读
+	ConfigTypeSVACEncodeConfig    = "SVACEncodeConfig"    // SVAC 编码配置
+	ConfigTypeSVACDecodeConfig    = "SVACDecodeConfig"    // SVAC 解码配置
+	ConfigTypeVideoParamAttribute = "VideoParamAttribute" // 视频参数属性配置
+	ConfigTypeVideoRecordPlan     = "VideoRecordPlan"     // 录像计划
+	ConfigTypeVideoAlarmRecord    = "VideoAlarmRecord"    // 报警录像
+	ConfigTypePictureMask         = "PictureMask"         // 视频画面遮挡
+	ConfigTypeFrameMirror         = "FrameMirror"         // 画面翻转
+	ConfigTypeAlarmReport         = "AlarmReport"         // 报警上报开关
+	ConfigTypeOSDConfig           = "OSDConfig"           // 前端 OSD 设置
 )
 
+// configTypes 支持的配置类型
+var configTypes = []string{
+	ConfigTypeBasicParam,
+	ConfigTypeVideoParamOpt,
+	ConfigTypeSVACEncodeConfig,
+	ConfigTypeSVACDecodeConfig,
+	ConfigTypeVideoParamAttribute,
+	ConfigTypeVideoRecordPlan,
+	ConfigTypeVideoAlarmRecord,
+	ConfigTypePictureMask,
+	ConfigTypeFrameMirror,
+	ConfigTypeAlarmReport,
+	ConfigTypeOSDConfig,
+}
+
+var (
+	ErrConfigType     = errors.New("unsupported config type")
+	ErrConfigReadOnly = errors.New("config type is read only")
+)
+
+// ParseConfigType 校验配置类型，忽略大小写，返回标准名称
+func ParseConfigType(typ string) (string, error) {
+	for _, k := range configTypes {
+		if strings.EqualFold(k, typ) {
+			return k, nil
+		}
+	}
+	return "", ErrConfigType
+}
+
 type ConfigDownloadRequest struct {
 	XMLName        xml.Name  `xml:"Query"`
 	CmdType        string    `xml:"CmdType"`    // 命令类型：设备配置查询(必选)
@@ -46,25 +68,109 @@ type ConfigDownloadRequest struct {
 }
 
 type ConfigDownloadResponse struct {
-	XMLName    xml.Name    `xml:"Response"`
-	CmdType    string      `xml:"CmdType"`
-	SN         int         `xml:"SN"`
-	DeviceID   string      `xml:"DeviceID"`
-	Result     string      `xml:"Result"`
-	BasicParam *BasicParam `xml:"BasicParam"`
-	// VideoParamOpt       *VideoParamOpt       `xml:"VideoParamOpt"`
-	// SVACEncodeConfig    *SVACEncodeConfig    `xml:"SVACEncodeConfig"`
-	// SVACDecodeConfig    *SVACDecodeConfig    `xml:"SVACDecodeConfig"`
-	// VideoParamAttribute *VideoParamAttribute `xml:"VideoParamAttribute"`
-	// VideoRecordPlan     *VideoRecordPlan     `xml:"VideoRecordPlan"`
-	// VideoAlarmRecord    *VideoAlarmRecord    `xml:"VideoAlarmRecord"`
-	// PictureMask         *PictureMask         `xml:"PictureMask"`
-	// FrameMirror         *FrameMirror         `xml:"FrameMirror"`
-	// AlarmReport         *AlarmReport         `xml:"AlarmReport"`
-	// OSDConfig           *OSDConfig           `xml:"OSDConfig"`
+	XMLName  xml.Name `xml:"Response"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	DeviceID string   `xml:"DeviceID"`
+	Result   string   `xml:"Result"`
+	ConfigParams
 	SnapShot *SnapShot `xml:"SnapShot"`
 }
 
+// ConfigParams 设备配置参数，配置查询应答与设备配置共用
+type ConfigParams struct {
+	BasicParam          *BasicParam          `xml:"BasicParam,omitempty" json:"basic_param,omitempty"`
+	VideoParamOpt       *VideoParamOpt       `xml:"VideoParamOpt,omitempty" json:"video_param_opt,omitempty"`
+	SVACEncodeConfig    *SVACEncodeConfig    `xml:"SVACEncodeConfig,omitempty" json:"svac_encode_config,omitempty"`
+	SVACDecodeConfig    *SVACDecodeConfig    `xml:"SVACDecodeConfig,omitempty" json:"svac_decode_config,omitempty"`
+	VideoParamAttribute *VideoParamAttribute `xml:"VideoParamAttribute,omitempty" json:"video_param_attribute,omitempty"`
+	VideoRecordPlan     *VideoRecordPlan     `xml:"VideoRecordPlan,omitempty" json:"video_record_plan,omitempty"`
+	VideoAlarmRecord    *VideoAlarmRecord    `xml:"VideoAlarmRecord,omitempty" json:"video_alarm_record,omitempty"`
+	PictureMask         *PictureMask         `xml:"PictureMask,omitempty" json:"picture_mask,omitempty"`
+	FrameMirror         *FrameMirror         `xml:"FrameMirror,omitempty" json:"frame_mirror,omitempty"`
+	AlarmReport         *AlarmReport         `xml:"AlarmReport,omitempty" json:"alarm_report,omitempty"`
+	OSDConfig           *OSDConfig           `xml:"OSDConfig,omitempty" json:"osd_config,omitempty"`
+}
+
+// Get 获取指定类型的配置，不存在时返回 nil
+func (p *ConfigParams) Get(typ string) any {
+	switch typ {
+	case ConfigTypeBasicParam:
+		return anyOrNil(p.BasicParam)
+	case ConfigTypeVideoParamOpt:
+		return anyOrNil(p.VideoParamOpt)
+	case ConfigTypeSVACEncodeConfig:
+		return anyOrNil(p.SVACEncodeConfig)
+	case ConfigTypeSVACDecodeConfig:
+		return anyOrNil(p.SVACDecodeConfig)
+	case ConfigTypeVideoParamAttribute:
+		return anyOrNil(p.VideoParamAttribute)
+	case ConfigTypeVideoRecordPlan:
+		return anyOrNil(p.VideoRecordPlan)
+	case ConfigTypeVideoAlarmRecord:
+		return anyOrNil(p.VideoAlarmRecord)
+	case ConfigTypePictureMask:
+		return anyOrNil(p.PictureMask)
+	case ConfigTypeFrameMirror:
+		return anyOrNil(p.FrameMirror)
+	case ConfigTypeAlarmReport:
+		return anyOrNil(p.AlarmReport)
+	case ConfigTypeOSDConfig:
+		return anyOrNil(p.OSDConfig)
+	}
+	return nil
+}
+
+// anyOrNil 空指针返回 nil，避免 any 中包含带类型的 nil
+func anyOrNil[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return v
+}
+
+// Set 按类型解析 json 格式的配置
+func (p *ConfigParams) Set(typ string, data []byte) error {
+	var v any
+	switch typ {
+	case ConfigTypeBasicParam:
+		p.BasicParam = new(BasicParam)
+		v = p.BasicParam
+	case ConfigTypeSVACEncodeConfig:
+		p.SVACEncodeConfig = new(SVACEncodeConfig)
+		v = p.SVACEncodeConfig
+	case ConfigTypeSVACDecodeConfig:
+		p.SVACDecodeConfig = new(SVACDecodeConfig)
+		v = p.SVACDecodeConfig
+	case ConfigTypeVideoParamAttribute:
+		p.VideoParamAttribute = new(VideoParamAttribute)
+		v = p.VideoParamAttribute
+	case ConfigTypeVideoRecordPlan:
+		p.VideoRecordPlan = new(VideoRecordPlan)
+		v = p.VideoRecordPlan
+	case ConfigTypeVideoAlarmRecord:
+		p.VideoAlarmRecord = new(VideoAlarmRecord)
+		v = p.VideoAlarmRecord
+	case ConfigTypePictureMask:
+		p.PictureMask = new(PictureMask)
+		v = p.PictureMask
+	case ConfigTypeFrameMirror:
+		p.FrameMirror = new(FrameMirror)
+		v = p.FrameMirror
+	case ConfigTypeAlarmReport:
+		p.AlarmReport = new(AlarmReport)
+		v = p.AlarmReport
+	case ConfigTypeOSDConfig:
+		p.OSDConfig = new(OSDConfig)
+		v = p.OSDConfig
+	case ConfigTypeVideoParamOpt:
+		return ErrConfigReadOnly
+	default:
+		return ErrConfigType
+	}
+	return json.Unmarshal(data, v)
+}
+
 type SnapShot struct {
 	SnapNum   int    `xml:"SnapNum"`   // 连拍张数(必选)，最多10张，当手动抓拍时，取值为1
 	Interval  int    `xml:"Interval"`  // 单张抓拍间隔时间，单位：秒(必选)，取值范围:最短1秒
@@ -74,25 +180,184 @@ type SnapShot struct {
 
 // BasicParam 设备基本参数配置
 type BasicParam struct {
-	Name              string `xml:"Name"`              // 设备名称
-	Expiration        int    `xml:"Expiration"`        // 注册过期时间
-	HeartBeatInterval int    `xml:"HeartBeatInterval"` // 心跳间隔时间
-	HeartBeatCount    int    `xml:"HeartBeatCount"`    // 心跳超时次数
+	Name              string `xml:"Name" json:"name"`                             // 设备名称
+	Expiration        int    `xml:"Expiration" json:"expiration"`                 // 注册过期时间
+	HeartBeatInterval int    `xml:"HeartBeatInterval" json:"heart_beat_interval"` // 心跳间隔时间
+	HeartBeatCount    int    `xml:"HeartBeatCount" json:"heart_beat_count"`       // 心跳超时次数
+}
+
+// VideoParamOpt 视频参数范围
+type VideoParamOpt struct {
+	DownloadSpeed string `xml:"DownloadSpeed" json:"download_speed"` // 下载倍速范围，各可选参数以 "/" 分隔，如 1/2/4
+	Resolution    string `xml:"Resolution" json:"resolution"`        // 摄像机支持的分辨率，各可选参数以 "/" 分隔
+}
+
+// SVACEncodeConfig SVAC 编码配置
+type SVACEncodeConfig struct {
+	ROIParam          *ROIParam             `xml:"ROIParam,omitempty" json:"roi_param,omitempty"`                   // 感兴趣区域参数
+	SVCParam          *SVCEncodeParam       `xml:"SVCParam,omitempty" json:"svc_param,omitempty"`                   // SVC 参数
+	SurveillanceParam *SurveillanceParam    `xml:"SurveillanceParam,omitempty" json:"surveillance_param,omitempty"` // 监控专用信息参数
+	EncryptParam      *EncryptParam         `xml:"EncryptParam,omitempty" json:"encrypt_param,omitempty"`           // 加密与认证参数
+	AudioParam        *SVACEncodeAudioParam `xml:"AudioParam,omitempty" json:"audio_param,omitempty"`               // 音频参数
+}
+
+type ROIParam struct {
+	ROIFlag            int       `xml:"ROIFlag" json:"roi_flag"`                         // 感兴趣区域开关 0 关闭 1 打开
+	ROINumber          int       `xml:"ROINumber" json:"roi_number"`                     // 感兴趣区域数量，取值范围 0~16
+	Items              []ROIItem `xml:"Item" json:"items"`                               // 感兴趣区域
+	BackGroundQP       int       `xml:"BackGroundQP" json:"back_ground_qp"`              // 背景区域编码质量等级
+	BackGroundSkipFlag int       `xml:"BackGroundSkipFlag" json:"back_ground_skip_flag"` // 背景跳过开关 0 关闭 1 打开
+}
+
+type ROIItem struct {
+	ROISeq      int `xml:"ROISeq" json:"roi_seq"`           // 感兴趣区域编号，取值范围 1~16
+	TopLeft     int `xml:"TopLeft" json:"top_left"`         // 感兴趣区域左上角坐标
+	BottomRight int `xml:"BottomRight" json:"bottom_right"` // 感兴趣区域右下角坐标
+	ROIQP       int `xml:"ROIQP" json:"roi_qp"`             // ROI 区域编码质量等级
+}
+
+type SVCEncodeParam struct {
+	SVCFlag            int `xml:"SVCFlag" json:"svc_flag"`                         // SVC 开关 0 关闭 1 打开
+	SVCSTMMode         int `xml:"SVCSTMMode" json:"svc_stm_mode"`                  // 码流上传模式
+	SVCSpaceDomainMode int `xml:"SVCSpaceDomainMode" json:"svc_space_domain_mode"` // 空域编码方式
+	SVCTimeDomainMode  int `xml:"SVCTimeDomainMode" json:"svc_time_domain_mode"`   // 时域编码方式
+}
+
+type SurveillanceParam struct {
+	TimeFlag  int `xml:"TimeFlag" json:"time_flag"`   // 绝对时间信息开关
+	EventFlag int `xml:"EventFlag" json:"event_flag"` // 监控事件信息开关
+	AlertFlag int `xml:"AlertFlag" json:"alert_flag"` // 报警信息开关
+}
+
+type EncryptParam struct {
+	EncryptionFlag     int `xml:"EncryptionFlag" json:"encryption_flag"`         // 加密开关
+	AuthenticationFlag int `xml:"AuthenticationFlag" json:"authentication_flag"` // 认证开关
+}
+
+type SVACEncodeAudioParam struct {
+	AudioRecognitionFlag int `xml:"AudioRecognitionFlag" json:"audio_recognition_flag"` // 声音识别特征参数开关
+}
+
+// SVACDecodeConfig SVAC 解码配置
+type SVACDecodeConfig struct {
+	SVCParam          *SVCDecodeParam        `xml:"SVCParam,omitempty" json:"svc_param,omitempty"`                   // SVC 参数
+	SurveillanceParam *SurveillanceShowParam `xml:"SurveillanceParam,omitempty" json:"surveillance_param,omitempty"` // 监控专用信息参数
+}
+
+type SVCDecodeParam struct {
+	SVCSTMMode int `xml:"SVCSTMMode" json:"svc_stm_mode"` // 码流显示模式
+}
+
+type SurveillanceShowParam struct {
+	TimeShowFlag  int `xml:"TimeShowFlag" json:"time_show_flag"`   // 绝对时间信息显示开关
+	EventShowFlag int `xml:"EventShowFlag" json:"event_show_flag"` // 监控事件信息显示开关
+	AlerShowtFlag int `xml:"AlerShowtFlag" json:"alert_show_flag"` // 报警信息显示开关，标准中即为此拼写
+}
+
+// VideoParamAttribute 视频参数属性配置
+type VideoParamAttribute struct {
+	Items []VideoParamAttributeItem `xml:"Item" json:"items"`
+}
+
+type VideoParamAttributeItem struct {
+	StreamNumber int    `xml:"StreamNumber" json:"stream_number"`  // 码流编号 0 主码流 1 子码流 2 第三码流
+	VideoFormat  string `xml:"VideoFormat" json:"video_format"`    // 视频编码格式 1 MPEG-4 2 H.264 3 SVAC 4 3GP 5 H.265
+	Resolution   string `xml:"Resolution" json:"resolution"`       // 分辨率
+	FrameRate    string `xml:"FrameRate" json:"frame_rate"`        // 帧率
+	BitRateType  string `xml:"BitRateType" json:"bit_rate_type"`   // 码率类型 1 固定码率 2 可变码率
+	VideoBitRate string `xml:"VideoBitRate" json:"video_bit_rate"` // 视频码率 kbps
+}
+
+// VideoRecordPlan 录像计划
+type VideoRecordPlan struct {
+	RecordEnable         int              `xml:"RecordEnable" json:"record_enable"`                   // 是否启用 0 否 1 是
+	RecordScheduleSumNum int              `xml:"RecordScheduleSumNum" json:"record_schedule_sum_num"` // 每周录像计划总数
+	RecordSchedule       []RecordSchedule `xml:"RecordSchedule" json:"record_schedule"`               // 一周的录像计划
+	StreamNumber         int              `xml:"StreamNumber" json:"stream_number"`                   // 码流类型 0 主码流 1 子码流 1 2 子码流 2
+}
+
+type RecordSchedule struct {
+	WeekDayNum        int           `xml:"WeekDayNum" json:"week_day_num"`                // 周几 1~7
+	TimeSegmentSumNum int           `xml:"TimeSegmentSumNum" json:"time_segment_sum_num"` // 当天时间段总数，最大 4 个
+	TimeSegment       []TimeSegment `xml:"TimeSegment" json:"time_segment"`
+}
+
+type TimeSegment struct {
+	StartHour int `xml:"StartHour" json:"start_hour"`
+	StartMin  int `xml:"StartMin" json:"start_min"`
+	StartSec  int `xml:"StartSec" json:"start_sec"`
+	StopHour  int `xml:"StopHour" json:"stop_hour"`
+	StopMin   int `xml:"StopMin" json:"stop_min"`
+	StopSec   int `xml:"StopSec" json:"stop_sec"`
+}
+
+// VideoAlarmRecord 报警录像
+type VideoAlarmRecord struct {
+	RecordEnable  int `xml:"RecordEnable" json:"record_enable"`    // 是否启用 0 否 1 是
+	RecordTime    int `xml:"RecordTime" json:"record_time"`        // 录像延时时间(秒)
+	PreRecordTime int `xml:"PreRecordTime" json:"pre_record_time"` // 预录时间(秒)
+	StreamNumber  int `xml:"StreamNumber" json:"stream_number"`    // 码流编号
+}
+
+// PictureMask 视频画面遮挡
+type PictureMask struct {
+	On         int               `xml:"On" json:"on"`                       // 画面遮挡开关 0 关闭 1 打开
+	SumNum     int               `xml:"SumNum" json:"sum_num"`              // 区域总数
+	RegionList []PictureMaskItem `xml:"RegionList>Item" json:"region_list"` // 区域列表
+}
+
+type PictureMaskItem struct {
+	Seq   int    `xml:"Seq" json:"seq"`     // 区域编号 1~4
+	Point string `xml:"Point" json:"point"` // 区域左上角与右下角坐标，如 "10,10,100,100"
+}
+
+// FrameMirror 画面翻转
+type FrameMirror struct {
+	FrameMirrorType int `xml:"FrameMirrorType" json:"frame_mirror_type"` // 0 不翻转 1 水平翻转 2 垂直翻转 3 水平垂直翻转
+}
+
+// AlarmReport 报警上报开关
+type AlarmReport struct {
+	MotionDetection int `xml:"MotionDetection" json:"motion_detection"` // 移动侦测事件上报开关 0 关闭 1 打开
+	FieldDetection  int `xml:"FieldDetection" json:"field_detection"`   // 区域入侵事件上报开关 0 关闭 1 打开
+}
+
+// OSDConfig 前端 OSD 设置
+type OSDConfig struct {
+	Length     int       `xml:"Length" json:"length"`          // 配置窗口长度像素值
+	Width      int       `xml:"Width" json:"width"`            // 配置窗口宽度像素值
+	TimeX      int       `xml:"TimeX" json:"time_x"`           // 时间 X 像素坐标
+	TimeY      int       `xml:"TimeY" json:"time_y"`           // 时间 Y 像素坐标
+	TimeEnable int       `xml:"TimeEnable" json:"time_enable"` // 显示时间开关 0 关闭 1 打开
+	TimeType   int       `xml:"TimeType" json:"time_type"`     // 时间显示类型 0 YYYY-MM-DD HH:MM:SS 1 YYYY年MM月DD日 HH:MM:SS
+	TextEnable int       `xml:"TextEnable" json:"text_enable"` // 显示文字开关 0 关闭 1 打开
+	SumNum     int       `xml:"SumNum" json:"sum_num"`         // 文字总数
+	Items      []OSDItem `xml:"Item" json:"items"`
+}
+
+type OSDItem struct {
+	Text string `xml:"Text" json:"text"` // 文字内容
+	X    int    `xml:"X" json:"x"`       // 文字 X 像素坐标
+	Y    int    `xml:"Y" json:"y"`       // 文字 Y 像素坐标
 }
 
 const CMDTypeConfigDownload = "ConfigDownload"
 
-func NewBasicParamRequest(sn int32, deviceID string) []byte {
+func newConfigDownloadRequest(sn int32, deviceID, configType string) []byte {
 	c := ConfigDownloadRequest{
 		CmdType:    CMDTypeConfigDownload,
 		SN:         sn,
 		DeviceID:   deviceID,
-		ConfigType: basicParam,
+		ConfigType: configType,
 	}
 	xmlData, _ := sip.XMLEncode(c)
 	return xmlData
 }
 
+func NewBasicParamRequest(sn int32, deviceID string) []byte {
+	return newConfigDownloadRequest(sn, deviceID, ConfigTypeBasicParam)
+}
+
 func (g *GB28181API) QueryConfigDownloadBasic(deviceID string) error {
 	slog.Debug("QueryConfigDownloadBasic", "deviceID", deviceID)
 	ipc, ok := g.svr.memoryStorer.Load(deviceID)
@@ -108,23 +373,97 @@ func (g *GB28181API) QueryConfigDownloadBasic(deviceID string) error {
 	return err
 }
 
-func (g *GB28181API) handleDeviceConfig(ctx *sip.Context) {
-	slog.Debug("handleDeviceConfig", "deviceID", ctx.DeviceID)
+type ConfigInput struct {
+	DeviceID   string // 设备国标编码
+	TargetID   string // 配置目标，设备或通道国标编码
+	ConfigType string
+}
+
+// QueryConfig 设备配置查询，等待设备应答
+// GB/T28181 9.5
+func (g *GB28181API) QueryConfig(in *ConfigInput) (*ConfigParams, error) {
+	ipc, ok := g.svr.memoryStorer.Load(in.DeviceID)
+	if !ok {
+		return nil, ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return nil, ErrDeviceOffline
+	}
+
+	sn := sip.RandInt(100000, 999999)
+	key := waiterKey(in.TargetID, sn)
+	ch := g.configs.register(key)
+	defer g.configs.cancel(key)
+
+	body := newConfigDownloadRequest(int32(sn), in.TargetID, in.ConfigType) // nolint
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sipResponse(tx); err != nil {
+		return nil, err
+	}
+	resp, err := g.configs.wait(ch, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Result != "" && !strings.EqualFold(resp.Result, "OK") {
+		return nil, errors.New("device: " + resp.Result)
+	}
+	return &resp.ConfigParams, nil
+}
 
-	b := ctx.Request.Body()
-	fmt.Println(">>>", string(b))
-	// var msg DeviceConfigResponse
-	// if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
-	// 	ctx.Log.Error("handleDeviceConfig", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
-	// 	ctx.String(400, ErrXMLDecode.Error())
-	// 	return
-	// }
+// DeviceConfig 设备配置，返回设备应答结果
+// GB/T28181 9.3
+func (g *GB28181API) DeviceConfig(in *ConfigInput, params *ConfigParams) (string, error) {
+	req := NewDeviceConfig(in.TargetID)
+	req.ConfigParams = *params
+	return g.sendDeviceConfig(in.DeviceID, req)
+}
 
-	// if msg.SnapShotConfig != nil {
-	// 	slog.Debug("handleDeviceConfig", "snapShotConfig", msg.SnapShotConfig)
-	// }
+// sendDeviceConfig 发送设备配置并等待设备应答
+func (g *GB28181API) sendDeviceConfig(deviceID string, req *DeviceConfigRequest) (string, error) {
+	ipc, ok := g.svr.memoryStorer.Load(deviceID)
+	if !ok {
+		return "", ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return "", ErrDeviceOffline
+	}
+
+	sn := sip.RandInt(100000, 999999)
+	key := waiterKey(req.DeviceID, sn)
+	ch := g.controls.register(key)
+	defer g.controls.cancel(key)
+
+	req.SetSN(int32(sn)) // nolint
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, req.Marshal())
+	if err != nil {
+		return "", err
+	}
+	if _, err := sipResponse(tx); err != nil {
+		return "", err
+	}
+	resp, err := g.controls.wait(ch, 10*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return resp.Result, nil
+}
 
+// handleDeviceConfig 设备配置应答
+func (g *GB28181API) handleDeviceConfig(ctx *sip.Context) {
+	var msg ControlResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("handleDeviceConfig", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
 	ctx.String(200, "OK")
+
+	if !g.controls.notify(waiterKey(msg.DeviceID, msg.SN), &msg) && !g.controls.notify(waiterKey(ctx.DeviceID, msg.SN), &msg) {
+		ctx.Log.Debug("设备配置应答无等待方", "channelID", msg.DeviceID, "sn", msg.SN, "result", msg.Result)
+	}
 }
 
 func (g *GB28181API) sipMessageConfigDownload(ctx *sip.Context) {
@@ -137,6 +476,10 @@ func (g *GB28181API) sipMessageConfigDownload(ctx *sip.Context) {
 		return
 	}
 
+	if !g.configs.notify(waiterKey(msg.DeviceID, msg.SN), &msg) {
+		g.configs.notify(waiterKey(ctx.DeviceID, msg.SN), &msg)
+	}
+
 	if msg.BasicParam != nil {
 		ipc, ok := g.svr.memoryStorer.Load(ctx.DeviceID)
 		if !ok {
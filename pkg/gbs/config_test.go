@@ -0,0 +1,73 @@
+package gbs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+func TestConfigDownloadResponse(t *testing.T) {
+	body := `<?xml version="1.0" encoding="GB2312"?>
+<Response>
+<CmdType>ConfigDownload</CmdType>
+<SN>17</SN>
+<DeviceID>34020000001320000001</DeviceID>
+<Result>OK</Result>
+<OSDConfig>
+<Length>1920</Length><Width>1080</Width><TimeX>10</TimeX><TimeY>10</TimeY>
+<TimeEnable>1</TimeEnable><TimeType>0</TimeType><TextEnable>1</TextEnable><SumNum>1</SumNum>
+<Item><Text>大门</Text><X>100</X><Y>200</Y></Item>
+</OSDConfig>
+<PictureMask><On>1</On><SumNum>1</SumNum><RegionList><Item><Seq>1</Seq><Point>0,0,10,10</Point></Item></RegionList></PictureMask>
+</Response>`
+	var msg ConfigDownloadResponse
+	if err := sip.XMLDecode([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	osd, ok := msg.Get(ConfigTypeOSDConfig).(*OSDConfig)
+	if !ok || osd.Width != 1080 || len(osd.Items) != 1 || osd.Items[0].Text != "大门" {
+		t.Fatalf("unexpected osd %+v", msg.OSDConfig)
+	}
+	if msg.PictureMask == nil || len(msg.PictureMask.RegionList) != 1 || msg.PictureMask.RegionList[0].Point != "0,0,10,10" {
+		t.Fatalf("unexpected picture mask %+v", msg.PictureMask)
+	}
+}
+
+func TestDeviceConfigRequest(t *testing.T) {
+	var params ConfigParams
+	if err := params.Set(ConfigTypeFrameMirror, []byte(`{"frame_mirror_type":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.Set(ConfigTypeVideoParamOpt, []byte(`{}`)); err != ErrConfigReadOnly {
+		t.Fatalf("expect read only, got %v", err)
+	}
+	req := NewDeviceConfig("34020000001320000001")
+	req.ConfigParams = params
+	out := string(req.Marshal())
+	if !strings.Contains(out, "<FrameMirror><FrameMirrorType>2</FrameMirrorType></FrameMirror>") {
+		t.Fatalf("unexpected xml %s", out)
+	}
+	if strings.Contains(out, "OSDConfig") || strings.Contains(out, "SnapShotConfig") {
+		t.Fatalf("unexpected empty config in xml %s", out)
+	}
+
+	typ, err := ParseConfigType("osdconfig")
+	if err != nil || typ != ConfigTypeOSDConfig {
+		t.Fatalf("unexpected type %s %v", typ, err)
+	}
+}
+
+func TestConfigParamsGetMissing(t *testing.T) {
+	params := ConfigParams{BasicParam: &BasicParam{}}
+	if params.Get(ConfigTypeBasicParam) == nil {
+		t.Fatal("expect BasicParam")
+	}
+	// 设备未返回的配置须为 nil 接口，接口层据此返回 404
+	if v := params.Get(ConfigTypeOSDConfig); v != nil {
+		t.Fatalf("expect nil, got %#v", v)
+	}
+	if v := params.Get("unknown"); v != nil {
+		t.Fatalf("expect nil, got %#v", v)
+	}
+}
@@ -0,0 +1,123 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+// 设备控制命令
+const (
+	ControlTeleBoot     = "teleboot"      // 远程重启
+	ControlRecord       = "record"        // 开始录像
+	ControlStopRecord   = "stop_record"   // 停止录像
+	ControlSetGuard     = "set_guard"     // 布防
+	ControlResetGuard   = "reset_guard"   // 撤防
+	ControlIFame        = "iframe"        // 强制关键帧
+	ControlHomePosition = "home_position" // 看守位
+	ControlUpgrade      = "upgrade"       // 设备软件升级
+)
+
+// ErrControlCmd 不支持的设备控制命令
+var ErrControlCmd = errors.New("unsupported device control command")
+
+// ControlResponse 设备控制应答 GB/T28181 A.2.6.2
+type ControlResponse struct {
+	CmdType  string `xml:"CmdType"`
+	SN       int    `xml:"SN"`
+	DeviceID string `xml:"DeviceID"`
+	Result   string `xml:"Result"` // OK/ERROR
+}
+
+type DeviceControlInput struct {
+	DeviceID     string         // 设备国标编码
+	TargetID     string         // 控制目标，设备或通道国标编码
+	Cmd          string         // 控制命令
+	HomePosition *HomePosition  // 看守位参数，仅 home_position 有效
+	Upgrade      *DeviceUpgrade // 升级参数，仅 upgrade 有效
+}
+
+// newControlRequest 按命令生成控制消息，返回是否需要等待设备应答
+// 远程重启后设备直接重启，强制关键帧设备直接发送 I 帧，二者均无应答
+func newControlRequest(in *DeviceControlInput) (*DeviceControlRequest, bool, error) {
+	req := NewDeviceControl(in.TargetID)
+	switch in.Cmd {
+	case ControlTeleBoot:
+		return req.SetTeleBoot(), false, nil
+	case ControlIFame:
+		return req.SetIFameCmd(), false, nil
+	case ControlRecord, ControlStopRecord:
+		return req.SetRecordCmd(in.Cmd == ControlRecord), true, nil
+	case ControlSetGuard, ControlResetGuard:
+		return req.SetGuardCmd(in.Cmd == ControlSetGuard), true, nil
+	case ControlHomePosition:
+		if in.HomePosition == nil {
+			return nil, false, ErrControlCmd
+		}
+		return req.SetHomePosition(in.HomePosition), true, nil
+	case ControlUpgrade:
+		if in.Upgrade == nil {
+			return nil, false, ErrControlCmd
+		}
+		return req.SetDeviceUpgrade(in.Upgrade), true, nil
+	}
+	return nil, false, ErrControlCmd
+}
+
+// DeviceControl 设备控制，返回设备应答结果
+// GB/T28181 9.3
+func (g *GB28181API) DeviceControl(in *DeviceControlInput) (string, error) {
+	ipc, ok := g.svr.memoryStorer.Load(in.DeviceID)
+	if !ok {
+		return "", ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return "", ErrDeviceOffline
+	}
+	req, wait, err := newControlRequest(in)
+	if err != nil {
+		return "", err
+	}
+	slog.Debug("DeviceControl", "deviceID", in.DeviceID, "target", in.TargetID, "cmd", in.Cmd)
+
+	key := waiterKey(in.TargetID, int(req.SN))
+	var ch chan *ControlResponse
+	if wait {
+		ch = g.controls.register(key)
+		defer g.controls.cancel(key)
+	}
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, req.Marshal())
+	if err != nil {
+		return "", err
+	}
+	if _, err := sipResponse(tx); err != nil {
+		return "", err
+	}
+	if !wait {
+		return "OK", nil
+	}
+	resp, err := g.controls.wait(ch, 10*time.Second)
+	if err != nil {
+		return "", err
+	}
+	return resp.Result, nil
+}
+
+// sipMessageDeviceControl 设备控制应答
+func (g *GB28181API) sipMessageDeviceControl(ctx *sip.Context) {
+	var msg ControlResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageDeviceControl", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	// 部分设备应答中携带的是设备编码而非通道编码
+	if !g.controls.notify(waiterKey(msg.DeviceID, msg.SN), &msg) && !g.controls.notify(waiterKey(ctx.DeviceID, msg.SN), &msg) {
+		ctx.Log.Debug("设备控制应答无等待方", "channelID", msg.DeviceID, "sn", msg.SN, "result", msg.Result)
+	}
+}
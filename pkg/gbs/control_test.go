@@ -0,0 +1,46 @@
+package gbs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewControlRequest(t *testing.T) {
+	cases := []struct {
+		in     DeviceControlInput
+		wait   bool
+		expect string
+	}{
+		{DeviceControlInput{Cmd: ControlTeleBoot}, false, "<TeleBoot>Boot</TeleBoot>"},
+		{DeviceControlInput{Cmd: ControlIFame}, false, "<IFameCmd>Send</IFameCmd>"},
+		{DeviceControlInput{Cmd: ControlStopRecord}, true, "<RecordCmd>StopRecord</RecordCmd>"},
+		{DeviceControlInput{Cmd: ControlSetGuard}, true, "<GuardCmd>SetGuard</GuardCmd>"},
+		{
+			DeviceControlInput{Cmd: ControlHomePosition, HomePosition: &HomePosition{Enabled: 1, ResetTime: 30, PresetIndex: 2}},
+			true,
+			"<HomePosition><Enabled>1</Enabled><ResetTime>30</ResetTime><PresetIndex>2</PresetIndex></HomePosition>",
+		},
+		{
+			DeviceControlInput{Cmd: ControlUpgrade, Upgrade: &DeviceUpgrade{Firmware: "V5.7.3", FileURL: "http://127.0.0.1/fw", SessionID: "up1"}},
+			true,
+			"<DeviceUpgrade><Firmware>V5.7.3</Firmware><FileURL>http://127.0.0.1/fw</FileURL><Manufacturer></Manufacturer><SessionID>up1</SessionID></DeviceUpgrade>",
+		},
+	}
+	for _, c := range cases {
+		c.in.TargetID = "34020000001320000001"
+		req, wait, err := newControlRequest(&c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait != c.wait {
+			t.Fatalf("cmd[%s] expect wait %v", c.in.Cmd, c.wait)
+		}
+		if body := string(req.Marshal()); !strings.Contains(body, c.expect) {
+			t.Fatalf("cmd[%s] expect %s in %s", c.in.Cmd, c.expect, body)
+		}
+	}
+
+	if _, _, err := newControlRequest(&DeviceControlInput{Cmd: ControlHomePosition}); err != ErrControlCmd {
+		t.Fatalf("expect ErrControlCmd got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package gbs
+
+import (
+	"log/slog"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+)
+
+// 巡航指令 GB/T28181 A.3.5
+// 字节5 为巡航组号，字节6 为预置位号或数据低8位，字节7 高4位为数据高4位
+const (
+	ptzCruiseAdd       = 0x84
+	ptzCruiseDel       = 0x85
+	ptzCruiseSpeed     = 0x86
+	ptzCruiseDwellTime = 0x87
+	ptzCruiseStart     = 0x88
+)
+
+// 扫描指令 GB/T28181 A.3.6
+// 0x89 字节6 为 00H 开始自动扫描，01H 设置左边界，02H 设置右边界
+// 0x8A 设置扫描速度，字节6 为速度低8位，字节7 高4位为速度高4位
+const (
+	ptzScan      = 0x89
+	ptzScanSpeed = 0x8A
+)
+
+// 自动扫描动作
+const (
+	ScanActionStart = "start"
+	ScanActionLeft  = "left"
+	ScanActionRight = "right"
+	ScanActionSpeed = "speed"
+	ScanActionStop  = "stop"
+)
+
+var scanActions = map[string]byte{
+	ScanActionStart: 0x00,
+	ScanActionLeft:  0x01,
+	ScanActionRight: 0x02,
+}
+
+// encodePTZCmd12 编码 12 位数据，低8位在字节6，高4位在字节7
+func encodePTZCmd12(cmd, group byte, value int) (string, error) {
+	if value < 0 || value > 0xFFF {
+		return "", ErrPTZParam
+	}
+	return encodePTZCmd(cmd, group, byte(value&0xFF), byte(value>>8)), nil // nolint
+}
+
+type CruiseInput struct {
+	Channel   *gb28181.Channel
+	CruiseID  int   // 巡航组号 0~255
+	Points    []int // 巡航点，预置位编号
+	Speed     int   // 巡航速度 0~4095
+	DwellTime int   // 停留时间(秒) 0~4095
+}
+
+// SetCruise 下发巡航轨迹，先删除整组再依次加入巡航点，最后设置速度与停留时间
+func (g *GB28181API) SetCruise(in *CruiseInput) error {
+	if in.CruiseID < 0 || in.CruiseID > 255 || len(in.Points) == 0 {
+		return ErrPTZParam
+	}
+	group := byte(in.CruiseID) // nolint
+
+	cmds := make([]string, 0, len(in.Points)+3)
+	cmds = append(cmds, encodePTZCmd(ptzCruiseDel, group, 0, 0))
+	for _, p := range in.Points {
+		if p < 1 || p > 255 {
+			return ErrPresetID
+		}
+		cmds = append(cmds, encodePTZCmd(ptzCruiseAdd, group, byte(p), 0)) // nolint
+	}
+	speed, err := encodePTZCmd12(ptzCruiseSpeed, group, in.Speed)
+	if err != nil {
+		return err
+	}
+	dwell, err := encodePTZCmd12(ptzCruiseDwellTime, group, in.DwellTime)
+	if err != nil {
+		return err
+	}
+	cmds = append(cmds, speed, dwell)
+
+	slog.Debug("SetCruise", "deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "cruiseID", in.CruiseID)
+	for _, cmd := range cmds {
+		if err := g.sendPTZCmd(in.Channel, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DelCruise 删除整组巡航轨迹
+func (g *GB28181API) DelCruise(channel *gb28181.Channel, cruiseID int) error {
+	if cruiseID < 0 || cruiseID > 255 {
+		return ErrPTZParam
+	}
+	return g.sendPTZCmd(channel, encodePTZCmd(ptzCruiseDel, byte(cruiseID), 0, 0)) // nolint
+}
+
+// StartCruise 开始巡航
+func (g *GB28181API) StartCruise(channel *gb28181.Channel, cruiseID int) error {
+	if cruiseID < 0 || cruiseID > 255 {
+		return ErrPTZParam
+	}
+	return g.sendPTZCmd(channel, encodePTZCmd(ptzCruiseStart, byte(cruiseID), 0, 0)) // nolint
+}
+
+// StopCruise 停止巡航，标准中巡航/扫描均通过 PTZ 停止指令结束
+func (g *GB28181API) StopCruise(channel *gb28181.Channel) error {
+	return g.sendPTZCmd(channel, encodePTZCmd(0x00, 0, 0, 0))
+}
+
+type ScanInput struct {
+	Channel *gb28181.Channel
+	ScanID  int    // 扫描组号 0~255
+	Action  string // start/left/right/speed/stop
+	Speed   int    // 扫描速度 0~4095，仅 speed 动作有效
+}
+
+// ScanControl 自动扫描控制
+func (g *GB28181API) ScanControl(in *ScanInput) error {
+	if in.ScanID < 0 || in.ScanID > 255 {
+		return ErrPTZParam
+	}
+	group := byte(in.ScanID) // nolint
+
+	var cmd string
+	switch in.Action {
+	case ScanActionSpeed:
+		c, err := encodePTZCmd12(ptzScanSpeed, group, in.Speed)
+		if err != nil {
+			return err
+		}
+		cmd = c
+	case ScanActionStop:
+		cmd = encodePTZCmd(0x00, 0, 0, 0)
+	default:
+		v, ok := scanActions[in.Action]
+		if !ok {
+			return ErrPTZAction
+		}
+		cmd = encodePTZCmd(ptzScan, group, v, 0)
+	}
+	slog.Debug("ScanControl", "deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "action", in.Action, "cmd", cmd)
+	return g.sendPTZCmd(in.Channel, cmd)
+}
@@ -38,9 +38,14 @@ type Device struct {
 	LastKeepaliveAt time.Time
 	LastRegisterAt  time.Time
 	Expires         int
+	// registerCallID 注册的 Call-ID，设备重启后重新注册会变化
+	registerCallID string
 
 	keepaliveInterval uint16
 	keepaliveTimeout  uint16
+
+	profileMutex sync.RWMutex
+	profile      *Profile
 }
 
 func NewDevice(conn sip.Connection, d *gb28181.Device) *Device {
@@ -68,6 +73,7 @@ func NewDevice(conn sip.Connection, d *gb28181.Device) *Device {
 		LastRegisterAt:  d.RegisteredAt.Time,
 		IsOnline:        d.IsOnline,
 		Password:        d.Password,
+		profile:         DeviceProfile(d),
 	}
 
 	return &c
@@ -115,6 +121,23 @@ func (d *Device) To() *sip.Address {
 	return d.to
 }
 
+// Profile 设备适配，未识别时为默认适配
+func (d *Device) Profile() *Profile {
+	d.profileMutex.RLock()
+	defer d.profileMutex.RUnlock()
+	if d.profile == nil {
+		return profiles[0]
+	}
+	return d.profile
+}
+
+// SetProfile 设备属性或指定的适配变化时更新
+func (d *Device) SetProfile(p *Profile) {
+	d.profileMutex.Lock()
+	defer d.profileMutex.Unlock()
+	d.profile = p
+}
+
 var _ Targeter = &Device{}
 
 type Channel struct {
@@ -136,6 +159,11 @@ func (c *Channel) Source() net.Addr {
 	return c.device.source
 }
 
+// Profile 通道沿用所属设备的适配
+func (c *Channel) Profile() *Profile {
+	return c.device.Profile()
+}
+
 // To implements Targeter.
 func (c *Channel) To() *sip.Address {
 	return c.to
@@ -255,12 +283,27 @@ type Channels struct {
 	// Memo 备注（用来标示通道信息）
 	MeMo string `json:"memo"  gorm:"column:memo"`
 	// Name 通道名称（设备端设置名称）
-	Name         string `xml:"Name" json:"name"  gorm:"column:name"`
-	Manufacturer string `xml:"Manufacturer" json:"manufacturer"  gorm:"column:manufacturer"`
-	Model        string `xml:"Model" json:"model"  gorm:"column:model"`
-	Owner        string `xml:"Owner"  json:"owner"  gorm:"column:owner"`
-	CivilCode    string `xml:"CivilCode" json:"civilcode"  gorm:"column:civilcode"`
-	// Address ip地址
+	Name         string  `xml:"Name" json:"name"  gorm:"column:name"`
+	Manufacturer string  `xml:"Manufacturer" json:"manufacturer"  gorm:"column:manufacturer"`
+	Model        string  `xml:"Model" json:"model"  gorm:"column:model"`
+	Owner        string  `xml:"Owner"  json:"owner"  gorm:"column:owner"`
+	CivilCode    string  `xml:"CivilCode" json:"civilcode"  gorm:"column:civilcode"`
+	Block        string  `xml:"Block" json:"block" gorm:"-"`
+	IPAddress    string  `xml:"IPAddress" json:"ipaddress" gorm:"-"`
+	Port         int     `xml:"Port" json:"port" gorm:"-"`
+	Longitude    float64 `xml:"Longitude" json:"longitude" gorm:"-"`
+	Latitude     float64 `xml:"Latitude" json:"latitude" gorm:"-"`
+	CertNum      string  `xml:"CertNum" json:"certnum" gorm:"-"`
+	Certifiable  int     `xml:"Certifiable" json:"certifiable" gorm:"-"`
+	ErrCode      int     `xml:"ErrCode" json:"errcode" gorm:"-"`
+	EndTime      string  `xml:"EndTime" json:"endtime" gorm:"-"`
+	// Info 扩展信息，云台类型、位置类型、分辨率等
+	Info *gb28181.ChannelInfo `xml:"Info" json:"info" gorm:"-"`
+	// ParentID 父设备/区域/系统 ID
+	ParentID string `xml:"ParentID" json:"parentid" gorm:"-"`
+	// BusinessGroupID 虚拟组织所属的业务分组 ID
+	BusinessGroupID string `xml:"BusinessGroupID" json:"businessgroupid" gorm:"-"`
+	// Address 安装地址
 	Address     string `xml:"Address"  json:"address"  gorm:"column:address"`
 	Parental    int    `xml:"Parental"  json:"parental"  gorm:"column:parental"`
 	SafetyWay   int    `xml:"SafetyWay"  json:"safetyway"  gorm:"column:safetyway"`
@@ -268,6 +311,8 @@ type Channels struct {
 	Secrecy     int    `xml:"Secrecy" json:"secrecy"  gorm:"column:secrecy"`
 	// Status 状态  on 在线
 	Status string `xml:"Status"  json:"status"  gorm:"column:status"`
+	// Event 目录订阅通知的变化事件
+	Event string `xml:"Event" json:"-" gorm:"-"`
 	// Active 最后活跃时间
 	Active int64  `json:"active"  gorm:"column:active"`
 	URIStr string ` json:"uri"  gorm:"column:uri"`
@@ -0,0 +1,204 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/gowvp/gb28181/pkg/zlm"
+)
+
+// NotifyTypeMediaEnd 媒体流发送结束 GB/T28181 A.2.5.5
+const NotifyTypeMediaEnd = "121"
+
+// MediaStatusNotify 媒体通知
+type MediaStatusNotify struct {
+	XMLName    xml.Name `xml:"Notify"`
+	CmdType    string   `xml:"CmdType"`
+	SN         int      `xml:"SN"`
+	DeviceID   string   `xml:"DeviceID"`
+	NotifyType string   `xml:"NotifyType"`
+}
+
+type DownloadInput struct {
+	Channel    *gb28181.Channel
+	SMS        *sms.MediaServer
+	StreamMode int8
+	StreamID   string // 下载流 ID，与下载任务 ID 一致
+	Start, End int64  // 录像时间段，unix 秒
+	Speed      int    // 期望下载倍速
+}
+
+func downloadKey(streamID string) string {
+	return "download:" + streamID
+}
+
+// HasDownload 是否为进行中的下载流
+func (g *GB28181API) HasDownload(streamID string) bool {
+	_, ok := g.streams.Load(downloadKey(streamID))
+	return ok
+}
+
+// Download 录像文件下载，返回设备应答的下载倍速
+// GB/T28181 9.9
+func (g *GB28181API) Download(in *DownloadInput) (int, error) {
+	log := slog.With("deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "stream", in.StreamID)
+	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
+	if !ok {
+		return 0, ErrChannelNotExist
+	}
+
+	ch.device.playMutex.Lock()
+	defer ch.device.playMutex.Unlock()
+
+	if !ch.device.IsOnline {
+		return 0, ErrDeviceOffline
+	}
+
+	key := downloadKey(in.StreamID)
+	stream, ok := g.streams.LoadOrStore(key, &Streams{
+		T:         1,
+		DeviceID:  in.Channel.DeviceID,
+		ChannelID: in.Channel.ChannelID,
+		StreamID:  in.StreamID,
+		svr:       in.SMS,
+	})
+	if ok {
+		log.Debug("下载流已存在")
+		return in.Speed, nil
+	}
+
+	ssrc, err := g.ssrcs.Alloc(true)
+	if err != nil {
+		g.streams.Delete(key)
+		return 0, err
+	}
+	stream.ssrc = ssrc
+
+	resp, err := g.sms.OpenRTPServer(in.SMS, zlm.OpenRTPServerRequest{
+		TCPMode:  in.StreamMode,
+		StreamID: in.StreamID,
+		SSRC:     ssrc,
+	})
+	if err != nil {
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
+		return 0, err
+	}
+
+	playIn := PlayInput{Channel: in.Channel, SMS: in.SMS, StreamMode: in.StreamMode}
+	if err := g.sipPlayPush2(ch, &playIn, resp.Port, stream, inviteSession{
+		name:          "Download",
+		start:         in.Start,
+		end:           in.End,
+		downloadSpeed: in.Speed,
+	}); err != nil {
+		log.Debug("下载 INVITE 失败", "err", err)
+		g.closeRTPServer(stream)
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
+		return 0, err
+	}
+
+	// 设备可能不支持请求的倍速，以应答为准
+	speed := in.Speed
+	if stream.Answer != nil {
+		if v := sdpDownloadSpeed(stream.Answer); v > 0 {
+			speed = v
+		}
+	}
+	return speed, nil
+}
+
+// StopDownload 停止下载
+func (g *GB28181API) StopDownload(streamID string) error {
+	stream, ok := g.streams.LoadAndDelete(downloadKey(streamID))
+	if !ok {
+		return nil
+	}
+	g.ssrcs.Release(stream.ssrc)
+	if stream.Resp == nil {
+		return nil
+	}
+	ch, ok := g.svr.memoryStorer.GetChannel(stream.DeviceID, stream.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+
+	req := sip.NewRequestFromResponse(sip.MethodBYE, stream.Resp)
+	req.SetDestination(ch.Source())
+	req.SetConnection(ch.Conn())
+	_, err := g.svr.Request(req)
+
+	// 主动关闭收流端口，使流尽快注销以结束 mp4 录制
+	g.closeRTPServer(stream)
+	return err
+}
+
+// findMediaStream 查找媒体通知对应的回放/下载会话，优先按 Call-ID 匹配，兼容未携带会话 Call-ID 的设备按通道匹配
+func (g *GB28181API) findMediaStream(callID, deviceID, channelID string) (string, *Streams) {
+	var (
+		key    string
+		stream *Streams
+	)
+	g.streams.Range(func(k string, v *Streams) bool {
+		if !strings.HasPrefix(k, "download:") && !strings.HasPrefix(k, "playback:") {
+			return true
+		}
+		if callID != "" && v.CallID == callID {
+			key, stream = k, v
+			return false
+		}
+		if stream == nil && v.DeviceID == deviceID && v.ChannelID == channelID {
+			key, stream = k, v
+		}
+		return true
+	})
+	return key, stream
+}
+
+// sipMessageMediaStatus 媒体通知，设备录像发送完毕后上报 NotifyType=121
+func (g *GB28181API) sipMessageMediaStatus(ctx *sip.Context) {
+	var msg MediaStatusNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageMediaStatus", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	if msg.NotifyType != NotifyTypeMediaEnd {
+		return
+	}
+
+	var callID string
+	if v, ok := ctx.Request.CallID(); ok {
+		callID = string(*v)
+	}
+	key, stream := g.findMediaStream(callID, ctx.DeviceID, msg.DeviceID)
+	if stream == nil {
+		ctx.Log.Warn("媒体通知未找到会话", "callID", callID, "channelID", msg.DeviceID)
+		return
+	}
+
+	if strings.HasPrefix(key, "playback:") {
+		_ = g.StopPlayback(stream.StreamID)
+		return
+	}
+
+	// 下载完成，发送 BYE 结束会话，ZLM 流注销后完成录制并通过 on_record_mp4 回调文件信息
+	if err := g.core.EditDownload(stream.StreamID, func(d *gb28181.Download) {
+		d.Progress = 100
+	}); err != nil {
+		ctx.Log.Error("EditDownload", "err", err, "stream", stream.StreamID)
+	}
+	// 等待媒体服务器收完缓冲中的数据
+	time.AfterFunc(time.Second, func() {
+		_ = g.StopDownload(stream.StreamID)
+	})
+}
@@ -13,4 +13,28 @@ var (
 
 	ErrDeviceOffline  = errors.New("device offline")
 	ErrChannelOffline = errors.New("channel offline")
+
+	ErrStreamNotExist = errors.New("stream not exist")
+	ErrSSRCExhausted  = errors.New("no ssrc available")
+	ErrStreamProfile  = errors.New("unsupported stream profile")
+	ErrSDPAnswer      = errors.New("invalid sdp answer")
+	ErrSSRCMismatch   = errors.New("answered ssrc does not match the requested one")
+	ErrPlayTransport  = errors.New("no rtp received on any transport")
+)
+
+var (
+	ErrPTZAction = errors.New("unsupported ptz action")
+	ErrPresetID  = errors.New("preset id must be between 1 and 255")
+	ErrPTZParam  = errors.New("ptz param out of range")
+
+	ErrPlaybackAction = errors.New("unsupported playback action")
+
+	ErrBroadcastTimeout = errors.New("broadcast timeout, device did not invite")
+	ErrBroadcastRefused = errors.New("broadcast refused by device")
+)
+
+var (
+	ErrCascadeTimeout   = errors.New("superior platform response timeout")
+	ErrCascadeSource    = errors.New("cascade stream source not set")
+	ErrCascadeTransport = errors.New("unsupported cascade media transport")
 )
@@ -1,29 +1,61 @@
 package gbs
 
 import (
+	"encoding/hex"
+	"errors"
 	"log/slog"
 
 	"github.com/gowvp/gb28181/pkg/gbs/sip"
 )
 
-func (g *GB28181API) QuerySnapshot(deviceID, channelID string) error {
-	slog.Debug("QuerySnapshot", "deviceID", deviceID)
-	ipc, ok := g.svr.memoryStorer.Load(deviceID)
-	if !ok {
-		return ErrDeviceOffline
+// 单次抓拍最多张数 GB/T28181-2022 A.2.3.1.11
+const maxSnapNum = 10
+
+var ErrSnapshotParam = errors.New("snap num must be between 1 and 10, interval must be at least 1 second")
+
+// SnapshotInput 图像抓拍参数
+type SnapshotInput struct {
+	DeviceID  string // 设备国标编码
+	ChannelID string // 通道国标编码
+	SnapNum   int    // 连拍张数
+	Interval  int    // 单张抓拍间隔(秒)
+	UploadURL string // 图像上传地址
+	SessionID string // 会话 ID，用于关联上传的图像
+}
+
+// QuerySnapshot 图像抓拍，设备通过 http 将图像上传至 UploadURL
+// 返回设备应答结果
+func (g *GB28181API) QuerySnapshot(in *SnapshotInput) (string, error) {
+	slog.Debug("QuerySnapshot", "deviceID", in.DeviceID, "channelID", in.ChannelID, "sessionID", in.SessionID)
+	if in.SnapNum < 1 || in.SnapNum > maxSnapNum || in.Interval < 1 {
+		return "", ErrSnapshotParam
 	}
+	req := NewDeviceConfig(in.ChannelID).SetSnapShotConfig(&SnapShot{
+		SnapNum:   in.SnapNum,
+		Interval:  in.Interval,
+		UploadURL: in.UploadURL,
+		SessionID: in.SessionID,
+	})
+	return g.sendDeviceConfig(in.DeviceID, req)
+}
 
-	body := NewDeviceConfig(channelID).SetSnapShotConfig(&SnapShot{
-		SnapNum:   1,
-		Interval:  1,
-		UploadURL: "http://192.168.10.31:15123/gb28181/snapshot",
-		SessionID: "1234567890",
-	}).Marshal()
+// UploadSnapShotFinishedNotify 图像抓拍传输完成通知
+type UploadSnapShotFinishedNotify struct {
+	CmdType      string   `xml:"CmdType"`
+	SN           int      `xml:"SN"`
+	DeviceID     string   `xml:"DeviceID"`
+	SessionID    string   `xml:"SessionID"`
+	SnapShotList []string `xml:"SnapShotList>SnapShotFileID"`
+}
 
-	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, body)
-	if err != nil {
-		return err
+// sipMessageUploadSnapShotFinished 设备上传完成后的通知，图像已由 http 接收，此处仅记录
+func (g *GB28181API) sipMessageUploadSnapShotFinished(ctx *sip.Context) {
+	var msg UploadSnapShotFinishedNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageUploadSnapShotFinished", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
 	}
-	_, err = sipResponse(tx)
-	return err
+	ctx.Log.Info("图像抓拍上传完成", "channelID", msg.DeviceID, "sessionID", msg.SessionID, "files", msg.SnapShotList)
+	ctx.String(200, "OK")
 }
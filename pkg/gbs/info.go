@@ -44,6 +44,10 @@ func (g GB28181API) sipMessageDeviceInfo(ctx *sip.Context) {
 	}
 
 	if err := g.core.Edit(ctx.DeviceID, func(d *gb28181.Device) {
+		// 首次识别到厂商时，按适配设置默认的数据传输模式
+		if d.Ext.Manufacturer == "" && msg.Manufacturer != "" && d.Profile == "" {
+			d.StreamMode = MatchProfile("", msg.Manufacturer, d.Ext.UserAgent).StreamMode
+		}
 		d.Ext.Firmware = msg.Firmware
 		d.Ext.Manufacturer = msg.Manufacturer
 		d.Ext.Model = msg.Model
@@ -1,6 +1,10 @@
 package gbs
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
 
 const snapShotConfig = "SnapShotConfig" // 图像抓拍配置
 
@@ -11,6 +15,7 @@ type DeviceConfigRequest struct {
 	SN             int32     `xml:"SN"`       // 命令序列号(必选)
 	DeviceID       string    `xml:"DeviceID"` // 目标设备编码(必选)
 	SnapShotConfig *SnapShot `xml:"SnapShotConfig"`
+	ConfigParams
 }
 
 func NewDeviceConfig(deviceID string) *DeviceConfigRequest {
@@ -35,3 +40,112 @@ func (d *DeviceConfigRequest) Marshal() []byte {
 	b, _ := xml.Marshal(d)
 	return b
 }
+
+// 设备控制 A.2.3.1
+type DeviceControlRequest struct {
+	XMLName       xml.Name       `xml:"Control"`
+	CmdType       string         `xml:"CmdType"`                 // 命令类型：设备控制(必选)
+	SN            int32          `xml:"SN"`                      // 命令序列号(必选)
+	DeviceID      string         `xml:"DeviceID"`                // 目标设备编码(必选)
+	PTZCmd        string         `xml:"PTZCmd,omitempty"`        // 球机/云台控制命令(可选)
+	TeleBoot      string         `xml:"TeleBoot,omitempty"`      // 远程启动控制命令(可选)
+	RecordCmd     string         `xml:"RecordCmd,omitempty"`     // 录像控制命令(可选)
+	GuardCmd      string         `xml:"GuardCmd,omitempty"`      // 报警布防/撤防命令(可选)
+	AlarmCmd      string         `xml:"AlarmCmd,omitempty"`      // 报警复位命令(可选)
+	IFameCmd      string         `xml:"IFameCmd,omitempty"`      // 强制关键帧命令(可选)
+	HomePosition  *HomePosition  `xml:"HomePosition,omitempty"`  // 看守位控制命令(可选)
+	DeviceUpgrade *DeviceUpgrade `xml:"DeviceUpgrade,omitempty"` // 设备软件升级命令(可选) GB/T28181-2022
+	Info          *ControlInfo   `xml:"Info,omitempty"`
+}
+
+// HomePosition 看守位
+type HomePosition struct {
+	Enabled     int `xml:"Enabled" json:"enabled"`          // 1 开启 0 关闭
+	ResetTime   int `xml:"ResetTime" json:"reset_time"`     // 自动归位时间间隔(秒)
+	PresetIndex int `xml:"PresetIndex" json:"preset_index"` // 调用预置位编号
+}
+
+// DeviceUpgrade 设备软件升级 GB/T28181-2022 A.2.3.1.12
+type DeviceUpgrade struct {
+	Firmware     string `xml:"Firmware" json:"firmware"`         // 固件版本
+	FileURL      string `xml:"FileURL" json:"file_url"`          // 固件下载地址
+	Manufacturer string `xml:"Manufacturer" json:"manufacturer"` // 设备厂商
+	SessionID    string `xml:"SessionID" json:"session_id"`      // 会话 ID，用于关联升级结果通知
+}
+
+// ControlInfo 控制扩展信息
+type ControlInfo struct {
+	ControlPriority int `xml:"ControlPriority,omitempty"` // 控制优先级，取值 1~5，5 最高
+	AlarmMethod     int `xml:"AlarmMethod,omitempty"`     // 复位报警的报警方式，缺省时复位全部
+	AlarmType       int `xml:"AlarmType,omitempty"`       // 复位报警的报警类型
+}
+
+func NewDeviceControl(deviceID string) *DeviceControlRequest {
+	return &DeviceControlRequest{
+		CmdType:  "DeviceControl",
+		SN:       int32(sip.RandInt(100000, 999999)), // nolint
+		DeviceID: deviceID,
+	}
+}
+
+func (d *DeviceControlRequest) SetPTZCmd(cmd string) *DeviceControlRequest {
+	d.PTZCmd = cmd
+	d.Info = &ControlInfo{ControlPriority: 5}
+	return d
+}
+
+// SetResetAlarm 报警复位，method/type 为 0 时不携带
+func (d *DeviceControlRequest) SetResetAlarm(method, typ int) *DeviceControlRequest {
+	d.AlarmCmd = "ResetAlarm"
+	if method > 0 || typ > 0 {
+		d.Info = &ControlInfo{AlarmMethod: method, AlarmType: typ}
+	}
+	return d
+}
+
+// SetTeleBoot 远程重启
+func (d *DeviceControlRequest) SetTeleBoot() *DeviceControlRequest {
+	d.TeleBoot = "Boot"
+	return d
+}
+
+// SetRecordCmd 设备端开始/停止录像
+func (d *DeviceControlRequest) SetRecordCmd(start bool) *DeviceControlRequest {
+	d.RecordCmd = "StopRecord"
+	if start {
+		d.RecordCmd = "Record"
+	}
+	return d
+}
+
+// SetGuardCmd 布防/撤防
+func (d *DeviceControlRequest) SetGuardCmd(set bool) *DeviceControlRequest {
+	d.GuardCmd = "ResetGuard"
+	if set {
+		d.GuardCmd = "SetGuard"
+	}
+	return d
+}
+
+// SetIFameCmd 强制关键帧
+func (d *DeviceControlRequest) SetIFameCmd() *DeviceControlRequest {
+	d.IFameCmd = "Send"
+	return d
+}
+
+// SetHomePosition 看守位
+func (d *DeviceControlRequest) SetHomePosition(home *HomePosition) *DeviceControlRequest {
+	d.HomePosition = home
+	return d
+}
+
+// SetDeviceUpgrade 设备软件升级
+func (d *DeviceControlRequest) SetDeviceUpgrade(upgrade *DeviceUpgrade) *DeviceControlRequest {
+	d.DeviceUpgrade = upgrade
+	return d
+}
+
+func (d *DeviceControlRequest) Marshal() []byte {
+	b, _ := sip.XMLEncode(d)
+	return b
+}
@@ -4,8 +4,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/gowvp/gb28181/internal/core/gb28181"
 	"github.com/gowvp/gb28181/internal/core/sms"
@@ -19,19 +20,33 @@ type PlayInput struct {
 	Channel    *gb28181.Channel
 	SMS        *sms.MediaServer
 	StreamMode int8
+	// StreamProfile 主/子码流，流 ID 由 StreamProfile.StreamID 生成
+	StreamProfile StreamProfile
+	// Video f= 视频参数，为空时不携带
+	Video *VideoParam
 }
 
 type StopPlayInput struct {
-	Channel *gb28181.Channel
+	Channel       *gb28181.Channel
+	StreamProfile StreamProfile
+}
+
+// rtpWaitTimeout 点播后等待设备推流的时间，与流媒体 rtp 收流超时一致
+const rtpWaitTimeout = 15 * time.Second
+
+// playKey 实时点播会话，各码流独立
+func playKey(ch *gb28181.Channel, profile StreamProfile) string {
+	return "play:" + ch.DeviceID + ":" + ch.ChannelID + ":" + profile.String()
 }
 
 // stopPlay 不加锁的
 func (g *GB28181API) stopPlay(ch *Channel, in *StopPlayInput) error {
-	key := "play:" + in.Channel.DeviceID + ":" + in.Channel.ChannelID
+	key := playKey(in.Channel, in.StreamProfile)
 	stream, ok := g.streams.LoadAndDelete(key)
 	if !ok {
 		return nil
 	}
+	g.ssrcs.Release(stream.ssrc)
 
 	if stream.Resp == nil {
 		return nil
@@ -48,6 +63,14 @@ func (g *GB28181API) stopPlay(ch *Channel, in *StopPlayInput) error {
 
 // StopPlay 加锁的停止播放
 func (g *GB28181API) StopPlay(in *StopPlayInput) error {
+	streamID := in.StreamProfile.StreamID(in.Channel.ID)
+	g.plays.Delete(streamID)
+	g.viewers.clear(streamID)
+	return g.closePlay(in)
+}
+
+// closePlay 停止播放并保留传输模式切换记录
+func (g *GB28181API) closePlay(in *StopPlayInput) error {
 	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
 	if !ok {
 		return ErrDeviceNotExist
@@ -56,14 +79,29 @@ func (g *GB28181API) StopPlay(in *StopPlayInput) error {
 	ch.device.playMutex.Lock()
 	defer ch.device.playMutex.Unlock()
 
-	defer func() {
-		g.svr.gb.core.EditPlaying(in.Channel.DeviceID, in.Channel.ChannelID, false)
-	}()
+	// 播放状态以主码流为准
+	if in.StreamProfile == StreamMain {
+		defer func() {
+			g.svr.gb.core.EditPlaying(in.Channel.DeviceID, in.Channel.ChannelID, false)
+		}()
+	}
 	return g.stopPlay(ch, in)
 }
 
+// Play 实时点播，已有可用的流时直接复用，收流超时后由 PlayTimeout 切换传输模式重试
 func (g *GB28181API) Play(in *PlayInput) error {
-	log := slog.With("deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID)
+	trace := newPlayTrace(in)
+	if err := g.play(in, trace); err != nil {
+		trace.fail(err.Error())
+		return err
+	}
+	return nil
+}
+
+// play trace 不为空时为新的点播请求，可复用已有的流，重新 INVITE 时记录传输模式切换
+// trace 为空时为切换传输模式的重试，总是重新 INVITE
+func (g *GB28181API) play(in *PlayInput, trace *playTrace) error {
+	log := slog.With("deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "profile", in.StreamProfile)
 	log.Info("开始播放流程")
 	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
 	if !ok {
@@ -78,42 +116,95 @@ func (g *GB28181API) Play(in *PlayInput) error {
 		return ErrDeviceOffline
 	}
 
-	// 播放中
-	key := "play:" + in.Channel.DeviceID + ":" + in.Channel.ChannelID
-	stream, ok := g.streams.LoadOrStore(key, &Streams{})
-	if ok {
-		log.Debug("PLAY 已存在流")
-		// TODO: 临时解决方案，每次播放，先停止再播放
-		// https://github.com/gowvp/gb28181/issues/16
+	// 播放中，多个观看者共用一路点播，有观看者时直接复用
+	// 无观看者时仅在流媒体上的流已不存在或需按新的视频参数点播时重新 INVITE
+	key := playKey(in.Channel, in.StreamProfile)
+	if stream, ok := g.streams.Load(key); ok {
+		if trace != nil && in.Video.IsZero() {
+			if n := g.viewers.count(stream.StreamID); n > 0 || g.streamAlive(in.SMS, stream) {
+				log.Debug("PLAY 复用已存在的流", "viewers", n)
+				return nil
+			}
+		}
+		log.Debug("PLAY 已存在的流不可用，重新点播")
 		if err := g.stopPlay(ch, &StopPlayInput{
-			Channel: in.Channel,
+			Channel:       in.Channel,
+			StreamProfile: in.StreamProfile,
 		}); err != nil {
 			slog.Error("stop play failed", "err", err)
 		}
 	}
 
-	log.Debug("1. 开启RTP服务器等待接收视频流")
-	// 开启RTP服务器等待接收视频流
+	ssrc, err := g.ssrcs.Alloc(false)
+	if err != nil {
+		return err
+	}
+	stream := &Streams{
+		DeviceID:  in.Channel.DeviceID,
+		ChannelID: in.Channel.ChannelID,
+		StreamID:  in.StreamProfile.StreamID(in.Channel.ID),
+		S:         time.Now(),
+		ssrc:      ssrc,
+		svr:       in.SMS,
+	}
+	g.streams.Store(key, stream)
+	if trace != nil {
+		g.plays.Store(stream.StreamID, trace)
+	}
+
+	log.Debug("1. 开启RTP服务器等待接收视频流", "ssrc", ssrc)
+	// 开启RTP服务器等待接收视频流，仅接收指定 ssrc 的流
 	resp, err := g.sms.OpenRTPServer(in.SMS, zlm.OpenRTPServerRequest{
 		TCPMode:  in.StreamMode,
-		StreamID: in.Channel.ID,
+		StreamID: stream.StreamID,
+		SSRC:     ssrc,
 	})
 	if err != nil {
 		log.Debug("1.1. 开启RTP服务器失败", "err", err)
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
 		return err
 	}
 
 	log.Debug("2. 发送SDP请求", "port", resp.Port)
-	if err := g.sipPlayPush2(ch, in, resp.Port, stream); err != nil {
+	if err := g.sipPlayPush2(ch, in, resp.Port, stream, inviteSession{name: "Play"}); err != nil {
 		log.Debug("2.1. 发送SDP请求失败", "err", err)
+		g.closeRTPServer(stream)
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
 		return err
 	}
 
-	g.svr.gb.core.EditPlaying(in.Channel.DeviceID, in.Channel.ChannelID, true)
+	if in.StreamProfile == StreamMain {
+		g.svr.gb.core.EditPlaying(in.Channel.DeviceID, in.Channel.ChannelID, true)
+	}
 
 	return nil
 }
 
+// streamAlive 无观看者时重新 INVITE 前确认流是否已不存在
+// 已收到流时以流媒体上任一协议的流存在为准，不依赖某个协议是否开启
+// 尚未收到流时在等待推流的时间内视为可用，避免打断进行中的点播
+func (g *GB28181API) streamAlive(svr *sms.MediaServer, stream *Streams) bool {
+	if !stream.Stream {
+		return time.Since(stream.S) < rtpWaitTimeout
+	}
+	items, err := g.sms.GetMediaList(svr, zlm.GetMediaListRequest{
+		App:    "rtp",
+		Stream: stream.StreamID,
+	})
+	if err != nil {
+		// 无法确认时不打断正在观看的流
+		slog.Warn("GetMediaList", "stream", stream.StreamID, "err", err)
+		return true
+	}
+	if len(items) == 0 {
+		return false
+	}
+	slog.Debug("流在线", "stream", stream.StreamID, "readers", items[0].TotalReaderCount)
+	return true
+}
+
 // GetIP 判断输入字符串并返回对应的IP地址
 // 输入可能是IPv4地址、域名、空值或其他非法值
 func GetIP(input string) (string, error) {
@@ -162,39 +253,45 @@ func GetIP(input string) (string, error) {
 	return input, fmt.Errorf("域名没有解析到IP地址")
 }
 
-func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *Streams) error {
-	name := "Play"
+// inviteSession INVITE 会话参数，区分实时点播/历史回放
+type inviteSession struct {
+	name          string // SDP s= 字段，Play/Playback/Download
+	start, end    int64  // 回放时间段，unix 秒，实时点播为 0
+	downloadSpeed int    // 下载倍速，仅 Download 有效
+}
+
+func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *Streams, sess inviteSession) error {
+	name := sess.name
+	profile := ch.Profile()
 	protocal := "TCP/RTP/AVP"
 	if in.StreamMode == 0 {
 		protocal = "RTP/AVP"
 	}
 
-	// if  {
-	// name = "Playback"
-	// protocal = "RTP/RTCP"
-	// }
-
-	video := sdp.Media{
-		Description: sdp.MediaDescription{
-			Type:     "video",
-			Port:     port,
-			Formats:  []string{"96", "97", "98"},
-			Protocol: protocal,
-		},
+	newMedia := func(typ string, formats []SDPFormat) sdp.Media {
+		m := newRecvMedia(typ, port, protocal, formats)
+		if in.StreamMode == 1 || in.StreamMode == 2 {
+			m.AddAttribute("setup", profile.setup(in.StreamMode))
+			m.AddAttribute("connection", "new")
+		}
+		return m
 	}
-	video.AddAttribute("recvonly")
 
-	switch in.StreamMode {
-	case 1:
-		video.AddAttribute("setup", "passive")
-		video.AddAttribute("connection", "new")
-	case 2:
-		video.AddAttribute("setup", "active")
-		video.AddAttribute("connection", "new")
+	video := newMedia("video", profile.Formats)
+	// 子码流按厂商约定的属性请求，主码流不携带以兼容不识别该属性的设备
+	if in.StreamProfile != StreamMain {
+		for _, attr := range profile.StreamAttrs {
+			video.AddAttribute(attr, strconv.Itoa(int(in.StreamProfile)))
+		}
+	}
+	if name == "Download" {
+		video.AddAttribute("downloadspeed", fmt.Sprint(sess.downloadSpeed))
+	}
+	medias := []sdp.Media{video}
+	// 音频与视频复用 PS 收流端口
+	if in.Channel.EnabledAudio && len(profile.AudioFormats) > 0 {
+		medias = append(medias, newMedia("audio", profile.AudioFormats))
 	}
-	video.AddAttribute("rtpmap", "96", "PS/90000")
-	video.AddAttribute("rtpmap", "97", "MPEG4/90000")
-	video.AddAttribute("rtpmap", "98", "H264/90000")
 
 	//获取配置值
 	ipstr := in.SMS.GetSDPIP()
@@ -221,19 +318,22 @@ func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *
 			AddressType: "IP4",
 			IP:          net.ParseIP(ip4str),
 		},
-		Timing: []sdp.Timing{
-			{
-				// 	Start: data.S,
-				// End:   data.E,
-			},
-		},
-		Medias: []sdp.Media{video},
-		SSRC:   g.getSSRC(0),
-		// URI:    fmt.Sprintf("%s:0", channel.ChannelID),
+		Timing: []sdp.Timing{{}},
+		Medias: medias,
+		SSRC:   stream.ssrc,
+	}
+	if name != "Play" {
+		// 历史回放/下载需携带 u= 与 t= 时间段，SSRC 首位为 1
+		msg.URI = fmt.Sprintf("%s:0", ch.ChannelID)
+		msg.Timing = []sdp.Timing{{Start: time.Unix(sess.start, 0), End: time.Unix(sess.end, 0)}}
 	}
 
 	// appending message to session
 	body := msg.Append(nil).AppendTo(nil)
+	// f= 位于 y= 之后，gosdp 不支持，直接追加
+	if !in.Video.IsZero() {
+		body = append(body, in.Video.sdpLine()...)
+	}
 
 	slog.Info(">>>", "body", string(body))
 	// appending session to byte buffer
@@ -241,7 +341,7 @@ func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *
 	// channel.addr = &sip.Address{URI: uri}
 	// _serverDevices.addr.Params.Add("tag", sip.String{Str: sip.RandString(20)})
 	tx, err := g.svr.wrapRequest(ch, sip.MethodInvite, &sip.ContentTypeSDP, body, func(r *sip.Request) {
-		r.AppendHeader(&sip.GenericHeader{HeaderName: "Subject", Contents: fmt.Sprintf("%s:%s,%s:%s", ch.ChannelID, in.Channel.ID, in.Channel.DeviceID, in.Channel.ID)})
+		r.AppendHeader(&sip.GenericHeader{HeaderName: "Subject", Contents: profile.subject(ch.ChannelID, in.StreamProfile.StreamID(in.Channel.ID), in.Channel.DeviceID, msg.SSRC, g.cfg.ID)})
 	})
 	if err != nil {
 		return err
@@ -260,8 +360,35 @@ func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *
 	}
 
 	stream.Resp = resp
-
+	if callID, ok := resp.CallID(); ok {
+		stream.CallID = string(*callID)
+	}
 	ackReq := sip.NewRequestFromResponse(sip.MethodACK, resp)
+	answer, err := decodeSDP(resp.Body())
+	if err == nil {
+		stream.Answer = answer
+		if err := checkAnswerSSRC(stream); err != nil {
+			_ = tx.Request(ackReq)
+			g.sendBye(ch, resp)
+			return err
+		}
+		if name == "Play" {
+			if err := g.core.EditChannelMedia(in.Channel.DeviceID, in.Channel.ChannelID, sdpCodecs(answer)); err != nil {
+				slog.Error("EditChannelMedia", "err", err)
+			}
+		}
+	} else {
+		slog.Warn("解析应答 SDP 失败", "err", err)
+	}
+
+	// TCP 主动模式由流媒体连接设备应答的端口，须在 ACK 前建立连接，设备收到 ACK 后即开始发流
+	if in.StreamMode == 2 {
+		if err := g.connectRTPServer(in.SMS, stream, answer); err != nil {
+			_ = tx.Request(ackReq)
+			g.sendBye(ch, resp)
+			return err
+		}
+	}
 	return tx.Request(ackReq)
 
 	// data.Resp = response
@@ -290,6 +417,68 @@ func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *
 	// return nil
 }
 
+// closeRTPServer 点播失败后关闭已开启的收流端口，不等待流媒体超时回收
+func (g *GB28181API) closeRTPServer(stream *Streams) {
+	if stream.svr == nil {
+		return
+	}
+	if _, err := g.sms.CloseRTPServer(stream.svr, zlm.CloseRTPServerRequest{StreamID: stream.StreamID}); err != nil {
+		slog.Warn("CloseRTPServer", "err", err, "stream", stream.StreamID)
+	}
+}
+
+// connectRTPServer TCP 主动模式下让流媒体连接设备
+func (g *GB28181API) connectRTPServer(svr *sms.MediaServer, stream *Streams, msg *sdp.Message) error {
+	if msg == nil {
+		return ErrSDPAnswer
+	}
+	answer, err := parseAnswer(msg)
+	if err != nil {
+		return err
+	}
+	log := slog.With("stream", stream.StreamID, "ip", answer.IP, "port", answer.Port)
+	if answer.Setup != "" && answer.Setup != "passive" {
+		log.Warn("TCP 主动模式下设备应答的 setup 不是 passive", "setup", answer.Setup)
+	}
+	if err := g.sms.ConnectRTPServer(svr, zlm.ConnectRTPServerRequest{
+		DstURL:   answer.IP,
+		DstPort:  answer.Port,
+		StreamID: stream.StreamID,
+	}); err != nil {
+		log.Error("ConnectRTPServer", "err", err)
+		return err
+	}
+	log.Info("流媒体已连接设备")
+	return nil
+}
+
+// sendBye 结束已建立的会话，用于 ACK 后发现会话不可用时
+func (g *GB28181API) sendBye(ch *Channel, resp *sip.Response) {
+	req := sip.NewRequestFromResponse(sip.MethodBYE, resp)
+	req.SetDestination(ch.Source())
+	req.SetConnection(ch.Conn())
+	if _, err := g.svr.Request(req); err != nil {
+		slog.Error("BYE", "err", err)
+	}
+}
+
+// checkAnswerSSRC 校验设备应答的 y= 字段
+// 流媒体只接收分配的 SSRC，设备声明以其他 SSRC 推流时流会被丢弃，直接结束会话
+// 不合法的 y= 无法作为推流的 SSRC，仅记录
+func checkAnswerSSRC(stream *Streams) error {
+	ssrc := stream.Answer.SSRC
+	if ssrc == "" || ssrc == stream.ssrc {
+		return nil
+	}
+	log := slog.With("stream", stream.StreamID, "expect", stream.ssrc, "answer", ssrc)
+	if !validSSRC(ssrc) {
+		log.Warn("设备应答的 SSRC 不合法，忽略")
+		return nil
+	}
+	log.Warn("设备应答的 SSRC 与请求不一致，结束会话")
+	return ErrSSRCMismatch
+}
+
 // sip 请求播放
 // func SipPlay(data *Streams) (*Streams, error) {
 // 	channel := Channels{ChannelID: data.ChannelID}
@@ -348,8 +537,6 @@ func (g *GB28181API) sipPlayPush2(ch *Channel, in *PlayInput, port int, stream *
 // 	return data, nil
 // }
 
-var ssrcLock *sync.Mutex
-
 // func sipPlayPush(data *Streams, channel Channels, device Devices) (*Streams, error) {
 // 	var (
 // 		s sdp.Session
@@ -0,0 +1,150 @@
+package gbs
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+)
+
+// streamModes 收流超时后依次尝试的传输模式，0:UDP 1:TCP_PASSIVE 2:TCP_ACTIVE
+var streamModes = [...]int8{0, 1, 2}
+
+// PlayAttempt 一次点播尝试
+type PlayAttempt struct {
+	StreamMode int8      `json:"stream_mode"`     // 传输模式
+	Error      string    `json:"error,omitempty"` // 失败原因，为空表示进行中或已收到流
+	StartedAt  time.Time `json:"started_at"`      // 发起时间
+}
+
+// playTrace 实时点播的传输模式切换记录
+// 设备在 NAT 后无法发送 UDP 等情况下，收流超时后切换到下一种传输模式，直到收到流或全部尝试失败
+type playTrace struct {
+	mu       sync.Mutex
+	in       PlayInput
+	attempts []PlayAttempt
+}
+
+func newPlayTrace(in *PlayInput) *playTrace {
+	t := playTrace{in: *in}
+	t.begin(in.StreamMode)
+	return &t
+}
+
+// begin 记录一次新的尝试
+func (t *playTrace) begin(mode int8) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.in.StreamMode = mode
+	t.attempts = append(t.attempts, PlayAttempt{StreamMode: mode, StartedAt: time.Now()})
+}
+
+// fail 记录当前尝试失败的原因
+func (t *playTrace) fail(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(t.attempts); n > 0 {
+		t.attempts[n-1].Error = reason
+	}
+}
+
+// next 当前模式之后尚未尝试的传输模式
+func (t *playTrace) next() (int8, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cur := slices.Index(streamModes[:], t.in.StreamMode)
+	for i := 1; i <= len(streamModes); i++ {
+		mode := streamModes[(cur+i)%len(streamModes)]
+		tried := slices.ContainsFunc(t.attempts, func(a PlayAttempt) bool { return a.StreamMode == mode })
+		if !tried {
+			return mode, true
+		}
+	}
+	return 0, false
+}
+
+// input 当前尝试的点播参数
+func (t *playTrace) input() PlayInput {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.in
+}
+
+// Attempts 已发起的尝试
+func (t *playTrace) Attempts() []PlayAttempt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.attempts)
+}
+
+// PlayAttempts 实时流的点播尝试记录，未点播时返回 nil
+func (g *GB28181API) PlayAttempts(streamID string) []PlayAttempt {
+	trace, ok := g.plays.Load(streamID)
+	if !ok {
+		return nil
+	}
+	return trace.Attempts()
+}
+
+// PlayTimeout 实时点播收流超时，BYE 结束当前会话后按 UDP → TCP 被动 → TCP 主动 切换传输模式重新点播
+// 返回 false 表示该流不是由此发起的实时点播
+func (g *GB28181API) PlayTimeout(streamID string) (bool, error) {
+	trace, ok := g.plays.Load(streamID)
+	if !ok {
+		return false, nil
+	}
+	in := trace.input()
+	log := slog.With("stream", streamID, "stream_mode", in.StreamMode)
+	trace.fail("收流超时")
+
+	if err := g.closePlay(&StopPlayInput{Channel: in.Channel, StreamProfile: in.StreamProfile}); err != nil {
+		log.Warn("BYE", "err", err)
+	}
+
+	for {
+		mode, ok := trace.next()
+		if !ok {
+			// 保留记录供查询点播状态，停止播放时清理
+			log.Warn("所有传输模式均未收到流", "attempts", trace.Attempts())
+			return true, ErrPlayTransport
+		}
+		trace.begin(mode)
+		in.StreamMode = mode
+		log.Info("收流超时，切换传输模式重新点播", "next", mode)
+		if err := g.play(&in, nil); err != nil {
+			log.Warn("重新点播失败", "next", mode, "err", err)
+			trace.fail(err.Error())
+			continue
+		}
+		return true, nil
+	}
+}
+
+// PlayStarted 实时流已注册，标记会话已收到流，切换过传输模式时记住成功的模式，下次点播直接使用
+func (g *GB28181API) PlayStarted(streamID string) {
+	trace, ok := g.plays.Load(streamID)
+	if !ok {
+		return
+	}
+	in := trace.input()
+	if ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID); ok {
+		ch.device.playMutex.Lock()
+		if stream, ok := g.streams.Load(playKey(in.Channel, in.StreamProfile)); ok {
+			stream.Stream = true
+		}
+		ch.device.playMutex.Unlock()
+	}
+
+	attempts := trace.Attempts()
+	if len(attempts) < 2 {
+		return
+	}
+	slog.Info("点播成功，记住传输模式", "stream", streamID, "stream_mode", in.StreamMode, "attempts", attempts)
+	if err := g.core.Edit(in.Channel.DeviceID, func(d *gb28181.Device) {
+		d.StreamMode = in.StreamMode
+	}); err != nil {
+		slog.Error("Edit StreamMode", "err", err)
+	}
+}
@@ -0,0 +1,36 @@
+package gbs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlayTraceNext(t *testing.T) {
+	tests := []struct {
+		start  int8
+		expect []int8
+	}{
+		{start: 0, expect: []int8{1, 2}},
+		{start: 1, expect: []int8{2, 0}},
+		{start: 2, expect: []int8{0, 1}},
+	}
+	for _, tt := range tests {
+		trace := newPlayTrace(&PlayInput{StreamMode: tt.start})
+		var modes []int8
+		for {
+			mode, ok := trace.next()
+			if !ok {
+				break
+			}
+			trace.fail("timeout")
+			trace.begin(mode)
+			modes = append(modes, mode)
+		}
+		if !slices.Equal(modes, tt.expect) {
+			t.Fatalf("start %d expect %v, got %v", tt.start, tt.expect, modes)
+		}
+		if n := len(trace.Attempts()); n != 3 {
+			t.Fatalf("expect 3 attempts, got %d", n)
+		}
+	}
+}
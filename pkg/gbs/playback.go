@@ -0,0 +1,197 @@
+package gbs
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/internal/core/sms"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/gowvp/gb28181/pkg/zlm"
+)
+
+// 回放控制动作
+const (
+	PlaybackActionPause  = "pause"
+	PlaybackActionResume = "resume"
+	PlaybackActionSpeed  = "speed"
+	PlaybackActionSeek   = "seek"
+)
+
+type PlaybackInput struct {
+	Channel    *gb28181.Channel
+	SMS        *sms.MediaServer
+	StreamMode int8
+	StreamID   string // 回放流 ID，同一通道可同时存在多路回放
+	Start, End int64  // 回放时间段，unix 秒
+}
+
+type PlaybackControlInput struct {
+	StreamID string
+	Action   string  // pause/resume/speed/seek
+	Scale    float64 // 倍速，speed 有效，如 0.5/1/2/4
+	Range    int64   // 相对回放开始时间的偏移秒数，seek 有效
+}
+
+func playbackKey(streamID string) string {
+	return "playback:" + streamID
+}
+
+// HasPlayback 是否为进行中的回放流
+func (g *GB28181API) HasPlayback(streamID string) bool {
+	_, ok := g.streams.Load(playbackKey(streamID))
+	return ok
+}
+
+// IsChannelPlayback 回放流是否属于该通道
+func (g *GB28181API) IsChannelPlayback(streamID, deviceID, channelID string) bool {
+	stream, ok := g.streams.Load(playbackKey(streamID))
+	return ok && stream.DeviceID == deviceID && stream.ChannelID == channelID
+}
+
+// Playback 历史回放
+// GB/T28181 9.8
+func (g *GB28181API) Playback(in *PlaybackInput) error {
+	log := slog.With("deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "stream", in.StreamID)
+	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+
+	ch.device.playMutex.Lock()
+	defer ch.device.playMutex.Unlock()
+
+	if !ch.device.IsOnline {
+		return ErrDeviceOffline
+	}
+
+	key := playbackKey(in.StreamID)
+	stream, ok := g.streams.LoadOrStore(key, &Streams{
+		T:         1,
+		DeviceID:  in.Channel.DeviceID,
+		ChannelID: in.Channel.ChannelID,
+		StreamID:  in.StreamID,
+		svr:       in.SMS,
+	})
+	if ok {
+		log.Debug("回放流已存在")
+		return nil
+	}
+
+	ssrc, err := g.ssrcs.Alloc(true)
+	if err != nil {
+		g.streams.Delete(key)
+		return err
+	}
+	stream.ssrc = ssrc
+
+	resp, err := g.sms.OpenRTPServer(in.SMS, zlm.OpenRTPServerRequest{
+		TCPMode:  in.StreamMode,
+		StreamID: in.StreamID,
+		SSRC:     ssrc,
+	})
+	if err != nil {
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
+		return err
+	}
+
+	playIn := PlayInput{Channel: in.Channel, SMS: in.SMS, StreamMode: in.StreamMode}
+	if err := g.sipPlayPush2(ch, &playIn, resp.Port, stream, inviteSession{
+		name:  "Playback",
+		start: in.Start,
+		end:   in.End,
+	}); err != nil {
+		log.Debug("回放 INVITE 失败", "err", err)
+		g.closeRTPServer(stream)
+		g.streams.Delete(key)
+		g.ssrcs.Release(ssrc)
+		return err
+	}
+	return nil
+}
+
+// StopPlayback 停止回放
+func (g *GB28181API) StopPlayback(streamID string) error {
+	stream, ok := g.streams.LoadAndDelete(playbackKey(streamID))
+	if !ok {
+		return nil
+	}
+	// 先关闭收流端口再释放 SSRC，避免 SSRC 分配给新会话时端口仍在接收
+	g.closeRTPServer(stream)
+	g.ssrcs.Release(stream.ssrc)
+	if stream.Resp == nil {
+		return nil
+	}
+	ch, ok := g.svr.memoryStorer.GetChannel(stream.DeviceID, stream.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+
+	req := sip.NewRequestFromResponse(sip.MethodBYE, stream.Resp)
+	req.SetDestination(ch.Source())
+	req.SetConnection(ch.Conn())
+	_, err := g.svr.Request(req)
+	return err
+}
+
+// newMANSRTSP 生成回放控制消息体
+// GB/T28181 附录 B
+func newMANSRTSP(in *PlaybackControlInput, cseq uint32) ([]byte, error) {
+	var body string
+	switch in.Action {
+	case PlaybackActionPause:
+		body = fmt.Sprintf("PAUSE RTSP/1.0\r\nCSeq: %d\r\nPauseTime: now\r\n", cseq)
+	case PlaybackActionResume:
+		body = fmt.Sprintf("PLAY RTSP/1.0\r\nCSeq: %d\r\nRange: npt=now-\r\n", cseq)
+	case PlaybackActionSpeed:
+		if in.Scale <= 0 {
+			return nil, ErrPlaybackAction
+		}
+		body = fmt.Sprintf("PLAY RTSP/1.0\r\nCSeq: %d\r\nScale: %s\r\n", cseq, strconv.FormatFloat(in.Scale, 'f', -1, 64))
+	case PlaybackActionSeek:
+		if in.Range < 0 {
+			return nil, ErrPlaybackAction
+		}
+		body = fmt.Sprintf("PLAY RTSP/1.0\r\nCSeq: %d\r\nRange: npt=%d-\r\n", cseq, in.Range)
+	default:
+		return nil, ErrPlaybackAction
+	}
+	return []byte(body), nil
+}
+
+// PlaybackControl 回放控制，通过会话内 INFO 携带 MANSRTSP 消息
+func (g *GB28181API) PlaybackControl(in *PlaybackControlInput) error {
+	stream, ok := g.streams.Load(playbackKey(in.StreamID))
+	if !ok || stream.Resp == nil {
+		return ErrStreamNotExist
+	}
+	ch, ok := g.svr.memoryStorer.GetChannel(stream.DeviceID, stream.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+
+	// NewRequestFromResponse 会递增会话的 CSeq，需串行构建请求
+	ch.device.playMutex.Lock()
+	stream.CseqNo++
+	body, err := newMANSRTSP(in, stream.CseqNo)
+	if err != nil {
+		ch.device.playMutex.Unlock()
+		return err
+	}
+	req := sip.NewRequestFromResponse(sip.MethodInfo, stream.Resp)
+	ch.device.playMutex.Unlock()
+
+	req.SetDestination(ch.Source())
+	req.SetConnection(ch.Conn())
+	req.AppendHeader(&sip.ContentTypeMANSRTSP)
+	req.SetBody(body, true)
+
+	tx, err := g.svr.Request(req)
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
@@ -0,0 +1,27 @@
+package gbs
+
+import "testing"
+
+func TestNewMANSRTSP(t *testing.T) {
+	cases := []struct {
+		in     PlaybackControlInput
+		expect string
+	}{
+		{PlaybackControlInput{Action: PlaybackActionPause}, "PAUSE RTSP/1.0\r\nCSeq: 1\r\nPauseTime: now\r\n"},
+		{PlaybackControlInput{Action: PlaybackActionResume}, "PLAY RTSP/1.0\r\nCSeq: 1\r\nRange: npt=now-\r\n"},
+		{PlaybackControlInput{Action: PlaybackActionSpeed, Scale: 0.5}, "PLAY RTSP/1.0\r\nCSeq: 1\r\nScale: 0.5\r\n"},
+		{PlaybackControlInput{Action: PlaybackActionSeek, Range: 120}, "PLAY RTSP/1.0\r\nCSeq: 1\r\nRange: npt=120-\r\n"},
+	}
+	for _, c := range cases {
+		b, err := newMANSRTSP(&c.in, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != c.expect {
+			t.Fatalf("action[%s] expect[%q] got[%q]", c.in.Action, c.expect, b)
+		}
+	}
+	if _, err := newMANSRTSP(&PlaybackControlInput{Action: PlaybackActionSpeed}, 1); err != ErrPlaybackAction {
+		t.Fatalf("expect ErrPlaybackAction got %v", err)
+	}
+}
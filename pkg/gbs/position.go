@@ -0,0 +1,111 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"log/slog"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// SubscribeEventMobilePosition 移动设备位置订阅事件，GB/T28181 9.11.3 规定 Event 为 presence
+const SubscribeEventMobilePosition = "presence"
+
+// MobilePositionQuery 移动设备位置查询/订阅
+type MobilePositionQuery struct {
+	XMLName  xml.Name `xml:"Query"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	DeviceID string   `xml:"DeviceID"`
+	Interval int      `xml:"Interval,omitempty"` // 上报间隔(秒)
+}
+
+// MobilePositionNotify 移动设备位置通知 GB/T28181 A.2.5.6
+type MobilePositionNotify struct {
+	XMLName   xml.Name `xml:"Notify"`
+	CmdType   string   `xml:"CmdType"`
+	SN        int      `xml:"SN"`
+	DeviceID  string   `xml:"DeviceID"`
+	Time      string   `xml:"Time"`      // 定位时间
+	Longitude float64  `xml:"Longitude"` // 经度
+	Latitude  float64  `xml:"Latitude"`  // 纬度
+	Speed     float64  `xml:"Speed"`     // 速度 km/h
+	Direction float64  `xml:"Direction"` // 方向
+	Altitude  float64  `xml:"Altitude"`  // 海拔 m
+}
+
+func (m *MobilePositionNotify) toPosition(deviceID string) *gb28181.Position {
+	out := gb28181.Position{
+		DeviceID:  deviceID,
+		ChannelID: m.DeviceID,
+		Longitude: m.Longitude,
+		Latitude:  m.Latitude,
+		Speed:     m.Speed,
+		Direction: m.Direction,
+		Altitude:  m.Altitude,
+		ReportAt:  orm.Now(),
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", m.Time, time.Local); err == nil {
+		out.ReportAt = orm.Time{Time: t}
+	}
+	return &out
+}
+
+func mobilePositionXML(deviceID string, interval int) []byte {
+	body, _ := sip.XMLEncode(MobilePositionQuery{
+		CmdType:  "MobilePosition",
+		SN:       sip.RandInt(100000, 999999),
+		DeviceID: deviceID,
+		Interval: interval,
+	})
+	return body
+}
+
+// SubscribeMobilePosition 订阅移动设备位置，interval 为上报间隔(秒)
+func (g *GB28181API) SubscribeMobilePosition(deviceID string, interval, expires int) error {
+	return g.Subscribe(deviceID, SubscribeEventMobilePosition, expires, func() []byte {
+		return mobilePositionXML(deviceID, interval)
+	})
+}
+
+// QueryMobilePosition 查询移动设备位置，结果通过 MobilePosition 通知上报
+func (g *GB28181API) QueryMobilePosition(deviceID string) error {
+	ipc, ok := g.svr.memoryStorer.Load(deviceID)
+	if !ok {
+		return ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return ErrDeviceOffline
+	}
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, mobilePositionXML(deviceID, 0))
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
+
+// sipMessageMobilePosition 移动设备位置通知，订阅后通过 NOTIFY 上报，查询时通过 MESSAGE 上报
+// GB/T28181 9.11.3
+func (g *GB28181API) sipMessageMobilePosition(ctx *sip.Context) {
+	var msg MobilePositionNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageMobilePosition", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	// 未定位的设备可能上报 0,0
+	if msg.Longitude == 0 && msg.Latitude == 0 {
+		return
+	}
+	pos := msg.toPosition(ctx.DeviceID)
+	slog.Debug("收到位置", "deviceID", pos.DeviceID, "channelID", pos.ChannelID, "lng", pos.Longitude, "lat", pos.Latitude)
+	if err := g.core.SavePosition(pos); err != nil {
+		ctx.Log.Error("SavePosition", "err", err)
+	}
+}
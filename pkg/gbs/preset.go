@@ -0,0 +1,170 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"log/slog"
+	"strings"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+// 预置位指令 GB/T28181 A.3.4
+// 字节5 为 00H，字节6 为预置位号 01H~FFH
+const (
+	ptzPresetSet  = 0x81
+	ptzPresetCall = 0x82
+	ptzPresetDel  = 0x83
+)
+
+const CMDTypePresetQuery = "PresetQuery"
+
+// PresetQueryRequest 预置位查询 GB/T28181-2022 A.2.4.11
+type PresetQueryRequest struct {
+	XMLName  xml.Name `xml:"Query"`
+	CmdType  string   `xml:"CmdType"`
+	SN       int      `xml:"SN"`
+	DeviceID string   `xml:"DeviceID"`
+}
+
+// PresetQueryResponse 预置位查询应答
+type PresetQueryResponse struct {
+	XMLName    xml.Name    `xml:"Response"`
+	CmdType    string      `xml:"CmdType"`
+	SN         int         `xml:"SN"`
+	DeviceID   string      `xml:"DeviceID"`
+	PresetList *PresetList `xml:"PresetList"`
+}
+
+type PresetList struct {
+	Num  int          `xml:"Num,attr"`
+	Item []PresetItem `xml:"Item"`
+}
+
+type PresetItem struct {
+	PresetID   int    `xml:"PresetID"`
+	PresetName string `xml:"PresetName"`
+}
+
+func NewPresetQueryRequest(sn int, channelID string) []byte {
+	b, _ := sip.XMLEncode(PresetQueryRequest{
+		CmdType:  CMDTypePresetQuery,
+		SN:       sn,
+		DeviceID: channelID,
+	})
+	return b
+}
+
+type PresetInput struct {
+	Channel  *gb28181.Channel
+	PresetID int // 预置位编号 1~255
+}
+
+func newPresetCmd(cmd byte, presetID int) (string, error) {
+	if presetID < 1 || presetID > 255 {
+		return "", ErrPresetID
+	}
+	return encodePTZCmd(cmd, 0, byte(presetID), 0), nil // nolint
+}
+
+// SetPreset 设置预置位
+func (g *GB28181API) SetPreset(in *PresetInput) error {
+	cmd, err := newPresetCmd(ptzPresetSet, in.PresetID)
+	if err != nil {
+		return err
+	}
+	return g.sendPTZCmd(in.Channel, cmd)
+}
+
+// CallPreset 调用预置位
+func (g *GB28181API) CallPreset(in *PresetInput) error {
+	cmd, err := newPresetCmd(ptzPresetCall, in.PresetID)
+	if err != nil {
+		return err
+	}
+	return g.sendPTZCmd(in.Channel, cmd)
+}
+
+// DelPreset 删除预置位
+func (g *GB28181API) DelPreset(in *PresetInput) error {
+	cmd, err := newPresetCmd(ptzPresetDel, in.PresetID)
+	if err != nil {
+		return err
+	}
+	return g.sendPTZCmd(in.Channel, cmd)
+}
+
+// QueryPreset 查询设备预置位，应答通过 sipMessagePresetQuery 收集后入库
+func (g *GB28181API) QueryPreset(channel *gb28181.Channel) error {
+	slog.Debug("QueryPreset", "deviceID", channel.DeviceID, "channelID", channel.ChannelID)
+	ch, ok := g.svr.memoryStorer.GetChannel(channel.DeviceID, channel.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+	if !ch.device.IsOnline {
+		return ErrDeviceOffline
+	}
+
+	// 先开始收集，设备可能在 MESSAGE 的 200 OK 之前就发送应答
+	key := presetKey(channel.DeviceID, channel.ChannelID)
+	g.presets.Run(key)
+
+	body := NewPresetQueryRequest(sip.RandInt(100000, 999999), channel.ChannelID)
+	tx, err := g.svr.wrapRequest(ch, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err != nil {
+		return err
+	}
+	if _, err := sipResponse(tx); err != nil {
+		return err
+	}
+	g.presets.Wait(key)
+	return nil
+}
+
+func presetKey(deviceID, channelID string) string {
+	return deviceID + ":" + channelID
+}
+
+// savePresets 收集完成后保存预置位
+func (g *GB28181API) savePresets(key string, items []*PresetItem, complete bool) {
+	deviceID, channelID, _ := strings.Cut(key, ":")
+	presets := make([]*gb28181.Preset, 0, len(items))
+	for _, item := range items {
+		presets = append(presets, &gb28181.Preset{
+			PresetID: item.PresetID,
+			Name:     item.PresetName,
+		})
+	}
+	if err := g.core.SavePresets(deviceID, channelID, presets, complete); err != nil {
+		slog.Error("save presets", "err", err, "deviceID", deviceID, "channelID", channelID)
+	}
+}
+
+// sipMessagePresetQuery 预置位查询应答
+func (g *GB28181API) sipMessagePresetQuery(ctx *sip.Context) {
+	var msg PresetQueryResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessagePresetQuery", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	if msg.PresetList == nil {
+		ctx.String(200, "OK")
+		return
+	}
+
+	key := presetKey(ctx.DeviceID, msg.DeviceID)
+	// 设备已无预置位，立即结束收集
+	if msg.PresetList.Num == 0 && len(msg.PresetList.Item) == 0 {
+		g.presets.Write(&sip.CollectorMsg[PresetItem]{Key: key})
+	}
+	for _, item := range msg.PresetList.Item {
+		g.presets.Write(&sip.CollectorMsg[PresetItem]{
+			Key:   key,
+			Data:  &item,
+			Total: msg.PresetList.Num,
+		})
+	}
+	ctx.String(200, "OK")
+}
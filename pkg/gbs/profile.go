@@ -0,0 +1,199 @@
+package gbs
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+// 设备适配名称
+const (
+	ProfileDefault   = "default"
+	ProfileHikvision = "hikvision"
+	ProfileDahua     = "dahua"
+	ProfileUniview   = "uniview"
+	ProfilePlatform  = "platform"
+)
+
+// XML 字符集
+const (
+	CharsetGB2312 = "GB2312"
+	CharsetUTF8   = "UTF-8"
+)
+
+// SubjectStyle INVITE Subject 头格式
+type SubjectStyle int
+
+const (
+	// SubjectLegacy 通道编码:流 ID,设备编码:流 ID，兼容早期版本的写法
+	SubjectLegacy SubjectStyle = iota
+	// SubjectStandard 通道编码:SSRC,平台编码:0，GB/T28181 附录 I
+	SubjectStandard
+)
+
+// SDPFormat INVITE 中声明的 rtpmap
+type SDPFormat struct {
+	PT    string `json:"pt"`    // 负载类型
+	Codec string `json:"codec"` // 编码名称/时钟频率
+}
+
+// Profile 设备厂商适配，按厂商/User-Agent 自动识别，也可在设备上指定
+type Profile struct {
+	Name     string   `json:"name"`  // 适配名称
+	Title    string   `json:"title"` // 显示名称
+	keywords []string // 厂商/User-Agent 关键字，小写
+
+	Formats      []SDPFormat  `json:"formats"`       // 点播 SDP 声明的视频格式，顺序即优先级
+	AudioFormats []SDPFormat  `json:"audio_formats"` // 通道开启音频时声明的音频格式
+	Subject      SubjectStyle `json:"subject"`       // Subject 头格式
+	Charset      string       `json:"charset"`       // 下发 XML 的字符集
+	StreamMode   int8         `json:"stream_mode"`   // 首次识别时设备默认的数据传输模式 0:UDP 1:TCP_PASSIVE 2:TCP_ACTIVE
+	// CatalogTimeout 等待目录多包应答的时间，大容量 NVR/平台通道多、应答包多
+	CatalogTimeout time.Duration `json:"catalog_timeout"`
+	// StreamAttrs 请求子码流时携带的 SDP 属性，如海康 a=streamprofile、2022 版 a=streamnumber
+	StreamAttrs []string `json:"stream_attrs"`
+}
+
+// GB/T28181 附录 F 的负载类型，H265 为 2022 版新增
+// 静态负载类型 9 为 G.722，G.722.1 使用动态负载类型
+var (
+	defaultFormats = []SDPFormat{{"96", "PS/90000"}, {"97", "MPEG4/90000"}, {"98", "H264/90000"}, {"99", "SVAC/90000"}, {"100", "H265/90000"}}
+	defaultAudio   = []SDPFormat{{"8", "PCMA/8000"}, {"0", "PCMU/8000"}, {"102", "MPEG4-GENERIC/8000"}, {"104", "G7221/16000"}}
+)
+
+// profiles 内置适配，首个为默认适配
+var profiles = []*Profile{
+	{
+		Name:           ProfileDefault,
+		Title:          "通用",
+		Formats:        defaultFormats,
+		AudioFormats:   defaultAudio,
+		Subject:        SubjectLegacy,
+		Charset:        CharsetGB2312,
+		StreamMode:     1,
+		CatalogTimeout: 7 * time.Second,
+		StreamAttrs:    []string{"streamprofile", "streamnumber"},
+	},
+	{
+		Name:           ProfileHikvision,
+		Title:          "海康威视",
+		keywords:       []string{"hikvision", "hikrobot", "海康"},
+		Formats:        []SDPFormat{{"96", "PS/90000"}, {"98", "H264/90000"}, {"100", "H265/90000"}},
+		AudioFormats:   defaultAudio,
+		Subject:        SubjectStandard,
+		Charset:        CharsetGB2312,
+		StreamMode:     1,
+		CatalogTimeout: 15 * time.Second,
+		StreamAttrs:    []string{"streamprofile"},
+	},
+	{
+		Name:           ProfileDahua,
+		Title:          "大华",
+		keywords:       []string{"dahua", "大华"},
+		Formats:        defaultFormats,
+		AudioFormats:   defaultAudio,
+		Subject:        SubjectStandard,
+		Charset:        CharsetGB2312,
+		StreamMode:     1,
+		CatalogTimeout: 30 * time.Second,
+		StreamAttrs:    []string{"streamprofile"},
+	},
+	{
+		Name:           ProfileUniview,
+		Title:          "宇视",
+		keywords:       []string{"uniview", "宇视"},
+		Formats:        []SDPFormat{{"96", "PS/90000"}, {"98", "H264/90000"}, {"100", "H265/90000"}},
+		AudioFormats:   defaultAudio,
+		Subject:        SubjectStandard,
+		Charset:        CharsetUTF8,
+		StreamMode:     0,
+		CatalogTimeout: 15 * time.Second,
+		StreamAttrs:    []string{"streamnumber"},
+	},
+	{
+		Name:           ProfilePlatform,
+		Title:          "下级平台",
+		keywords:       []string{"wvp", "platform", "平台"},
+		Formats:        defaultFormats,
+		AudioFormats:   defaultAudio,
+		Subject:        SubjectStandard,
+		Charset:        CharsetGB2312,
+		StreamMode:     1,
+		CatalogTimeout: 60 * time.Second,
+		StreamAttrs:    []string{"streamnumber"},
+	},
+}
+
+// Profiles 内置的全部适配
+func Profiles() []*Profile {
+	return slices.Clone(profiles)
+}
+
+// GetProfile 按名称查找适配
+func GetProfile(name string) (*Profile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// MatchProfile 优先使用指定的适配，否则按厂商与 User-Agent 识别，都不匹配时返回默认适配
+func MatchProfile(name, manufacturer, userAgent string) *Profile {
+	if p, ok := GetProfile(name); ok {
+		return p
+	}
+	s := strings.ToLower(manufacturer + " " + userAgent)
+	for _, p := range profiles {
+		for _, k := range p.keywords {
+			if strings.Contains(s, k) {
+				return p
+			}
+		}
+	}
+	return profiles[0]
+}
+
+// DeviceProfile 设备当前生效的适配
+func DeviceProfile(d *gb28181.Device) *Profile {
+	return MatchProfile(d.Profile, d.Ext.Manufacturer, d.Ext.UserAgent)
+}
+
+// setup TCP 模式下 SDP a=setup 的取值，streamMode 1:被动 2:主动
+func (p *Profile) setup(streamMode int8) string {
+	if streamMode == 1 {
+		return "passive"
+	}
+	return "active"
+}
+
+// subject INVITE Subject 头
+func (p *Profile) subject(channelID, streamID, deviceID, ssrc, serverID string) string {
+	if p.Subject == SubjectStandard {
+		return fmt.Sprintf("%s:%s,%s:0", channelID, ssrc, serverID)
+	}
+	return fmt.Sprintf("%s:%s,%s:%s", channelID, streamID, deviceID, streamID)
+}
+
+// encodeXML 按设备字符集转换下发的 XML，默认 GB2312 无需转换
+func (p *Profile) encodeXML(body []byte) []byte {
+	if p.Charset != CharsetUTF8 {
+		return body
+	}
+	out, err := sip.GbkToUtf8(body)
+	if err != nil {
+		return body
+	}
+	return bytes.Replace(out, []byte(`encoding="`+CharsetGB2312+`"`), []byte(`encoding="`+CharsetUTF8+`"`), 1)
+}
+
+// profiler 可提供设备适配的请求目标
+type profiler interface {
+	Profile() *Profile
+}
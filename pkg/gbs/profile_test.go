@@ -0,0 +1,57 @@
+package gbs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+func TestMatchProfile(t *testing.T) {
+	cases := []struct {
+		name, manufacturer, userAgent string
+		expect                        string
+	}{
+		{"", "Hikvision", "", ProfileHikvision},
+		{"", "", "Dahua SIP UAS V2.0", ProfileDahua},
+		{"", "UNIVIEW", "", ProfileUniview},
+		{"", "unknown", "IP Camera", ProfileDefault},
+		{"", "Sunvision", "", ProfileDefault},         // 不因包含 unv 误识别为宇视
+		{ProfileDahua, "Hikvision", "", ProfileDahua}, // 指定的适配优先
+		{"not-exist", "Hikvision", "", ProfileHikvision},
+	}
+	for _, c := range cases {
+		if p := MatchProfile(c.name, c.manufacturer, c.userAgent); p.Name != c.expect {
+			t.Fatalf("MatchProfile(%q, %q, %q) expect %s, got %s", c.name, c.manufacturer, c.userAgent, c.expect, p.Name)
+		}
+	}
+}
+
+func TestProfileEncodeXML(t *testing.T) {
+	body, err := sip.XMLEncode(struct {
+		XMLName  struct{} `xml:"Query"`
+		DeviceID string   `xml:"DeviceID"`
+		Name     string   `xml:"Name"`
+	}{DeviceID: "34020000001320000001", Name: "通道"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := GetProfile(ProfileDefault)
+	if out := p.encodeXML(body); !bytes.Equal(out, body) {
+		t.Fatal("GB2312 profile should keep body")
+	}
+
+	p, _ = GetProfile(ProfileUniview)
+	out := p.encodeXML(body)
+	if !bytes.Contains(out, []byte(`encoding="UTF-8"`)) || !bytes.Contains(out, []byte("通道")) {
+		t.Fatalf("expect utf-8 body, got %s", out)
+	}
+}
+
+func TestProfileSetup(t *testing.T) {
+	p := Profile{}
+	if p.setup(1) != "passive" || p.setup(2) != "active" {
+		t.Fatal("unexpected setup")
+	}
+}
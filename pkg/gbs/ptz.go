@@ -0,0 +1,142 @@
+package gbs
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+// PTZ 指令码 GB/T28181 A.3
+// 字节4 bit7~bit6 为 00 时表示 PTZ 指令，为 01 时表示 FI 指令
+const (
+	ptzRight   = 0x01
+	ptzLeft    = 0x02
+	ptzDown    = 0x04
+	ptzUp      = 0x08
+	ptzZoomIn  = 0x10
+	ptzZoomOut = 0x20
+
+	fiFocusFar  = 0x41
+	fiFocusNear = 0x42
+	fiIrisOpen  = 0x44
+	fiIrisClose = 0x48
+	fiStop      = 0x40
+)
+
+// PTZ 控制动作
+const (
+	PTZActionUp        = "up"
+	PTZActionDown      = "down"
+	PTZActionLeft      = "left"
+	PTZActionRight     = "right"
+	PTZActionUpLeft    = "upleft"
+	PTZActionUpRight   = "upright"
+	PTZActionDownLeft  = "downleft"
+	PTZActionDownRight = "downright"
+	PTZActionZoomIn    = "zoomin"
+	PTZActionZoomOut   = "zoomout"
+	PTZActionFocusNear = "focusnear"
+	PTZActionFocusFar  = "focusfar"
+	PTZActionIrisOpen  = "irisopen"
+	PTZActionIrisClose = "irisclose"
+	PTZActionStop      = "stop"
+)
+
+var ptzActions = map[string]byte{
+	PTZActionUp:        ptzUp,
+	PTZActionDown:      ptzDown,
+	PTZActionLeft:      ptzLeft,
+	PTZActionRight:     ptzRight,
+	PTZActionUpLeft:    ptzUp | ptzLeft,
+	PTZActionUpRight:   ptzUp | ptzRight,
+	PTZActionDownLeft:  ptzDown | ptzLeft,
+	PTZActionDownRight: ptzDown | ptzRight,
+	PTZActionZoomIn:    ptzZoomIn,
+	PTZActionZoomOut:   ptzZoomOut,
+	PTZActionFocusNear: fiFocusNear,
+	PTZActionFocusFar:  fiFocusFar,
+	PTZActionIrisOpen:  fiIrisOpen,
+	PTZActionIrisClose: fiIrisClose,
+	PTZActionStop:      0x00,
+}
+
+// encodePTZCmd 生成 8 字节的 PTZCmd 指令串
+// 字节1 A5H；字节2 高4位版本号 0H，低4位为校验位 (字节1高4位+字节1低4位+字节2高4位)%16；
+// 字节3 地址低8位；字节4 指令码；字节5、6 数据1、数据2；
+// 字节7 高4位为数据3，低4位为地址高4位；字节8 为前7字节的算术和取低8位
+func encodePTZCmd(cmd, data1, data2, data3 byte) string {
+	b := [8]byte{0xA5, 0x0F, 0x01, cmd, data1, data2, (data3 & 0x0F) << 4}
+	var sum int
+	for _, v := range b[:7] {
+		sum += int(v)
+	}
+	b[7] = byte(sum % 256) // nolint
+	return fmt.Sprintf("%X", b[:])
+}
+
+// newPTZCmd 根据动作和速度生成 PTZCmd，速度取值 0~255
+func newPTZCmd(action string, speed uint8) (string, error) {
+	cmd, ok := ptzActions[action]
+	if !ok {
+		return "", ErrPTZAction
+	}
+
+	switch {
+	case cmd == 0x00:
+		return encodePTZCmd(cmd, 0, 0, 0), nil
+	case cmd&0xC0 == 0x40:
+		// FI 指令，数据1 为聚焦速度，数据2 为光圈速度
+		return encodePTZCmd(cmd, speed, speed, 0), nil
+	}
+
+	var pan, tilt, zoom byte
+	if cmd&(ptzLeft|ptzRight) != 0 {
+		pan = speed
+	}
+	if cmd&(ptzUp|ptzDown) != 0 {
+		tilt = speed
+	}
+	if cmd&(ptzZoomIn|ptzZoomOut) != 0 {
+		// 变倍速度只有 4 位
+		zoom = speed >> 4
+	}
+	return encodePTZCmd(cmd, pan, tilt, zoom), nil
+}
+
+type PTZControlInput struct {
+	Channel *gb28181.Channel
+	Action  string // 控制动作，持续运动直到收到 stop
+	Speed   uint8  // 速度 0~255
+}
+
+// PTZControl 云台控制
+// GB/T28181 A.2.3.1.2
+func (g *GB28181API) PTZControl(in *PTZControlInput) error {
+	cmd, err := newPTZCmd(in.Action, in.Speed)
+	if err != nil {
+		return err
+	}
+	slog.Debug("PTZControl", "deviceID", in.Channel.DeviceID, "channelID", in.Channel.ChannelID, "action", in.Action, "cmd", cmd)
+	return g.sendPTZCmd(in.Channel, cmd)
+}
+
+// sendPTZCmd 向通道发送 PTZCmd 设备控制命令
+func (g *GB28181API) sendPTZCmd(channel *gb28181.Channel, cmd string) error {
+	ch, ok := g.svr.memoryStorer.GetChannel(channel.DeviceID, channel.ChannelID)
+	if !ok {
+		return ErrChannelNotExist
+	}
+	if !ch.device.IsOnline {
+		return ErrDeviceOffline
+	}
+
+	body := NewDeviceControl(channel.ChannelID).SetPTZCmd(cmd).Marshal()
+	tx, err := g.svr.wrapRequest(ch, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
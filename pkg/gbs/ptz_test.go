@@ -0,0 +1,44 @@
+package gbs
+
+import "testing"
+
+func TestNewPTZCmd(t *testing.T) {
+	cases := []struct {
+		action string
+		speed  uint8
+		expect string
+	}{
+		{PTZActionStop, 100, "A50F0100000000B5"},
+		{PTZActionUp, 0x80, "A50F01080080003D"},
+		{PTZActionUpLeft, 0x10, "A50F010A101000DF"},
+		{PTZActionZoomIn, 0xFF, "A50F01100000F0B5"},
+		{PTZActionFocusNear, 0x20, "A50F014220200037"},
+	}
+	for _, c := range cases {
+		out, err := newPTZCmd(c.action, c.speed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != c.expect {
+			t.Fatalf("action[%s] expect[%s] got[%s]", c.action, c.expect, out)
+		}
+	}
+
+	if _, err := newPTZCmd("unknown", 1); err != ErrPTZAction {
+		t.Fatalf("expect ErrPTZAction got %v", err)
+	}
+}
+
+func TestEncodePTZCmd12(t *testing.T) {
+	out, err := encodePTZCmd12(ptzCruiseSpeed, 1, 0x123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0x123 低8位 0x23 在字节6，高4位 0x1 在字节7 高4位
+	if expect := "A50F01860123106F"; out != expect {
+		t.Fatalf("expect[%s] got[%s]", expect, out)
+	}
+	if _, err := encodePTZCmd12(ptzCruiseSpeed, 1, 0x1000); err != ErrPTZParam {
+		t.Fatalf("expect ErrPTZParam got %v", err)
+	}
+}
@@ -1,58 +1,15 @@
 package gbs
 
 import (
-	"errors"
+	"encoding/hex"
 	"fmt"
-	"net/http"
 	"sort"
-	"sync"
 	"time"
 
+	"github.com/gowvp/gb28181/internal/core/gb28181"
 	"github.com/gowvp/gb28181/pkg/gbs/sip"
 )
 
-// 获取录像文件列表
-func SipRecordList(to *Channels, start, end int64) (*Records, error) {
-	sn := sip.RandInt(100000, 999999)
-	resp := make(chan Records, 1)
-	defer close(resp)
-	device, ok := _activeDevices.Get(to.DeviceID)
-	if !ok {
-		return nil, errors.New("设备不在线")
-	}
-	channelURI, _ := sip.ParseURI(to.URIStr)
-	to.addr = &sip.Address{URI: channelURI}
-	recordKey := fmt.Sprintf("%s%d", to.ChannelID, sn)
-	_recordList.Store(recordKey, recordList{channelid: to.ChannelID, resp: resp, data: [][]int64{}, l: &sync.Mutex{}, s: start, e: end})
-	defer _recordList.Delete(recordKey)
-	hb := sip.NewHeaderBuilder().SetTo(to.addr).SetFrom(_serverDevices.addr).AddVia(&sip.ViaHop{
-		Params: sip.NewParams().Add("branch", sip.String{Str: sip.GenerateBranch()}),
-	}).SetContentType(&sip.ContentTypeXML).SetMethod(sip.MethodMessage)
-	req := sip.NewRequest("", sip.MethodMessage, to.addr.URI, sip.DefaultSipVersion, hb.Build(), sip.GetRecordInfoXML(to.ChannelID, sn, start, end))
-	req.SetDestination(device.source)
-	tx, err := svr.Request(req)
-	if err != nil {
-		return nil, err
-	}
-	response := tx.GetResponse()
-	if response.StatusCode() != http.StatusOK {
-		return nil, errors.New(response.Reason())
-	}
-	tick := time.NewTicker(10 * time.Second)
-	select {
-	case res := <-resp:
-		return &res, nil
-	case <-tick.C:
-		// 10秒未完成返回当前获取到的数据
-		if list, ok := _recordList.Load(recordKey); ok {
-			info := list.(recordList)
-			data := transRecordList(info.data)
-			return &data, nil
-		}
-		return nil, errors.New("获取数据超时")
-	}
-}
-
 // MessageRecordInfoResponse 目录列表
 type MessageRecordInfoResponse struct {
 	CmdType  string       `xml:"CmdType"`
@@ -76,52 +33,82 @@ type RecordItem struct {
 	Type      string `xml:"Type" bson:"Type" json:"Type"`
 }
 
-type recordList struct {
-	channelid string
-	resp      chan Records
-	num       int
-	data      [][]int64
-	l         *sync.Mutex
-	s, e      int64
+// RecordInfoInput 录像查询参数，时间为 unix 秒
+type RecordInfoInput struct {
+	Channel    *gb28181.Channel
+	Start, End int64
 }
 
-// 当前获取目录文件设备集合
-var _recordList *sync.Map
+func recordKey(channelID string, sn int) string {
+	return fmt.Sprintf("%s:%d", channelID, sn)
+}
 
-func sipMessageRecordInfo(u Devices, body []byte) error {
-	message := &MessageRecordInfoResponse{}
-	if err := sip.XMLDecode(body, message); err != nil {
-		// logrus.Errorln("Message Unmarshal xml err:", err, "body:", string(body))
-		return err
+// QueryRecordInfo 查询设备录像，多包应答通过 SN 关联收集后按天合并
+// GB/T28181 A.2.4.5
+func (g *GB28181API) QueryRecordInfo(in *RecordInfoInput) (*Records, error) {
+	ch, ok := g.svr.memoryStorer.GetChannel(in.Channel.DeviceID, in.Channel.ChannelID)
+	if !ok {
+		return nil, ErrChannelNotExist
 	}
-	recordKey := fmt.Sprintf("%s%d", message.DeviceID, message.SN)
-	if list, ok := _recordList.Load(recordKey); ok {
-		info := list.(recordList)
-		info.l.Lock()
-		defer info.l.Unlock()
-		info.num += len(message.Item)
-		var sint, eint int64
-		for _, item := range message.Item {
-			s, _ := time.ParseInLocation("2006-01-02T15:04:05", item.StartTime, time.Local)
-			e, _ := time.ParseInLocation("2006-01-02T15:04:05", item.EndTime, time.Local)
-			sint = s.Unix()
-			eint = e.Unix()
-			if sint < info.s {
-				sint = info.s
-			}
-			if eint > info.e {
-				eint = info.e
-			}
-			info.data = append(info.data, []int64{sint, eint})
-		}
-		if info.num == message.SumNum {
-			// 获取到完整数据
-			info.resp <- transRecordList(info.data)
+	if !ch.device.IsOnline {
+		return nil, ErrDeviceOffline
+	}
+
+	sn := sip.RandInt(100000, 999999)
+	key := recordKey(in.Channel.ChannelID, sn)
+	result := g.recordResults.register(key)
+	defer g.recordResults.cancel(key)
+	g.records.Run(key)
+
+	body := sip.GetRecordInfoXML(in.Channel.ChannelID, sn, in.Start, in.End)
+	tx, err := g.svr.wrapRequest(ch, sip.MethodMessage, &sip.ContentTypeXML, body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sipResponse(tx); err != nil {
+		return nil, err
+	}
+
+	var items []*RecordItem
+	if v, err := g.recordResults.wait(result, 15*time.Second); err == nil {
+		items = *v
+	}
+
+	data := make([][]int64, 0, len(items))
+	for _, item := range items {
+		s, err1 := time.ParseInLocation("2006-01-02T15:04:05", item.StartTime, time.Local)
+		e, err2 := time.ParseInLocation("2006-01-02T15:04:05", item.EndTime, time.Local)
+		if err1 != nil || err2 != nil {
+			continue
 		}
-		_recordList.Store(recordKey, info)
-		return nil
+		data = append(data, []int64{max(s.Unix(), in.Start), min(e.Unix(), in.End)})
+	}
+	out := transRecordList(data)
+	return &out, nil
+}
+
+// sipMessageRecordInfo 录像文件检索应答
+func (g *GB28181API) sipMessageRecordInfo(ctx *sip.Context) {
+	var msg MessageRecordInfoResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageRecordInfo", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+
+	key := recordKey(msg.DeviceID, msg.SN)
+	// 没有录像时设备只应答一包且 SumNum 为 0，直接结束收集
+	if msg.SumNum == 0 {
+		g.records.Write(&sip.CollectorMsg[RecordItem]{Key: key})
+	}
+	for _, item := range msg.Item {
+		g.records.Write(&sip.CollectorMsg[RecordItem]{
+			Key:   key,
+			Data:  &item,
+			Total: msg.SumNum,
+		})
 	}
-	return errors.New("recordlist devices not found")
+	ctx.String(200, "OK")
 }
 
 // Records Records
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 
@@ -23,9 +24,33 @@ type GB28181API struct {
 	core gb28181.GB28181
 
 	catalog *sip.Collector[Channels]
+	presets *sip.Collector[PresetItem]
+	records *sip.Collector[RecordItem]
+	// recordResults 录像查询收集完成的结果，查询方超时后的结果直接丢弃
+	recordResults *waiter[[]*RecordItem]
 
 	// TODO: 待替换成 redis
 	streams *conc.Map[string, *Streams]
+	// subs 向设备发起的订阅，key 为 deviceID:event
+	subs *conc.Map[string, *subscription]
+	// controls 设备控制应答
+	controls *waiter[ControlResponse]
+	// configs 设备配置查询应答
+	configs *waiter[ConfigDownloadResponse]
+	// broadcasts 语音广播会话，key 为 deviceID:channelID
+	broadcasts *conc.Map[string, *broadcastSession]
+	// cascades 向上级平台的级联注册，key 为平台 ID
+	cascades *conc.Map[string, *cascadeClient]
+	// cascadeCalls 上级平台的点播会话，key 为 Call-ID
+	cascadeCalls *conc.Map[string, *cascadeCall]
+	// source 级联共享通道的流来源
+	source CascadeSource
+	// plays 实时点播的传输模式切换记录，key 为流 ID
+	plays *conc.Map[string, *playTrace]
+	// viewers 实时流的观看者，决定是否复用与关闭点播
+	viewers *viewerCounter
+	// ssrcs 收流 SSRC 分配
+	ssrcs *ssrcAllocator
 
 	svr *Server
 
@@ -40,9 +65,29 @@ func NewGB28181API(cfg *conf.Bootstrap, store gb28181.GB28181, sms *sms.NodeMana
 		catalog: sip.NewCollector(func(c1, c2 *Channels) bool {
 			return c1.ChannelID == c2.ChannelID
 		}),
-		streams: &conc.Map[string, *Streams]{},
+		presets: sip.NewCollector(func(p1, p2 *PresetItem) bool {
+			return p1.PresetID == p2.PresetID
+		}),
+		records: sip.NewCollector(func(r1, r2 *RecordItem) bool {
+			return r1.StartTime == r2.StartTime && r1.EndTime == r2.EndTime && r1.FilePath == r2.FilePath
+		}),
+		recordResults: &waiter[[]*RecordItem]{},
+		streams:       &conc.Map[string, *Streams]{},
+		subs:          &conc.Map[string, *subscription]{},
+		broadcasts:    &conc.Map[string, *broadcastSession]{},
+		cascades:      &conc.Map[string, *cascadeClient]{},
+		cascadeCalls:  &conc.Map[string, *cascadeCall]{},
+		controls:      &waiter[ControlResponse]{},
+		plays:         &conc.Map[string, *playTrace]{},
+		viewers:       newViewerCounter(),
+		ssrcs:         newSSRCAllocator(cfg.Sip.Domain),
+		configs:       &waiter[ConfigDownloadResponse]{},
 	}
-	go g.catalog.Start(func(s string, channel []*Channels) {
+	go g.presets.StartWithComplete(g.savePresets)
+	go g.records.Start(func(key string, items []*RecordItem) {
+		g.recordResults.notify(key, &items)
+	})
+	go g.catalog.StartWithComplete(func(s string, channel []*Channels, complete bool) {
 		// 零值不做变更，没有通道又何必注册上来
 		if len(channel) == 0 {
 			return
@@ -56,6 +101,25 @@ func NewGB28181API(cfg *conf.Bootstrap, store gb28181.GB28181, sms *sms.NodeMana
 		// 	}
 		// }
 
+		// 分组节点单独保存，不计入通道
+		groups := make([]*gb28181.CatalogGroup, 0, 8)
+		items := make([]*Channels, 0, len(channel))
+		for _, ch := range channel {
+			if gb28181.IsCatalogGroup(ch.ChannelID) {
+				groups = append(groups, ch.toCatalogGroup())
+				continue
+			}
+			items = append(items, ch)
+		}
+		// 超时仅收到部分目录时不删除分组，避免误删未上报的节点
+		if err := g.core.SaveCatalogGroups(s, groups, complete); err != nil {
+			slog.Error("SaveCatalogGroups", "err", err, "deviceID", s)
+		}
+		channel = items
+		if len(channel) == 0 {
+			return
+		}
+
 		ipc, ok := g.svr.memoryStorer.Load(s)
 		if ok {
 			for _, ch := range channel {
@@ -70,16 +134,7 @@ func NewGB28181API(cfg *conf.Bootstrap, store gb28181.GB28181, sms *sms.NodeMana
 
 		out := make([]*gb28181.Channel, len(channel))
 		for i, ch := range channel {
-			out[i] = &gb28181.Channel{
-				DeviceID:  s,
-				ChannelID: ch.ChannelID,
-				Name:      ch.Name,
-				IsOnline:  ch.Status == "OK" || ch.Status == "ON",
-				Ext: gb28181.DeviceExt{
-					Manufacturer: ch.Manufacturer,
-					Model:        ch.Model,
-				},
-			}
+			out[i] = ch.toChannel(s)
 		}
 		g.core.SaveChannels(out)
 	})
@@ -170,6 +225,11 @@ func (g *GB28181API) handlerRegister(ctx *sip.Context) {
 		respFn()
 		return
 	}
+	// 设备此前不在线或重启后重新注册时，原订阅会话已失效
+	fresh := true
+	if ipc, ok := g.svr.memoryStorer.Load(ctx.DeviceID); ok && ipc.IsOnline && ipc.registerCallID == registerCallID(ctx.Request) {
+		fresh = false
+	}
 	g.login(ctx, expire)
 
 	// conn := ctx.Request.GetConnection()
@@ -183,6 +243,9 @@ func (g *GB28181API) handlerRegister(ctx *sip.Context) {
 	g.QueryDeviceInfo(ctx)
 	_ = g.QueryCatalog(dev.DeviceID)
 	_ = g.QueryConfigDownloadBasic(dev.DeviceID)
+	_ = g.QueryDeviceStatus(dev.DeviceID)
+	// 订阅需等待设备应答，不阻塞注册处理
+	go g.ensureSubscriptions(dev.DeviceID, fresh)
 }
 
 func (g GB28181API) login(ctx *sip.Context, expire string) {
@@ -192,17 +255,38 @@ func (g GB28181API) login(ctx *sip.Context, expire string) {
 		d.RegisteredAt = orm.Now()
 		d.KeepaliveAt = orm.Now()
 		d.Expires, _ = strconv.Atoi(expire)
+		d.Ext.UserAgent = userAgent(ctx.Request)
 	}, func(d *Device) {
 		d.conn = ctx.Request.GetConnection()
 		d.source = ctx.Source
 		d.to = ctx.To
+		d.registerCallID = registerCallID(ctx.Request)
 	})
 }
 
+func registerCallID(req *sip.Request) string {
+	if callID, ok := req.CallID(); ok {
+		return string(*callID)
+	}
+	return ""
+}
+
 func (g GB28181API) logout(deviceID string, changeFn func(*gb28181.Device)) error {
 	slog.Info("status change 设备离线", "device_id", deviceID)
+	g.stopSubscriptions(deviceID)
+	g.stopBroadcasts(deviceID)
 	return g.svr.memoryStorer.Change(deviceID, changeFn, func(d *Device) {
 		d.Expires = 0
 		d.IsOnline = false
 	})
 }
+
+// userAgent 读取 User-Agent，其值可能包含冒号，不能按冒号切分
+func userAgent(req *sip.Request) string {
+	hdrs := req.GetHeaders("User-Agent")
+	if len(hdrs) == 0 {
+		return ""
+	}
+	_, v, _ := strings.Cut(hdrs[0].String(), ":")
+	return strings.TrimSpace(v)
+}
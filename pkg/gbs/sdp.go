@@ -0,0 +1,140 @@
+package gbs
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	sdp "github.com/panjjo/gosdp"
+)
+
+// decodeSDP 解析设备应答的 SDP
+// 国标扩展的 y= (SSRC) 由 gosdp 忽略，此处单独提取；部分设备携带空的 f= 行，会导致解析失败，需跳过
+func decodeSDP(body []byte) (*sdp.Message, error) {
+	var ssrc string
+	lines := bytes.Split(body, []byte("\n"))
+	buf := make([]byte, 0, len(body))
+	for _, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) <= 2 || line[1] != '=' {
+			continue
+		}
+		if line[0] == 'y' {
+			ssrc = string(line[2:])
+			continue
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\r', '\n')
+	}
+	msg, err := sdp.Decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	msg.SSRC = ssrc
+	return msg, nil
+}
+
+// sdpDownloadSpeed 读取应答中的 a=downloadspeed，未携带时返回 0
+func sdpDownloadSpeed(msg *sdp.Message) int {
+	v := msg.Attribute("downloadspeed")
+	for _, m := range msg.Medias {
+		if s := m.Attribute("downloadspeed"); s != "" {
+			v = s
+		}
+	}
+	speed, _ := strconv.Atoi(v)
+	return speed
+}
+
+// sdpAnswer 应答中设备的收发流地址
+type sdpAnswer struct {
+	IP    string // 媒体级 c= 优先，否则取会话级 c=
+	Port  int    // 视频媒体端口
+	Setup string // a=setup，TCP 时设备的角色
+	SSRC  string // y= 字段
+}
+
+// parseAnswer 读取应答中首个未被拒绝的视频媒体，没有视频时取首个媒体
+func parseAnswer(msg *sdp.Message) (sdpAnswer, error) {
+	var media *sdp.Media
+	for i := range msg.Medias {
+		m := &msg.Medias[i]
+		if m.Description.Port == 0 {
+			continue
+		}
+		if media == nil || (media.Description.Type != "video" && m.Description.Type == "video") {
+			media = m
+		}
+	}
+	if media == nil {
+		return sdpAnswer{}, ErrSDPAnswer
+	}
+	ip := media.Connection.IP
+	if ip == nil {
+		ip = msg.Connection.IP
+	}
+	if ip == nil {
+		return sdpAnswer{}, ErrSDPAnswer
+	}
+	return sdpAnswer{
+		IP:    ip.String(),
+		Port:  media.Description.Port,
+		Setup: media.Attribute("setup"),
+		SSRC:  msg.SSRC,
+	}, nil
+}
+
+// newRecvMedia 生成收流的媒体描述，formats 的顺序即优先级
+func newRecvMedia(typ string, port int, protocol string, formats []SDPFormat) sdp.Media {
+	m := sdp.Media{
+		Description: sdp.MediaDescription{
+			Type:     typ,
+			Port:     port,
+			Formats:  make([]string, 0, len(formats)),
+			Protocol: protocol,
+		},
+	}
+	m.AddAttribute("recvonly")
+	for _, f := range formats {
+		m.Description.Formats = append(m.Description.Formats, f.PT)
+		m.AddAttribute("rtpmap", f.PT, f.Codec)
+	}
+	return m
+}
+
+// staticCodecs 请求中声明的 RFC 3551 静态负载类型，应答未携带 rtpmap 时使用
+var staticCodecs = map[string]string{"0": "PCMU", "8": "PCMA"}
+
+// sdpCodecs 读取应答中各媒体首选的编码，端口为 0 的媒体表示被拒绝
+func sdpCodecs(msg *sdp.Message) gb28181.ChannelMedia {
+	var out gb28181.ChannelMedia
+	for _, m := range msg.Medias {
+		if m.Description.Port == 0 || len(m.Description.Formats) == 0 {
+			continue
+		}
+		codec := mediaCodec(&m, m.Description.Formats[0])
+		switch m.Description.Type {
+		case "video":
+			out.Video = codec
+		case "audio":
+			out.Audio = codec
+		}
+	}
+	return out
+}
+
+// mediaCodec 负载类型对应的编码名称，如 "96 PS/90000" 返回 PS
+func mediaCodec(m *sdp.Media, pt string) string {
+	for _, v := range m.Attributes.Values("rtpmap") {
+		fields := strings.Fields(v)
+		if len(fields) == 2 && fields[0] == pt {
+			name, _, _ := strings.Cut(fields[1], "/")
+			return strings.ToUpper(name)
+		}
+	}
+	if codec, ok := staticCodecs[pt]; ok {
+		return codec
+	}
+	return pt
+}
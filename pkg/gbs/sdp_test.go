@@ -0,0 +1,96 @@
+package gbs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeSDP(t *testing.T) {
+	body := "v=0\r\n" +
+		"o=34020000001320000001 0 0 IN IP4 192.168.1.2\r\n" +
+		"s=Download\r\n" +
+		"c=IN IP4 192.168.1.2\r\n" +
+		"t=0 0\r\n" +
+		"m=video 15060 RTP/AVP 96\r\n" +
+		"a=sendonly\r\n" +
+		"a=rtpmap:96 PS/90000\r\n" +
+		"a=downloadspeed:2\r\n" +
+		"y=1100000001\r\n" +
+		"f=\r\n"
+	msg, err := decodeSDP([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.SSRC != "1100000001" {
+		t.Fatalf("expect ssrc 1100000001, got %s", msg.SSRC)
+	}
+	if speed := sdpDownloadSpeed(msg); speed != 2 {
+		t.Fatalf("expect downloadspeed 2, got %d", speed)
+	}
+}
+
+func TestSDPCodecs(t *testing.T) {
+	body := "v=0\r\n" +
+		"o=34020000001320000001 0 0 IN IP4 192.168.1.2\r\n" +
+		"s=Play\r\n" +
+		"c=IN IP4 192.168.1.2\r\n" +
+		"t=0 0\r\n" +
+		"m=video 15060 RTP/AVP 100\r\n" +
+		"a=sendonly\r\n" +
+		"a=rtpmap:100 H265/90000\r\n" +
+		"m=audio 15060 RTP/AVP 8\r\n" +
+		"a=sendonly\r\n" +
+		"y=0200000001\r\n"
+	msg, err := decodeSDP([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	media := sdpCodecs(msg)
+	if media.Video != "H265" || media.Audio != "PCMA" {
+		t.Fatalf("expect H265/PCMA, got %s/%s", media.Video, media.Audio)
+	}
+}
+
+func TestParseAnswer(t *testing.T) {
+	body := "v=0\r\n" +
+		"o=34020000001320000001 0 0 IN IP4 192.168.1.2\r\n" +
+		"s=Play\r\n" +
+		"c=IN IP4 192.168.1.2\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 0 TCP/RTP/AVP 8\r\n" +
+		"m=video 15060 TCP/RTP/AVP 96\r\n" +
+		"c=IN IP4 192.168.1.3\r\n" +
+		"a=sendonly\r\n" +
+		"a=setup:passive\r\n" +
+		"a=rtpmap:96 PS/90000\r\n" +
+		"y=0200000001\r\n"
+	msg, err := decodeSDP([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := parseAnswer(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := sdpAnswer{IP: "192.168.1.3", Port: 15060, Setup: "passive", SSRC: "0200000001"}
+	if answer != expect {
+		t.Fatalf("expect %+v, got %+v", expect, answer)
+	}
+
+	msg.Medias = msg.Medias[:1]
+	if _, err := parseAnswer(msg); err != ErrSDPAnswer {
+		t.Fatalf("expect ErrSDPAnswer, got %v", err)
+	}
+}
+
+func TestAudioFormatsStaticPT(t *testing.T) {
+	for _, f := range defaultAudio {
+		codec, ok := staticCodecs[f.PT]
+		if !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Codec, "/"); name != codec {
+			t.Fatalf("pt %s offered as %s, static codec is %s", f.PT, name, codec)
+		}
+	}
+}
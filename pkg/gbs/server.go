@@ -55,14 +55,31 @@ func NewServer(cfg *conf.Bootstrap, store gb28181.GB28181, sc sms.Core) (*Server
 
 	svr = sip.NewServer(&from)
 	svr.Register(api.handlerRegister)
-	msg := svr.Message()
+	// 上级平台的请求由级联处理，其余交由设备处理
+	svr.Invite(api.cascadeInvite, api.sipInvite)
+	svr.Ack(api.sipAck)
+	svr.Bye(api.cascadeBye, api.sipBye)
+	msg := svr.Message(api.cascadeMessage)
 	msg.Handle("Keepalive", api.sipMessageKeepalive)
 	msg.Handle("Catalog", api.sipMessageCatalog)
 	msg.Handle("DeviceInfo", api.sipMessageDeviceInfo)
 	msg.Handle("ConfigDownload", api.sipMessageConfigDownload)
 	msg.Handle("DeviceConfig", api.handleDeviceConfig)
-
-	// msg.Handle("RecordInfo", api.handlerMessage)
+	msg.Handle(CMDTypePresetQuery, api.sipMessagePresetQuery)
+	msg.Handle("RecordInfo", api.sipMessageRecordInfo)
+	msg.Handle("MediaStatus", api.sipMessageMediaStatus)
+	msg.Handle("Alarm", api.sipMessageAlarm)
+	msg.Handle("MobilePosition", api.sipMessageMobilePosition)
+	msg.Handle("Broadcast", api.sipMessageBroadcast)
+	msg.Handle("DeviceControl", api.sipMessageDeviceControl)
+	msg.Handle("DeviceStatus", api.sipMessageDeviceStatus)
+	msg.Handle("UploadSnapShotFinished", api.sipMessageUploadSnapShotFinished)
+	msg.Handle("DeviceUpgradeResult", api.sipMessageDeviceUpgradeResult)
+
+	notify := svr.Notify()
+	notify.Handle("Alarm", api.sipMessageAlarm)
+	notify.Handle("Catalog", api.sipNotifyCatalog)
+	notify.Handle("MobilePosition", api.sipMessageMobilePosition)
 
 	c := Server{
 		Server:       svr,
@@ -76,6 +93,7 @@ func NewServer(cfg *conf.Bootstrap, store gb28181.GB28181, sc sms.Core) (*Server
 	go svr.ListenUDPServer(fmt.Sprintf(":%d", cfg.Sip.Port))
 	go svr.ListenTCPServer(fmt.Sprintf(":%d", cfg.Sip.Port))
 	go c.startTickerCheck()
+	go api.startStatusCheck()
 	// 等待 UDP 连接
 	for {
 		time.Sleep(50 * time.Millisecond)
@@ -84,6 +102,7 @@ func NewServer(cfg *conf.Bootstrap, store gb28181.GB28181, sc sms.Core) (*Server
 			break
 		}
 	}
+	go api.startCascades()
 	return &c, c.Close
 }
 
@@ -139,9 +158,7 @@ func LoadSYSInfo() {
 	config = m.MConfig
 	_activeDevices = ActiveDevices{sync.Map{}}
 
-	StreamList = streamsList{&sync.Map{}, &sync.Map{}, 0}
-	ssrcLock = &sync.Mutex{}
-	_recordList = &sync.Map{}
+	StreamList = streamsList{&sync.Map{}, &sync.Map{}}
 	RecordList = apiRecordList{items: map[string]*apiRecordItem{}, l: sync.RWMutex{}}
 
 	// init sysinfo
@@ -217,7 +234,197 @@ func (s *Server) StopPlay(in *StopPlayInput) error {
 	return s.gb.StopPlay(in)
 }
 
-// QuerySnapshot 厂商实现抓图的少，sip 层已实现，先搁置
-func (s *Server) QuerySnapshot(deviceID, channelID string) error {
-	return s.gb.QuerySnapshot(deviceID, channelID)
+// PlayTimeout 实时点播收流超时，切换传输模式重新点播
+func (s *Server) PlayTimeout(streamID string) (bool, error) {
+	return s.gb.PlayTimeout(streamID)
+}
+
+// PlayStarted 实时流已注册
+func (s *Server) PlayStarted(streamID string) {
+	s.gb.PlayStarted(streamID)
+}
+
+// AddViewer 记录实时流的观看者
+func (s *Server) AddViewer(streamID, id string) int {
+	return s.gb.AddViewer(streamID, id)
+}
+
+// RemoveViewer 观看者断开
+func (s *Server) RemoveViewer(streamID, id string) int {
+	return s.gb.RemoveViewer(streamID, id)
+}
+
+// Viewers 实时流的观看者数量
+func (s *Server) Viewers(streamID string) int {
+	return s.gb.Viewers(streamID)
+}
+
+// PlayAttempts 实时流的点播尝试记录
+func (s *Server) PlayAttempts(streamID string) []PlayAttempt {
+	return s.gb.PlayAttempts(streamID)
+}
+
+// QuerySnapshot 设备图像抓拍，图像由设备上传至 in.UploadURL
+func (s *Server) QuerySnapshot(in *SnapshotInput) (string, error) {
+	return s.gb.QuerySnapshot(in)
+}
+
+// PTZControl 云台控制
+func (s *Server) PTZControl(in *PTZControlInput) error {
+	return s.gb.PTZControl(in)
+}
+
+// SetPreset 设置预置位
+func (s *Server) SetPreset(in *PresetInput) error {
+	return s.gb.SetPreset(in)
+}
+
+// CallPreset 调用预置位
+func (s *Server) CallPreset(in *PresetInput) error {
+	return s.gb.CallPreset(in)
+}
+
+// DelPreset 删除预置位
+func (s *Server) DelPreset(in *PresetInput) error {
+	return s.gb.DelPreset(in)
+}
+
+// QueryPreset 查询设备预置位并同步入库
+func (s *Server) QueryPreset(ch *gb28181.Channel) error {
+	return s.gb.QueryPreset(ch)
+}
+
+// SetCruise 下发巡航轨迹
+func (s *Server) SetCruise(in *CruiseInput) error {
+	return s.gb.SetCruise(in)
+}
+
+// DelCruise 删除巡航轨迹
+func (s *Server) DelCruise(ch *gb28181.Channel, cruiseID int) error {
+	return s.gb.DelCruise(ch, cruiseID)
+}
+
+// StartCruise 开始巡航
+func (s *Server) StartCruise(ch *gb28181.Channel, cruiseID int) error {
+	return s.gb.StartCruise(ch, cruiseID)
+}
+
+// StopCruise 停止巡航
+func (s *Server) StopCruise(ch *gb28181.Channel) error {
+	return s.gb.StopCruise(ch)
+}
+
+// ScanControl 自动扫描控制
+func (s *Server) ScanControl(in *ScanInput) error {
+	return s.gb.ScanControl(in)
+}
+
+// QueryRecordInfo 查询设备录像
+func (s *Server) QueryRecordInfo(in *RecordInfoInput) (*Records, error) {
+	return s.gb.QueryRecordInfo(in)
+}
+
+// Playback 历史回放
+func (s *Server) Playback(in *PlaybackInput) error {
+	return s.gb.Playback(in)
+}
+
+// StopPlayback 停止历史回放
+func (s *Server) StopPlayback(streamID string) error {
+	return s.gb.StopPlayback(streamID)
+}
+
+// HasPlayback 是否为进行中的回放流
+func (s *Server) HasPlayback(streamID string) bool {
+	return s.gb.HasPlayback(streamID)
+}
+
+// IsChannelPlayback 回放流是否属于该通道
+func (s *Server) IsChannelPlayback(streamID, deviceID, channelID string) bool {
+	return s.gb.IsChannelPlayback(streamID, deviceID, channelID)
+}
+
+// PlaybackControl 回放控制
+func (s *Server) PlaybackControl(in *PlaybackControlInput) error {
+	return s.gb.PlaybackControl(in)
+}
+
+// Download 录像下载
+func (s *Server) Download(in *DownloadInput) (int, error) {
+	return s.gb.Download(in)
+}
+
+// StopDownload 停止下载
+func (s *Server) StopDownload(streamID string) error {
+	return s.gb.StopDownload(streamID)
+}
+
+// HasDownload 是否为进行中的下载流
+func (s *Server) HasDownload(streamID string) bool {
+	return s.gb.HasDownload(streamID)
+}
+
+// ResetAlarm 报警复位
+func (s *Server) ResetAlarm(in *ResetAlarmInput) error {
+	return s.gb.ResetAlarm(in)
+}
+
+// SubscribeMobilePosition 订阅移动设备位置
+func (s *Server) SubscribeMobilePosition(deviceID string, interval, expires int) error {
+	return s.gb.SubscribeMobilePosition(deviceID, interval, expires)
+}
+
+// UnsubscribeMobilePosition 取消移动设备位置订阅
+func (s *Server) UnsubscribeMobilePosition(deviceID string) error {
+	return s.gb.Unsubscribe(deviceID, SubscribeEventMobilePosition)
+}
+
+// QueryMobilePosition 查询移动设备位置
+func (s *Server) QueryMobilePosition(deviceID string) error {
+	return s.gb.QueryMobilePosition(deviceID)
+}
+
+// Broadcast 语音广播
+func (s *Server) Broadcast(in *BroadcastInput) error {
+	return s.gb.Broadcast(in)
+}
+
+// StopBroadcast 停止语音广播
+func (s *Server) StopBroadcast(deviceID, channelID string) error {
+	return s.gb.StopBroadcast(deviceID, channelID)
+}
+
+// DeviceControl 设备控制
+func (s *Server) DeviceControl(in *DeviceControlInput) (string, error) {
+	return s.gb.DeviceControl(in)
+}
+
+// QueryDeviceStatus 设备状态查询
+func (s *Server) QueryDeviceStatus(deviceID string) error {
+	return s.gb.QueryDeviceStatus(deviceID)
+}
+
+// QueryConfig 设备配置查询
+func (s *Server) QueryConfig(in *ConfigInput) (*ConfigParams, error) {
+	return s.gb.QueryConfig(in)
+}
+
+// DeviceConfig 设备配置
+func (s *Server) DeviceConfig(in *ConfigInput, params *ConfigParams) (string, error) {
+	return s.gb.DeviceConfig(in, params)
+}
+
+// SetCascadeSource 设置级联共享通道的流来源
+func (s *Server) SetCascadeSource(src CascadeSource) {
+	s.gb.SetCascadeSource(src)
+}
+
+// StartCascade 启动或重启向上级平台的注册，平台未启用时仅注销
+func (s *Server) StartCascade(p *gb28181.Platform) {
+	s.gb.StartCascade(p)
+}
+
+// StopCascade 注销并停止向上级平台的注册
+func (s *Server) StopCascade(platformID string) {
+	s.gb.StopCascade(platformID)
 }
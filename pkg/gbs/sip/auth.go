@@ -102,6 +102,14 @@ func (auth *Authorization) SetPassword(password string) *Authorization {
 	return auth
 }
 
+// SetCNonce 作为客户端应答 qop=auth 的质询时，设置 nc 与 cnonce
+func (auth *Authorization) SetCNonce(nc, cnonce string) *Authorization {
+	auth.nc = nc
+	auth.cnonce = cnonce
+
+	return auth
+}
+
 // CalcResponse CalcResponse
 func (auth *Authorization) CalcResponse() string {
 	auth.response = CalcResponse(
@@ -34,6 +34,7 @@ func (c *Collector[T]) Write(info *CollectorMsg[T]) {
 	c.msg <- info
 }
 
+// CollectorMsg 收集的单条数据，Data 为空且 Total 为 0 表示应答为空，立即结束收集
 type CollectorMsg[T any] struct {
 	Key   string
 	Data  *T
@@ -65,10 +66,20 @@ func (c *Collector[T]) Wait(key string) {
 	c.observer.DefaultRegister(key)
 }
 
+// WaitWithTimeout 自定义等待时间，适用于多包应答较慢的场景
+func (c *Collector[T]) WaitWithTimeout(key string, duration time.Duration) {
+	c.observer.RegisterWithTimeout(key, duration)
+}
+
 // Start 启动定时任务检查和保存数据
 func (c *Collector[T]) Start(save func(string, []*T)) {
-	fn := func(k string, data []*T) {
-		save(k, data)
+	c.StartWithComplete(func(k string, data []*T, _ bool) { save(k, data) })
+}
+
+// StartWithComplete 同 Start，complete 表示数据已按总数收齐，超时保存的部分数据为 false
+func (c *Collector[T]) StartWithComplete(save func(key string, data []*T, complete bool)) {
+	fn := func(k string, data []*T, complete bool) {
+		save(k, data, complete)
 		c.observer.Notify(k)
 	}
 
@@ -78,13 +89,13 @@ func (c *Collector[T]) Start(save func(string, []*T)) {
 		select {
 		case <-check.C:
 			for k, v := range c.data {
-				if time.Since(v.lastUpdateAt) > 10*time.Second {
-					fn(k, v.data)
+				if v.total > 0 && len(v.data) >= v.total {
+					fn(k, v.data, true)
 					delete(c.data, k)
 					continue
 				}
-				if v.total > 0 && len(v.data) >= v.total {
-					fn(k, v.data)
+				if time.Since(v.lastUpdateAt) > 10*time.Second {
+					fn(k, v.data, false)
 					delete(c.data, k)
 					continue
 				}
@@ -97,6 +108,13 @@ func (c *Collector[T]) Start(save func(string, []*T)) {
 				slog.Debug("key 不存在或已过期", "key", msg.Key, "data", msg.Data)
 				continue
 			}
+			if msg.Data == nil {
+				if msg.Total == 0 {
+					fn(msg.Key, data.data, true)
+					delete(c.data, msg.Key)
+				}
+				continue
+			}
 			// 如果数据已存在且无重复，跳过该消息
 			if slices.ContainsFunc(data.data, func(v *T) bool {
 				return c.noRepeatFn(v, msg.Data)
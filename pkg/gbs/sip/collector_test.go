@@ -0,0 +1,54 @@
+package sip
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollectorEmptyFinish(t *testing.T) {
+	c := NewCollector(func(a, b *int) bool { return *a == *b })
+	saved := make(chan []*int, 1)
+	var complete bool
+	go c.StartWithComplete(func(_ string, data []*int, ok bool) {
+		complete = ok
+		saved <- data
+	})
+
+	c.Run("k")
+	// 等待 Start 处理创建请求
+	time.Sleep(50 * time.Millisecond)
+	c.Write(&CollectorMsg[int]{Key: "k"})
+
+	select {
+	case data := <-saved:
+		if len(data) != 0 {
+			t.Fatalf("expect empty data, got %d", len(data))
+		}
+		if !complete {
+			t.Fatal("expect empty reply complete")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expect finish without waiting for timeout")
+	}
+}
+
+func TestCollectorComplete(t *testing.T) {
+	c := NewCollector(func(a, b *int) bool { return *a == *b })
+	done := make(chan bool, 1)
+	go c.StartWithComplete(func(_ string, _ []*int, complete bool) { done <- complete })
+
+	c.Run("k")
+	time.Sleep(50 * time.Millisecond)
+	a, b := 1, 2
+	c.Write(&CollectorMsg[int]{Key: "k", Data: &a, Total: 2})
+	c.Write(&CollectorMsg[int]{Key: "k", Data: &b, Total: 2})
+
+	select {
+	case complete := <-done:
+		if !complete {
+			t.Fatal("expect complete when total reached")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect finish when total reached")
+	}
+}
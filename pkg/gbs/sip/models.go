@@ -76,6 +76,9 @@ var ContentTypeSDP = ContentType("application/sdp")
 // ContentTypeXML XML contenttype
 var ContentTypeXML = ContentType("Application/MANSCDP+xml")
 
+// ContentTypeMANSRTSP 回放控制 contenttype
+var ContentTypeMANSRTSP = ContentType("Application/MANSRTSP")
+
 var (
 	// CatalogXML 获取设备列表xml样式
 	CatalogXML = `<?xml version="1.0" encoding="GB2312"?>
@@ -107,6 +110,20 @@ var (
 `
 )
 
+// DeviceStatusXML 查询设备状态xml样式
+const DeviceStatusXML = `<?xml version="1.0" encoding="GB2312"?>
+<Query>
+<CmdType>DeviceStatus</CmdType>
+<SN>%d</SN>
+<DeviceID>%s</DeviceID>
+</Query>
+`
+
+// GetDeviceStatusXML 获取设备状态指令
+func GetDeviceStatusXML(id string) []byte {
+	return []byte(fmt.Sprintf(DeviceStatusXML, RandInt(100000, 999999), id))
+}
+
 // GetDeviceInfoXML 获取设备详情指令
 func GetDeviceInfoXML(id string) []byte {
 	return []byte(fmt.Sprintf(DeviceInfoXML, RandInt(100000, 999999), id))
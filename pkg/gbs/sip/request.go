@@ -97,6 +97,45 @@ func NewRequestFromResponse(method string, inviteResponse *Response) *Request {
 	return ackRequest
 }
 
+// NewRequestFromInvite 作为被叫方在 INVITE 建立的会话内发起请求，如 BYE
+// From/To 与 INVITE 互换，answer 为本端发送的 2xx 应答，携带本端 tag
+func NewRequestFromInvite(method string, invite *Request, answer *Response, seqNo uint32) *Request {
+	target := invite.Recipient()
+	if from, ok := invite.From(); ok {
+		target = from.Address
+	}
+	if contact, ok := invite.Contact(); ok {
+		target = contact.Address
+	}
+	req := NewRequest("", method, target, invite.SipVersion(), []Header{}, []byte{})
+
+	transport := "UDP"
+	if via, ok := invite.ViaHop(); ok {
+		transport = via.Transport
+	}
+	req.AppendHeader(ViaHeader{&ViaHop{
+		ProtocolName:    "SIP",
+		ProtocolVersion: "2.0",
+		Transport:       transport,
+		Params:          NewParams().Add("branch", String{Str: GenerateBranch()}),
+	}})
+	if to, ok := answer.To(); ok {
+		req.AppendHeader(&FromHeader{DisplayName: to.DisplayName, Address: to.Address, Params: to.Params})
+	}
+	if from, ok := invite.From(); ok {
+		req.AppendHeader(&ToHeader{DisplayName: from.DisplayName, Address: from.Address, Params: from.Params})
+	}
+	CopyHeaders("Call-ID", invite, req)
+	req.AppendHeader(&CSeq{SeqNo: seqNo, MethodName: method})
+	maxForwards := MaxForwards(70)
+	req.AppendHeader(&maxForwards)
+
+	req.SetSource(invite.Destination())
+	req.SetDestination(invite.Source())
+	req.SetConnection(invite.GetConnection())
+	return req
+}
+
 // StartLine returns Request Line - RFC 2361 7.1.
 func (req *Request) StartLine() string {
 	var buffer bytes.Buffer
@@ -62,6 +62,19 @@ func (s *Server) Register(handler ...HandlerFunc) {
 	s.addRoute(MethodRegister, handler...)
 }
 
+// Invite 设备主动发起的 INVITE，如语音广播
+func (s *Server) Invite(handler ...HandlerFunc) {
+	s.addRoute(MethodInvite, handler...)
+}
+
+func (s *Server) Ack(handler ...HandlerFunc) {
+	s.addRoute(MethodACK, handler...)
+}
+
+func (s *Server) Bye(handler ...HandlerFunc) {
+	s.addRoute(MethodBYE, handler...)
+}
+
 func (s *Server) Message(handler ...HandlerFunc) *RouteGroup {
 	s.addRoute(MethodMessage, handler...)
 	return newRouteGroup(MethodMessage, s, handler...)
@@ -0,0 +1,81 @@
+package gbs
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// maxSSRCSeq SSRC 末 4 位序号的上限
+const maxSSRCSeq = 9999
+
+// ssrcAllocator 收流 SSRC 分配，GB/T28181 附录 G
+// 10 位十进制：首位 0 实时 1 历史，第 2~6 位取 SIP 域编码第 4~8 位，末 4 位为序号
+// 已分配的 SSRC 在会话结束前不会重复分配，避免并发点播时串流
+type ssrcAllocator struct {
+	mu     sync.Mutex
+	prefix string
+	seq    int
+	used   map[string]struct{}
+}
+
+func newSSRCAllocator(domain string) *ssrcAllocator {
+	prefix := "00000"
+	if len(domain) >= 8 {
+		prefix = domain[3:8]
+	}
+	return &ssrcAllocator{prefix: prefix, used: make(map[string]struct{})}
+}
+
+// Alloc 分配并占用一个 SSRC，history 为 true 时用于历史回放/下载
+func (a *ssrcAllocator) Alloc(history bool) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for range maxSSRCSeq {
+		ssrc := a.next(history)
+		if _, ok := a.used[ssrc]; ok {
+			continue
+		}
+		a.used[ssrc] = struct{}{}
+		return ssrc, nil
+	}
+	return "", ErrSSRCExhausted
+}
+
+// Next 生成一个格式合规但不占用的 SSRC，用于对端未指定时的发流
+func (a *ssrcAllocator) Next(history bool) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.next(history)
+}
+
+// Release 会话结束后释放
+func (a *ssrcAllocator) Release(ssrc string) {
+	if ssrc == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.used, ssrc)
+}
+
+func (a *ssrcAllocator) next(history bool) string {
+	a.seq++
+	if a.seq > maxSSRCSeq {
+		a.seq = 1
+	}
+	flag := 0
+	if history {
+		flag = 1
+	}
+	return fmt.Sprintf("%d%s%04d", flag, a.prefix, a.seq)
+}
+
+// validSSRC SSRC 应为可表示为 uint32 的十进制数字
+func validSSRC(ssrc string) bool {
+	if ssrc == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(ssrc, 10, 32)
+	return err == nil
+}
@@ -0,0 +1,77 @@
+package gbs
+
+import (
+	"testing"
+
+	sdp "github.com/panjjo/gosdp"
+)
+
+func TestSSRCAllocator(t *testing.T) {
+	a := newSSRCAllocator("3402000000")
+
+	live, err := a.Alloc(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if live != "0200000001" {
+		t.Fatalf("expect 0200000001, got %s", live)
+	}
+	history, err := a.Alloc(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history != "1200000002" {
+		t.Fatalf("expect 1200000002, got %s", history)
+	}
+
+	// 序号回绕后跳过仍被占用的 SSRC
+	a.seq = maxSSRCSeq
+	if ssrc, _ := a.Alloc(false); ssrc != "0200000002" {
+		t.Fatalf("expect 0200000002, got %s", ssrc)
+	}
+	a.Release(live)
+	a.seq = maxSSRCSeq
+	if ssrc, _ := a.Alloc(false); ssrc != live {
+		t.Fatalf("expect released %s, got %s", live, ssrc)
+	}
+}
+
+func TestSSRCAllocatorExhausted(t *testing.T) {
+	a := newSSRCAllocator("3402000000")
+	for range maxSSRCSeq {
+		if _, err := a.Alloc(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.Alloc(false); err != ErrSSRCExhausted {
+		t.Fatalf("expect ErrSSRCExhausted, got %v", err)
+	}
+}
+
+func TestValidSSRC(t *testing.T) {
+	for ssrc, expect := range map[string]bool{
+		"0200000001": true,
+		"1200000001": true,
+		"":           false,
+		"abc":        false,
+		"9999999999": false, // 超出 uint32
+	} {
+		if validSSRC(ssrc) != expect {
+			t.Fatalf("validSSRC(%q) expect %v", ssrc, expect)
+		}
+	}
+}
+
+func TestCheckAnswerSSRC(t *testing.T) {
+	for answer, expect := range map[string]error{
+		"":           nil,
+		"0200000001": nil,
+		"abc":        nil, // 不合法的 y= 仅记录
+		"0200000002": ErrSSRCMismatch,
+	} {
+		stream := Streams{ssrc: "0200000001", Answer: &sdp.Message{SSRC: answer}}
+		if err := checkAnswerSSRC(&stream); err != expect {
+			t.Fatalf("answer %q expect %v, got %v", answer, expect, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package gbs
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/ixugo/goddd/pkg/conc"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// DeviceStatusResponse 设备状态查询应答 GB/T28181 A.2.6.6
+type DeviceStatusResponse struct {
+	CmdType    string            `xml:"CmdType"`
+	SN         int               `xml:"SN"`
+	DeviceID   string            `xml:"DeviceID"`
+	Result     string            `xml:"Result"`
+	Online     string            `xml:"Online"`
+	Status     string            `xml:"Status"`
+	Reason     string            `xml:"Reason"`
+	Encode     string            `xml:"Encode"`
+	Record     string            `xml:"Record"`
+	DeviceTime string            `xml:"DeviceTime"`
+	Alarms     []AlarmStatusItem `xml:"Alarmstatus>Item"` // 标准中为 Alarmstatus
+	Alarms2    []AlarmStatusItem `xml:"AlarmStatus>Item"` // 兼容部分设备
+}
+
+type AlarmStatusItem struct {
+	DeviceID   string `xml:"DeviceID"`
+	DutyStatus string `xml:"DutyStatus"`
+}
+
+func (m *DeviceStatusResponse) toStatus() *gb28181.DeviceStatus {
+	out := gb28181.DeviceStatus{
+		Online:     m.Online,
+		Status:     m.Status,
+		Reason:     m.Reason,
+		Encode:     m.Encode,
+		Record:     m.Record,
+		DeviceTime: m.DeviceTime,
+		UpdatedAt:  orm.Now(),
+	}
+	for _, items := range [][]AlarmStatusItem{m.Alarms, m.Alarms2} {
+		for _, item := range items {
+			out.Alarms = append(out.Alarms, gb28181.AlarmStatus{DeviceID: item.DeviceID, DutyStatus: item.DutyStatus})
+		}
+	}
+	return &out
+}
+
+// QueryDeviceStatus 设备状态查询，结果通过应答异步更新
+// GB/T28181 A.2.4.5
+func (g *GB28181API) QueryDeviceStatus(deviceID string) error {
+	ipc, ok := g.svr.memoryStorer.Load(deviceID)
+	if !ok {
+		return ErrDeviceNotExist
+	}
+	if !ipc.IsOnline {
+		return ErrDeviceOffline
+	}
+	tx, err := g.svr.wrapRequest(ipc, sip.MethodMessage, &sip.ContentTypeXML, sip.GetDeviceStatusXML(deviceID))
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
+
+// sipMessageDeviceStatus 设备状态查询应答
+func (g *GB28181API) sipMessageDeviceStatus(ctx *sip.Context) {
+	var msg DeviceStatusResponse
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageDeviceStatus", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	status := msg.toStatus()
+	if err := g.core.Edit(ctx.DeviceID, func(d *gb28181.Device) {
+		d.Ext.Status = status
+	}); err != nil {
+		ctx.Log.Error("Edit", "err", err)
+	}
+}
+
+// startStatusCheck 定时查询在线设备状态
+func (g *GB28181API) startStatusCheck() {
+	if g.cfg.DeviceStatusInterval <= 0 {
+		return
+	}
+	every := time.Duration(g.cfg.DeviceStatusInterval) * time.Second
+	conc.Timer(context.Background(), every, every, func() {
+		g.svr.memoryStorer.RangeDevices(func(key string, ipc *Device) bool {
+			if !ipc.IsOnline {
+				return true
+			}
+			// 逐个查询，避免同一时刻大量应答
+			if err := g.QueryDeviceStatus(key); err != nil {
+				slog.Warn("QueryDeviceStatus", "deviceID", key, "err", err)
+			}
+			return true
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package gbs
+
+import (
+	"testing"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+func TestDeviceStatusResponse(t *testing.T) {
+	body := `<?xml version="1.0" encoding="GB2312"?>
+<Response>
+<CmdType>DeviceStatus</CmdType>
+<SN>248</SN>
+<DeviceID>34020000001110000001</DeviceID>
+<Result>OK</Result>
+<Online>ONLINE</Online>
+<Status>OK</Status>
+<Encode>ON</Encode>
+<Record>OFF</Record>
+<DeviceTime>2024-05-01T10:20:30</DeviceTime>
+<Alarmstatus Num="2">
+<Item><DeviceID>34020000001340000001</DeviceID><DutyStatus>ONDUTY</DutyStatus></Item>
+<Item><DeviceID>34020000001340000002</DeviceID><DutyStatus>ALARM</DutyStatus></Item>
+</Alarmstatus>
+</Response>`
+	var msg DeviceStatusResponse
+	if err := sip.XMLDecode([]byte(body), &msg); err != nil {
+		t.Fatal(err)
+	}
+	status := msg.toStatus()
+	if status.Online != "ONLINE" || status.Encode != "ON" || status.Record != "OFF" || status.DeviceTime != "2024-05-01T10:20:30" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if len(status.Alarms) != 2 || status.Alarms[1].DutyStatus != "ALARM" {
+		t.Fatalf("unexpected alarms %+v", status.Alarms)
+	}
+}
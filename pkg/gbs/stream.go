@@ -1,12 +1,13 @@
 package gbs
 
 import (
-	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/gowvp/gb28181/internal/core/sms"
 	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	sdp "github.com/panjjo/gosdp"
 )
 
 // Streams Streams
@@ -45,10 +46,13 @@ type Streams struct {
 	Stream bool `json:"stream" gorm:"column:stream"`
 
 	// ---
-	S, E time.Time     `json:"-" gorm:"-"`
-	ssrc string        // 国标ssrc 10进制字符串
-	Ext  int64         `json:"-" gorm:"-"` // 流等待过期时间
-	Resp *sip.Response `json:"-" gorm:"-"`
+	S, E time.Time        `json:"-" gorm:"-"`
+	ssrc string           // 国标ssrc 10进制字符串
+	svr  *sms.MediaServer // 收流的流媒体
+	Ext  int64            `json:"-" gorm:"-"` // 流等待过期时间
+	Resp *sip.Response    `json:"-" gorm:"-"`
+	// 设备应答的 SDP
+	Answer *sdp.Message `json:"-" gorm:"-"`
 }
 
 // 当前系统中存在的流列表
@@ -57,27 +61,10 @@ type streamsList struct {
 	Response *sync.Map
 	// key=channelid value={Play}  当前设备直播信息，防止重复直播
 	Succ *sync.Map
-	ssrc int
 }
 
 var StreamList streamsList
 
-func (g *GB28181API) getSSRC(t int) string {
-	r := false
-	for {
-		StreamList.ssrc++
-		// ssrc最大为四位数，超过时从1开始重新计算
-		if StreamList.ssrc > 9000 && !r {
-			StreamList.ssrc = 0
-			r = true
-		}
-		key := fmt.Sprintf("%d%s%04d", t, g.cfg.Domain[3:8], StreamList.ssrc)
-		// stream := Streams{StreamID: ssrc2stream(key), Stop: false}
-		// if err := db.Get(db.DBClient, &stream); db.RecordNotFound(err) || stream.CreatedAt == 0 {
-		return key
-	}
-}
-
 // 定时检查未关闭的流
 // 检查规则：
 // 1. 数据库查询当前status=0在推流状态的所有流信息
@@ -0,0 +1,81 @@
+package gbs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StreamProfile 码流类型，取值即设备的码流编号
+type StreamProfile int
+
+const (
+	StreamMain  StreamProfile = iota // 主码流
+	StreamSub                        // 子码流
+	StreamThird                      // 第三码流
+)
+
+var streamProfileNames = [...]string{"main", "sub", "third"}
+
+// ParseStreamProfile 解析 main/sub/third，为空时为主码流
+func ParseStreamProfile(s string) (StreamProfile, error) {
+	if s == "" {
+		return StreamMain, nil
+	}
+	for i, name := range streamProfileNames {
+		if strings.EqualFold(s, name) {
+			return StreamProfile(i), nil
+		}
+	}
+	return StreamMain, ErrStreamProfile
+}
+
+func (p StreamProfile) String() string {
+	if p < 0 || int(p) >= len(streamProfileNames) {
+		return streamProfileNames[0]
+	}
+	return streamProfileNames[p]
+}
+
+// StreamID 码流在流媒体上的流 ID，主码流沿用通道 ID，其它码流追加后缀
+func (p StreamProfile) StreamID(channelID string) string {
+	if p == StreamMain {
+		return channelID
+	}
+	return channelID + "_" + p.String()
+}
+
+// ParseStreamID 由流 ID 解析通道 ID 与码流类型
+func ParseStreamID(stream string) (string, StreamProfile) {
+	for i := len(streamProfileNames) - 1; i > 0; i-- {
+		if id, ok := strings.CutSuffix(stream, "_"+streamProfileNames[i]); ok {
+			return id, StreamProfile(i)
+		}
+	}
+	return stream, StreamMain
+}
+
+// VideoParam GB/T28181-2016 附录 F 的 f= 视频参数，零值字段表示不指定
+type VideoParam struct {
+	Codec       int `form:"codec" json:"codec"`               // 编码格式 1 MPEG-4 2 H.264 3 SVAC 4 3GP 5 H.265
+	Resolution  int `form:"resolution" json:"resolution"`     // 分辨率 1 QCIF 2 CIF 3 4CIF 4 D1 5 720P 6 1080P/I
+	FrameRate   int `form:"frame_rate" json:"frame_rate"`     // 帧率 0~99
+	BitrateType int `form:"bitrate_type" json:"bitrate_type"` // 码率类型 1 固定码率 2 可变码率
+	Bitrate     int `form:"bitrate" json:"bitrate"`           // 码率，kbps
+}
+
+// IsZero 未指定任何参数
+func (v *VideoParam) IsZero() bool {
+	return v == nil || *v == VideoParam{}
+}
+
+// sdpLine 生成 f= 行，音频参数不指定
+func (v *VideoParam) sdpLine() string {
+	item := func(i int) string {
+		if i <= 0 {
+			return ""
+		}
+		return fmt.Sprint(i)
+	}
+	return fmt.Sprintf("f=v/%s/%s/%s/%s/%sa///\r\n",
+		item(v.Codec), item(v.Resolution), item(v.FrameRate), item(v.BitrateType), item(v.Bitrate))
+}
@@ -0,0 +1,41 @@
+package gbs
+
+import "testing"
+
+func TestStreamProfile(t *testing.T) {
+	for s, expect := range map[string]StreamProfile{"": StreamMain, "main": StreamMain, "SUB": StreamSub, "third": StreamThird} {
+		p, err := ParseStreamProfile(s)
+		if err != nil || p != expect {
+			t.Fatalf("ParseStreamProfile(%q) expect %v, got %v err %v", s, expect, p, err)
+		}
+	}
+	if _, err := ParseStreamProfile("fourth"); err != ErrStreamProfile {
+		t.Fatalf("expect ErrStreamProfile, got %v", err)
+	}
+
+	const channelID = "gb_abc"
+	for _, p := range []StreamProfile{StreamMain, StreamSub, StreamThird} {
+		id, profile := ParseStreamID(p.StreamID(channelID))
+		if id != channelID || profile != p {
+			t.Fatalf("ParseStreamID(%s) got %s %v", p.StreamID(channelID), id, profile)
+		}
+	}
+	if StreamMain.StreamID(channelID) != channelID {
+		t.Fatal("main stream should keep channel id")
+	}
+}
+
+func TestVideoParamSDPLine(t *testing.T) {
+	v := VideoParam{Codec: 2, Resolution: 2, FrameRate: 15, BitrateType: 2, Bitrate: 512}
+	if line := v.sdpLine(); line != "f=v/2/2/15/2/512a///\r\n" {
+		t.Fatalf("unexpected f= line %q", line)
+	}
+	v = VideoParam{Resolution: 6}
+	if line := v.sdpLine(); line != "f=v//6///a///\r\n" {
+		t.Fatalf("unexpected f= line %q", line)
+	}
+	var empty *VideoParam
+	if !empty.IsZero() || !(&VideoParam{}).IsZero() {
+		t.Fatal("expect zero video param")
+	}
+}
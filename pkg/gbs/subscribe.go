@@ -0,0 +1,244 @@
+package gbs
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+)
+
+// 订阅事件类型
+const (
+	SubscribeEventCatalog = "Catalog"
+)
+
+// subscription 向设备发起的订阅会话，到期前在会话内刷新
+type subscription struct {
+	mu       sync.Mutex
+	deviceID string
+	event    string
+	eventID  int // Event 头 id 参数，会话内刷新保持不变
+	expires  int
+	body     func() []byte // 订阅消息体，刷新时 SN 需变化
+	resp     *sip.Response // 订阅成功的应答，用于会话内刷新和取消
+	timer    *time.Timer
+	closed   bool
+}
+
+func subscriptionKey(deviceID, event string) string {
+	return deviceID + ":" + event
+}
+
+// refreshAfter 有效期过去 4/5 时刷新
+func (s *subscription) refreshAfter() time.Duration {
+	return time.Duration(s.expires) * time.Second * 4 / 5
+}
+
+func (s *subscription) eventHeader() *sip.GenericHeader {
+	return &sip.GenericHeader{HeaderName: "Event", Contents: fmt.Sprintf("%s;id=%d", s.event, s.eventID)}
+}
+
+// Subscribe 向设备发起订阅，已存在的同类订阅先在原会话内取消再替换，避免设备保留多个订阅重复通知
+// GB/T28181 9.11
+func (g *GB28181API) Subscribe(deviceID, event string, expires int, body func() []byte) error {
+	if expires <= 0 {
+		return nil
+	}
+	if old, ok := g.subs.LoadAndDelete(subscriptionKey(deviceID, event)); ok {
+		if err := g.unsubscribe(old); err != nil {
+			slog.Warn("取消原订阅失败", "deviceID", deviceID, "event", event, "err", err)
+		}
+	}
+
+	sub := subscription{
+		deviceID: deviceID,
+		event:    event,
+		eventID:  sip.RandInt(100000, 999999),
+		expires:  expires,
+		body:     body,
+	}
+	if err := g.sendSubscribe(&sub, false); err != nil {
+		return err
+	}
+	g.subs.Store(subscriptionKey(deviceID, event), &sub)
+	return nil
+}
+
+// Unsubscribe 取消订阅，Expires 为 0 的会话内 SUBSCRIBE
+func (g *GB28181API) Unsubscribe(deviceID, event string) error {
+	sub, ok := g.subs.LoadAndDelete(subscriptionKey(deviceID, event))
+	if !ok {
+		return nil
+	}
+	return g.unsubscribe(sub)
+}
+
+// unsubscribe 停止刷新并在会话内取消
+func (g *GB28181API) unsubscribe(sub *subscription) error {
+	sub.stop()
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.resp == nil {
+		return nil
+	}
+	ipc, ok := g.svr.memoryStorer.Load(sub.deviceID)
+	if !ok || !ipc.IsOnline {
+		return nil
+	}
+	req := g.newSubscribeRequest(sub, sub.resp, 0)
+	req.SetDestination(ipc.Source())
+	req.SetConnection(ipc.Conn())
+	tx, err := g.svr.Request(req)
+	if err != nil {
+		return err
+	}
+	_, err = sipResponse(tx)
+	return err
+}
+
+// subscribeEvents 设备注册后需要维持的订阅
+func (g *GB28181API) subscribeEvents(deviceID string) map[string]func() error {
+	return map[string]func() error{
+		SubscribeEventCatalog: func() error { return g.SubscribeCatalog(deviceID) },
+		SubscribeEventMobilePosition: func() error {
+			return g.SubscribeMobilePosition(deviceID, g.cfg.MobilePositionInterval, g.cfg.MobilePositionSubscribeExpires)
+		},
+	}
+}
+
+// ensureSubscriptions 设备注册后维持订阅
+// 首次注册或设备此前已离线时原会话已失效，重新订阅；注册刷新时已有的订阅由定时器在会话内刷新，仅补订缺失的订阅
+func (g *GB28181API) ensureSubscriptions(deviceID string, fresh bool) {
+	for event, subscribe := range g.subscribeEvents(deviceID) {
+		if _, ok := g.subs.Load(subscriptionKey(deviceID, event)); ok && !fresh {
+			continue
+		}
+		if err := subscribe(); err != nil {
+			slog.Warn("订阅失败", "deviceID", deviceID, "event", event, "err", err)
+		}
+	}
+}
+
+// stopSubscriptions 设备离线时停止全部订阅刷新，重新注册后再次订阅
+func (g *GB28181API) stopSubscriptions(deviceID string) {
+	for _, event := range []string{SubscribeEventCatalog, SubscribeEventMobilePosition} {
+		if sub, ok := g.subs.LoadAndDelete(subscriptionKey(deviceID, event)); ok {
+			sub.stop()
+		}
+	}
+}
+
+func (s *subscription) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// sendSubscribe 发送订阅，inDialog 为 true 时在原会话内刷新
+func (g *GB28181API) sendSubscribe(sub *subscription, inDialog bool) error {
+	ipc, ok := g.svr.memoryStorer.Load(sub.deviceID)
+	if !ok || !ipc.IsOnline {
+		return ErrDeviceOffline
+	}
+
+	sub.mu.Lock()
+	expires, last := sub.expires, sub.resp
+	sub.mu.Unlock()
+
+	var (
+		tx  *sip.Transaction
+		err error
+	)
+	if inDialog && last != nil {
+		req := g.newSubscribeRequest(sub, last, expires)
+		req.SetDestination(ipc.Source())
+		req.SetConnection(ipc.Conn())
+		tx, err = g.svr.Request(req)
+	} else {
+		tx, err = g.svr.wrapRequest(ipc, sip.MethodSubscribe, &sip.ContentTypeXML, sub.body(), func(r *sip.Request) {
+			e := sip.Expires(expires) // nolint
+			r.AppendHeader(sub.eventHeader())
+			r.AppendHeader(&e)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := sipResponse(tx)
+	if err != nil {
+		return err
+	}
+
+	if contact, _ := resp.Contact(); contact == nil {
+		resp.AppendHeader(&sip.ContactHeader{
+			Address: ipc.To().URI,
+			Params:  sip.NewParams(),
+		})
+	}
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return nil
+	}
+	// 设备可能缩短有效期
+	if v := resp.GetHeaders("Expires"); len(v) > 0 {
+		if e, ok := v[0].(*sip.Expires); ok && *e > 0 {
+			sub.expires = int(*e)
+		}
+	}
+	sub.resp = resp
+	sub.timer = time.AfterFunc(sub.refreshAfter(), func() {
+		g.refreshSubscribe(sub)
+	})
+	slog.Debug("订阅成功", "deviceID", sub.deviceID, "event", sub.event, "expires", sub.expires)
+	return nil
+}
+
+// refreshSubscribe 会话内刷新失败时，重新发起订阅
+func (g *GB28181API) refreshSubscribe(sub *subscription) {
+	sub.mu.Lock()
+	closed := sub.closed
+	sub.mu.Unlock()
+	if closed {
+		return
+	}
+	if err := g.sendSubscribe(sub, true); err == nil {
+		return
+	}
+	if err := g.sendSubscribe(sub, false); err != nil {
+		slog.Warn("刷新订阅失败", "deviceID", sub.deviceID, "event", sub.event, "err", err)
+		// 稍后重试，设备离线时由 stopSubscriptions 结束
+		sub.mu.Lock()
+		if !sub.closed {
+			sub.timer = time.AfterFunc(time.Minute, func() {
+				g.refreshSubscribe(sub)
+			})
+		}
+		sub.mu.Unlock()
+	}
+}
+
+// newSubscribeRequest 基于订阅应答构造会话内 SUBSCRIBE
+func (g *GB28181API) newSubscribeRequest(sub *subscription, resp *sip.Response, expires int) *sip.Request {
+	req := sip.NewRequestFromResponse(sip.MethodSubscribe, resp)
+	e := sip.Expires(expires) // nolint
+	req.AppendHeader(sub.eventHeader())
+	req.AppendHeader(&e)
+	req.AppendHeader(&sip.ContentTypeXML)
+	req.AppendHeader(&sip.ContactHeader{Address: g.svr.fromAddress.URI, Params: sip.NewParams()})
+	req.SetBody(sub.body(), true)
+	return req
+}
+
+// SubscribeCatalog 订阅设备目录，变化通过 NOTIFY 增量上报
+func (g *GB28181API) SubscribeCatalog(deviceID string) error {
+	return g.Subscribe(deviceID, SubscribeEventCatalog, g.cfg.CatalogSubscribeExpires, func() []byte {
+		return sip.GetCatalogXML(deviceID)
+	})
+}
@@ -0,0 +1,56 @@
+package gbs
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/gowvp/gb28181/internal/core/gb28181"
+	"github.com/gowvp/gb28181/pkg/gbs/sip"
+	"github.com/ixugo/goddd/pkg/orm"
+)
+
+// DeviceUpgradeResultNotify 设备软件升级结果通知 GB/T28181-2022 A.2.5.10
+type DeviceUpgradeResultNotify struct {
+	CmdType             string `xml:"CmdType"`
+	SN                  int    `xml:"SN"`
+	DeviceID            string `xml:"DeviceID"`
+	SessionID           string `xml:"SessionID"`
+	Firmware            string `xml:"Firmware"`
+	Result              string `xml:"Result"` // OK/ERROR
+	UpgradeResult       string `xml:"UpgradeResult"`
+	UpgradeFailedReason string `xml:"UpgradeFailedReason"`
+}
+
+// ok 升级是否成功，部分设备使用 Result 字段
+func (n *DeviceUpgradeResultNotify) ok() bool {
+	result := n.UpgradeResult
+	if result == "" {
+		result = n.Result
+	}
+	return strings.EqualFold(result, "OK")
+}
+
+// sipMessageDeviceUpgradeResult 设备升级完成后上报结果
+func (g *GB28181API) sipMessageDeviceUpgradeResult(ctx *sip.Context) {
+	var msg DeviceUpgradeResultNotify
+	if err := sip.XMLDecode(ctx.Request.Body(), &msg); err != nil {
+		ctx.Log.Error("sipMessageDeviceUpgradeResult", "err", err, "body", hex.EncodeToString(ctx.Request.Body()))
+		ctx.String(400, ErrXMLDecode.Error())
+		return
+	}
+	ctx.String(200, "OK")
+
+	if msg.SessionID == "" {
+		ctx.Log.Warn("升级结果缺少会话 ID", "deviceID", ctx.DeviceID, "firmware", msg.Firmware)
+		return
+	}
+	err := g.core.EditUpgrade(ctx.DeviceID, msg.SessionID, func(u *gb28181.Upgrade) {
+		u.Finish(msg.ok(), msg.UpgradeFailedReason)
+	})
+	switch {
+	case orm.IsErrRecordNotFound(err):
+		ctx.Log.Warn("升级结果的会话 ID 与设备的升级任务不匹配", "deviceID", ctx.DeviceID, "sessionID", msg.SessionID)
+	case err != nil:
+		ctx.Log.Error("EditUpgrade", "err", err, "sessionID", msg.SessionID)
+	}
+}
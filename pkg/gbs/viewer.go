@@ -0,0 +1,71 @@
+package gbs
+
+import "sync"
+
+// viewerCounter 实时流的观看者，按流 ID 记录流媒体的播放会话 ID
+// on_play 时增加，播放器断开时减少，同一会话重复上报只计一次
+type viewerCounter struct {
+	mu      sync.Mutex
+	streams map[string]map[string]struct{}
+}
+
+func newViewerCounter() *viewerCounter {
+	return &viewerCounter{streams: make(map[string]map[string]struct{})}
+}
+
+// add 增加观看者，返回当前数量
+func (v *viewerCounter) add(streamID, id string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	ids, ok := v.streams[streamID]
+	if !ok {
+		ids = make(map[string]struct{})
+		v.streams[streamID] = ids
+	}
+	ids[id] = struct{}{}
+	return len(ids)
+}
+
+// remove 移除观看者，返回剩余数量
+func (v *viewerCounter) remove(streamID, id string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	ids := v.streams[streamID]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(v.streams, streamID)
+	}
+	return len(ids)
+}
+
+// count 当前观看者数量
+func (v *viewerCounter) count(streamID string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.streams[streamID])
+}
+
+// clear 会话结束时清理
+func (v *viewerCounter) clear(streamID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.streams, streamID)
+}
+
+// AddViewer 记录实时流的观看者，仅统计实时点播，回放与下载由各自的会话管理
+func (g *GB28181API) AddViewer(streamID, id string) int {
+	if _, ok := g.plays.Load(streamID); !ok {
+		return 0
+	}
+	return g.viewers.add(streamID, id)
+}
+
+// RemoveViewer 观看者断开，返回剩余数量
+func (g *GB28181API) RemoveViewer(streamID, id string) int {
+	return g.viewers.remove(streamID, id)
+}
+
+// Viewers 实时流当前的观看者数量
+func (g *GB28181API) Viewers(streamID string) int {
+	return g.viewers.count(streamID)
+}
@@ -0,0 +1,50 @@
+package gbs
+
+import (
+	"testing"
+
+	"github.com/ixugo/goddd/pkg/conc"
+)
+
+func TestViewerCounter(t *testing.T) {
+	v := newViewerCounter()
+	const stream = "gb_1"
+
+	if n := v.add(stream, "a"); n != 1 {
+		t.Fatalf("expect 1, got %d", n)
+	}
+	// 同一会话重复上报只计一次
+	v.add(stream, "a")
+	if n := v.add(stream, "b"); n != 2 {
+		t.Fatalf("expect 2, got %d", n)
+	}
+	if n := v.remove(stream, "a"); n != 1 {
+		t.Fatalf("expect 1, got %d", n)
+	}
+	// 未记录的会话断开不影响计数
+	if n := v.remove(stream, "c"); n != 1 {
+		t.Fatalf("expect 1, got %d", n)
+	}
+	v.clear(stream)
+	if n := v.count(stream); n != 0 {
+		t.Fatalf("expect 0, got %d", n)
+	}
+	if n := v.remove(stream, "b"); n != 0 {
+		t.Fatalf("expect 0, got %d", n)
+	}
+}
+
+func TestAddViewerLiveOnly(t *testing.T) {
+	g := GB28181API{plays: &conc.Map[string, *playTrace]{}, viewers: newViewerCounter()}
+	// 回放、下载流不计入
+	if n := g.AddViewer("gb_1", "a"); n != 0 {
+		t.Fatalf("expect 0, got %d", n)
+	}
+	g.plays.Store("gb_1", newPlayTrace(&PlayInput{}))
+	if n := g.AddViewer("gb_1", "a"); n != 1 {
+		t.Fatalf("expect 1, got %d", n)
+	}
+	if n := g.RemoveViewer("gb_1", "a"); n != 0 {
+		t.Fatalf("expect 0, got %d", n)
+	}
+}
@@ -0,0 +1,53 @@
+package gbs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ixugo/goddd/pkg/conc"
+)
+
+// ErrResponseTimeout 等待设备应答超时
+var ErrResponseTimeout = fmt.Errorf("wait device response timeout")
+
+// waiter 单包应答等待，请求方按 deviceID:SN 注册，应答处理时通知
+// 多包应答使用 sip.Collector
+type waiter[T any] struct {
+	m conc.Map[string, chan *T]
+}
+
+func waiterKey(deviceID string, sn int) string {
+	return fmt.Sprintf("%s:%d", deviceID, sn)
+}
+
+// register 须在发送请求前注册，避免应答先于注册到达
+// 返回的 chan 交给 wait，应答可能在 wait 之前已写入
+func (w *waiter[T]) register(key string) chan *T {
+	ch := make(chan *T, 1)
+	w.m.Store(key, ch)
+	return ch
+}
+
+func (w *waiter[T]) cancel(key string) {
+	w.m.Delete(key)
+}
+
+// notify 通知等待方，无人等待时返回 false
+func (w *waiter[T]) notify(key string, v *T) bool {
+	ch, ok := w.m.LoadAndDelete(key)
+	if !ok {
+		return false
+	}
+	ch <- v
+	return true
+}
+
+// wait 等待应答，超时后由调用方 cancel 注销
+func (w *waiter[T]) wait(ch chan *T, timeout time.Duration) (*T, error) {
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-time.After(timeout):
+		return nil, ErrResponseTimeout
+	}
+}
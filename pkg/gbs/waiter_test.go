@@ -0,0 +1,42 @@
+package gbs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaiterNotifyBeforeWait(t *testing.T) {
+	var w waiter[ControlResponse]
+	key := waiterKey("34020000001320000001", 1)
+	ch := w.register(key)
+	defer w.cancel(key)
+
+	// 应答先于 wait 到达
+	if !w.notify(key, &ControlResponse{Result: "OK"}) {
+		t.Fatal("expect notify ok")
+	}
+	resp, err := w.wait(ch, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result != "OK" {
+		t.Fatalf("expect OK, got %s", resp.Result)
+	}
+	// 已通知的 key 不再接收
+	if w.notify(key, &ControlResponse{}) {
+		t.Fatal("expect notify false after delivered")
+	}
+}
+
+func TestWaiterTimeout(t *testing.T) {
+	var w waiter[ControlResponse]
+	key := waiterKey("34020000001320000001", 2)
+	ch := w.register(key)
+	w.cancel(key)
+	if _, err := w.wait(ch, 10*time.Millisecond); err != ErrResponseTimeout {
+		t.Fatalf("expect ErrResponseTimeout, got %v", err)
+	}
+	if w.notify(key, &ControlResponse{}) {
+		t.Fatal("expect notify false after cancel")
+	}
+}
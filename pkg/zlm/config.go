@@ -192,6 +192,10 @@ func NewBool(b bool) *bool {
 	return &b
 }
 
+func NewInt(i int) *int {
+	return &i
+}
+
 // SetServerConfigRequest
 // https://github.com/zlmediakit/ZLMediaKit/wiki/MediaServer%E6%94%AF%E6%8C%81%E7%9A%84HTTP-HOOK-API
 type SetServerConfigRequest struct {
@@ -3,6 +3,10 @@ package zlm
 const (
 	openRtpServer  = `/index/api/openRtpServer`
 	closeRtpServer = `/index/api/closeRtpServer`
+	startSendRtp   = `/index/api/startSendRtp`
+	stopSendRtp    = `/index/api/stopSendRtp`
+
+	connectRtpServer = `/index/api/connectRtpServer`
 )
 
 type OpenRTPServerResponse struct {
@@ -11,9 +15,10 @@ type OpenRTPServerResponse struct {
 	Port int    `json:"port"` // 接收端口，方便获取随机端口号
 }
 type OpenRTPServerRequest struct {
-	Port     int    `json:"port"`      // 接收端口，0 则为随机端口
-	TCPMode  int8   `json:"tcp_mode"`  // 0 udp 模式，1 tcp 被动模式, 2 tcp 主动模式。 (兼容 enable_tcp 为 0/1)
-	StreamID string `json:"stream_id"` // 该端口绑定的流 ID，该端口只能创建这一个流(而不是根据 ssrc 创建多个)
+	Port     int    `json:"port"`           // 接收端口，0 则为随机端口
+	TCPMode  int8   `json:"tcp_mode"`       // 0 udp 模式，1 tcp 被动模式, 2 tcp 主动模式。 (兼容 enable_tcp 为 0/1)
+	StreamID string `json:"stream_id"`      // 该端口绑定的流 ID，该端口只能创建这一个流(而不是根据 ssrc 创建多个)
+	SSRC     string `json:"ssrc,omitempty"` // 指定 ssrc 收流，其它 ssrc 的 rtp 包将被丢弃，不传时不过滤
 }
 
 // OpenRTPServer 创建 GB28181 RTP 接收端口，如果该端口接收数据超时，则会自动被回收(不用调用 closeRtpServer 接口)
@@ -58,3 +63,80 @@ func (e *Engine) CloseRTPServer(in CloseRTPServerRequest) (*CloseRTPServerRespon
 	}
 	return &resp, nil
 }
+
+type ConnectRTPServerRequest struct {
+	DstURL   string `json:"dst_url"`   // 设备的 ip 或域名
+	DstPort  int    `json:"dst_port"`  // 设备的端口
+	StreamID string `json:"stream_id"` // 调用 openRtpServer 接口时提供的流 ID
+}
+
+// ConnectRTPServer tcp 主动模式下，连接设备并开始收流，需在 openRtpServer 且 tcp_mode 为 2 后调用
+// https://docs.zlmediakit.com/zh/guide/media_server/restful_api.html#_29%E3%80%81-index-api-connectrtpserver
+func (e *Engine) ConnectRTPServer(in ConnectRTPServerRequest) error {
+	body, err := struct2map(in)
+	if err != nil {
+		return err
+	}
+	var resp FixedHeader
+	if err := e.post(connectRtpServer, body, &resp); err != nil {
+		return err
+	}
+	return e.ErrHandle(resp.Code, resp.Msg)
+}
+
+type StartSendRTPRequest struct {
+	Vhost     string `json:"vhost"`                // 虚拟主机，例如__defaultVhost__
+	App       string `json:"app"`                  // 应用名，例如 live
+	Stream    string `json:"stream"`               // 流 ID，例如 test
+	SSRC      string `json:"ssrc"`                 // 推流的 rtp 的 ssrc
+	DstURL    string `json:"dst_url"`              // 目标 ip 或域名
+	DstPort   int    `json:"dst_port"`             // 目标端口
+	IsUDP     bool   `json:"is_udp"`               // 是否为 udp 模式，否则为 tcp 模式
+	SrcPort   int    `json:"src_port,omitempty"`   // 使用的本机端口，为 0 或不传时默认为随机端口
+	PT        int    `json:"pt,omitempty"`         // 发送时，rtp 的 pt（uint8_t），不传时默认为 96
+	UsePS     *int   `json:"use_ps,omitempty"`     // 发送时，rtp 的负载类型。为 1 时，负载为 ps；为 0 时，为 es；不传时默认为 1
+	OnlyAudio *bool  `json:"only_audio,omitempty"` // 当 use_ps 为 0 时，有效。为 1 时，发送音频；为 0 时，发送视频；不传时默认为 0
+}
+
+type StartSendRTPResponse struct {
+	FixedHeader
+	LocalPort int `json:"local_port"` // 使用的本地端口号
+}
+
+// StartSendRTP 作为 GB28181 客户端，启动 ps-rtp 推流，用于语音广播/对讲向设备推送音频
+// https://docs.zlmediakit.com/zh/guide/media_server/restful_api.html#_26%E3%80%81-index-api-startsendrtp
+func (e *Engine) StartSendRTP(in StartSendRTPRequest) (*StartSendRTPResponse, error) {
+	body, err := struct2map(in)
+	if err != nil {
+		return nil, err
+	}
+	var resp StartSendRTPResponse
+	if err := e.post(startSendRtp, body, &resp); err != nil {
+		return nil, err
+	}
+	if err := e.ErrHandle(resp.Code, resp.Msg); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+type StopSendRTPRequest struct {
+	Vhost  string `json:"vhost"`          // 虚拟主机，例如__defaultVhost__
+	App    string `json:"app"`            // 应用名，例如 live
+	Stream string `json:"stream"`         // 流 ID，例如 test
+	SSRC   string `json:"ssrc,omitempty"` // 根据 ssrc 关停某路 rtp 推流，不传时关闭所有推流
+}
+
+// StopSendRTP 停止 GB28181 ps-rtp 推流
+// https://docs.zlmediakit.com/zh/guide/media_server/restful_api.html#_27%E3%80%81-index-api-stopsendrtp
+func (e *Engine) StopSendRTP(in StopSendRTPRequest) error {
+	body, err := struct2map(in)
+	if err != nil {
+		return err
+	}
+	var resp FixedHeader
+	if err := e.post(stopSendRtp, body, &resp); err != nil {
+		return err
+	}
+	return e.ErrHandle(resp.Code, resp.Msg)
+}